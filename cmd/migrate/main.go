@@ -0,0 +1,146 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/Sayan80bayev/go-project/pkg/logging"
+	"userService/internal/bootstrap"
+	"userService/internal/config"
+	"userService/internal/migrations"
+)
+
+type options struct {
+	dryRun bool
+	down   int
+	status bool
+}
+
+func main() {
+	logger := logging.GetLogger()
+
+	var opts options
+	flag.BoolVar(&opts.dryRun, "dry-run", false, "print pending steps without applying them")
+	flag.IntVar(&opts.down, "down", 0, "roll back the given number of applied migrations")
+	flag.BoolVar(&opts.status, "status", false, "list migrations and their applied state")
+	flag.Parse()
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		logger.Fatalf("Couldn't load config: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Minute)
+	defer cancel()
+
+	if cfg.DBDriver == config.DriverPostgres {
+		runPostgres(ctx, cfg, opts)
+		return
+	}
+	runMongo(ctx, cfg, opts)
+}
+
+func runMongo(ctx context.Context, cfg *config.Config, opts options) {
+	logger := logging.GetLogger()
+
+	db, err := bootstrap.InitMongoDatabase(cfg)
+	if err != nil {
+		logger.Fatalf("Couldn't connect to MongoDB: %v", err)
+	}
+	defer func() {
+		if err := db.Client().Disconnect(context.Background()); err != nil {
+			logger.Warnf("Couldn't disconnect from MongoDB: %v", err)
+		}
+	}()
+
+	m := migrations.NewMigrator(db, migrations.All, opts.dryRun)
+
+	switch {
+	case opts.status:
+		statuses, err := m.Status(ctx)
+		if err != nil {
+			logger.Fatalf("Couldn't read migration status: %v", err)
+		}
+		for _, s := range statuses {
+			printStatus(s.Version, s.Description, s.Applied, s.AppliedAt)
+		}
+
+	case opts.down > 0:
+		reverted, err := m.Down(ctx, opts.down)
+		for _, mig := range reverted {
+			fmt.Printf("rolled back %d: %s\n", mig.Version, mig.Description)
+		}
+		if err != nil {
+			logger.Errorf("Rollback stopped: %v", err)
+			os.Exit(1)
+		}
+
+	default:
+		applied, err := m.Up(ctx)
+		for _, mig := range applied {
+			fmt.Printf("applied %d: %s\n", mig.Version, mig.Description)
+		}
+		if err != nil {
+			logger.Errorf("Migration stopped: %v", err)
+			os.Exit(1)
+		}
+	}
+}
+
+func runPostgres(ctx context.Context, cfg *config.Config, opts options) {
+	logger := logging.GetLogger()
+
+	pool, err := bootstrap.InitPostgresPool(cfg)
+	if err != nil {
+		logger.Fatalf("Couldn't connect to Postgres: %v", err)
+	}
+	defer pool.Close()
+
+	all, err := migrations.PostgresMigrations()
+	if err != nil {
+		logger.Fatalf("Couldn't load migrations: %v", err)
+	}
+	m := migrations.NewPostgresMigrator(pool, all, opts.dryRun)
+
+	switch {
+	case opts.status:
+		statuses, err := m.Status(ctx)
+		if err != nil {
+			logger.Fatalf("Couldn't read migration status: %v", err)
+		}
+		for _, s := range statuses {
+			printStatus(s.Version, s.Description, s.Applied, s.AppliedAt)
+		}
+
+	case opts.down > 0:
+		reverted, err := m.Down(ctx, opts.down)
+		for _, mig := range reverted {
+			fmt.Printf("rolled back %d: %s\n", mig.Version, mig.Description)
+		}
+		if err != nil {
+			logger.Errorf("Rollback stopped: %v", err)
+			os.Exit(1)
+		}
+
+	default:
+		applied, err := m.Up(ctx)
+		for _, mig := range applied {
+			fmt.Printf("applied %d: %s\n", mig.Version, mig.Description)
+		}
+		if err != nil {
+			logger.Errorf("Migration stopped: %v", err)
+			os.Exit(1)
+		}
+	}
+}
+
+func printStatus(version int, description string, applied bool, appliedAt time.Time) {
+	state := "pending"
+	if applied {
+		state = "applied " + appliedAt.Format(time.RFC3339)
+	}
+	fmt.Printf("%4d  %-28s  %s\n", version, state, description)
+}
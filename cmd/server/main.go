@@ -2,10 +2,12 @@ package main
 
 import (
 	"context"
+	"expvar"
 	"github.com/Sayan80bayev/go-project/pkg/logging"
 	"github.com/gin-gonic/gin"
 	swaggerFiles "github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
+	"userService/internal/auth"
 	"userService/internal/bootstrap"
 	"userService/internal/grpc"
 	"userService/internal/routes"
@@ -25,6 +27,8 @@ func main() {
 	r := gin.New()
 	r.Use(logging.Middleware)
 	r.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
+	// Metrics expose internal state, so only admins may read them
+	r.GET("/debug/vars", c.Verifier.Identify(), auth.Require(auth.HasRole(auth.RoleAdmin)), gin.WrapH(expvar.Handler()))
 	routes.SetupUserRoutes(r, c)
 
 	// Start gRPC server (in its own goroutine)
@@ -35,6 +39,9 @@ func main() {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 	go c.Consumer.Start(ctx)
+	go c.Lifecycle.RunPurgeJob(ctx, c.Config.UserPurgeInterval)
+	go c.Moderation.RunExpiryJob(ctx, c.Config.ModerationExpiryInterval)
+	go c.OutboxRelay.Run(ctx)
 
 	if err := r.Run(":" + c.Config.Port); err != nil {
 		logger.Errorf("Couldn't start Gin server: %v", err)
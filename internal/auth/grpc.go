@@ -0,0 +1,24 @@
+package auth
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// UnaryInterceptor is the gRPC counterpart of Identify: it reads the
+// "authorization" metadata and attaches the caller's Principal when valid.
+func (v *Verifier) UnaryInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if md, ok := metadata.FromIncomingContext(ctx); ok {
+			for _, header := range md.Get("authorization") {
+				if p := v.fromHeader(header); p != nil {
+					ctx = NewContext(ctx, p)
+					break
+				}
+			}
+		}
+		return handler(ctx, req)
+	}
+}
@@ -0,0 +1,51 @@
+package auth
+
+import (
+	"context"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+type principalKey struct{}
+
+// NewContext returns a copy of ctx carrying p.
+func NewContext(ctx context.Context, p *Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, p)
+}
+
+// FromContext returns the Principal attached by Identify or UnaryInterceptor,
+// or nil for anonymous callers. A *gin.Context is accepted as well.
+func FromContext(ctx context.Context) *Principal {
+	if gc, ok := ctx.(*gin.Context); ok {
+		ctx = gc.Request.Context()
+	}
+	p, _ := ctx.Value(principalKey{}).(*Principal)
+	return p
+}
+
+// Identify attaches the caller's Principal to the request when it carries a
+// valid bearer token. Requests without one pass through anonymously; routes
+// that need a caller reject them with Require(Authenticated()).
+func (v *Verifier) Identify() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		if p := v.fromHeader(ctx.GetHeader("Authorization")); p != nil {
+			ctx.Request = ctx.Request.WithContext(NewContext(ctx.Request.Context(), p))
+		}
+		ctx.Next()
+	}
+}
+
+// fromHeader parses an Authorization header value, returning nil unless it
+// holds a valid bearer token.
+func (v *Verifier) fromHeader(header string) *Principal {
+	raw, ok := strings.CutPrefix(header, "Bearer ")
+	if !ok || raw == "" {
+		return nil
+	}
+	p, err := v.Parse(raw)
+	if err != nil {
+		return nil
+	}
+	return p
+}
@@ -0,0 +1,93 @@
+package auth
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// Rule decides whether the caller may proceed with a request. p is nil for
+// anonymous callers.
+type Rule func(ctx *gin.Context, p *Principal) bool
+
+// Authenticated lets through any caller with a valid token.
+func Authenticated() Rule {
+	return func(_ *gin.Context, p *Principal) bool {
+		return p != nil
+	}
+}
+
+// HasRole lets through callers holding role as a realm or client role.
+func HasRole(role string) Rule {
+	return func(_ *gin.Context, p *Principal) bool {
+		return p.HasRole(role)
+	}
+}
+
+// IsOwner lets through callers whose subject is the profile id in path parameter param.
+func IsOwner(param string) Rule {
+	return func(ctx *gin.Context, p *Principal) bool {
+		id, err := uuid.Parse(ctx.Param(param))
+		return err == nil && p.Is(id)
+	}
+}
+
+// AnyOf lets through callers satisfying at least one of rules.
+func AnyOf(rules ...Rule) Rule {
+	return func(ctx *gin.Context, p *Principal) bool {
+		for _, rule := range rules {
+			if rule(ctx, p) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// WhenQuery applies rule only to requests whose query parameter key is true,
+// such as opt-in privileged views of a public route.
+func WhenQuery(key string, rule Rule) Rule {
+	return func(ctx *gin.Context, p *Principal) bool {
+		if on, _ := strconv.ParseBool(ctx.Query(key)); !on {
+			return true
+		}
+		return rule(ctx, p)
+	}
+}
+
+// OwnerOrAdmin is the rule of writes to a profile addressed by path parameter
+// param.
+func OwnerOrAdmin(param string) Rule {
+	return func(ctx *gin.Context, p *Principal) bool {
+		id, err := uuid.Parse(ctx.Param(param))
+		return err == nil && p.CanManage(id)
+	}
+}
+
+// Require enforces rule on a route. It must run after Identify. Rejected
+// anonymous callers get 401, rejected authenticated ones 403.
+func Require(rule Rule) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		p := FromContext(ctx)
+		if rule(ctx, p) {
+			ctx.Next()
+			return
+		}
+
+		if p == nil {
+			ctx.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
+				"status":  "error",
+				"code":    "UNAUTHORIZED",
+				"message": "You're unauthorized",
+			})
+			return
+		}
+		ctx.AbortWithStatusJSON(http.StatusForbidden, gin.H{
+			"status":  "error",
+			"code":    "FORBIDDEN",
+			"message": "You are not allowed to perform this action",
+		})
+	}
+}
@@ -0,0 +1,43 @@
+package auth
+
+import (
+	"slices"
+
+	"github.com/google/uuid"
+)
+
+// RoleAdmin is the Keycloak role that grants administrative access to user profiles.
+const RoleAdmin = "user-admin"
+
+// Principal is the authenticated caller as described by its access token.
+type Principal struct {
+	Subject uuid.UUID
+	// RealmRoles come from realm_access.roles
+	RealmRoles []string
+	// ClientRoles come from resource_access.<client>.roles of the configured client
+	ClientRoles []string
+}
+
+// HasRole reports whether the caller holds role as a realm or client role.
+func (p *Principal) HasRole(role string) bool {
+	if p == nil {
+		return false
+	}
+	return slices.Contains(p.RealmRoles, role) || slices.Contains(p.ClientRoles, role)
+}
+
+// IsAdmin reports whether the caller holds RoleAdmin.
+func (p *Principal) IsAdmin() bool {
+	return p.HasRole(RoleAdmin)
+}
+
+// Is reports whether the caller is the owner of the profile id.
+func (p *Principal) Is(id uuid.UUID) bool {
+	return p != nil && p.Subject == id
+}
+
+// CanManage reports whether the caller may change or delete the profile id:
+// its owner and admins may.
+func (p *Principal) CanManage(id uuid.UUID) bool {
+	return p.Is(id) || p.IsAdmin()
+}
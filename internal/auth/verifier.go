@@ -0,0 +1,71 @@
+package auth
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/MicahParks/keyfunc/v2"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+)
+
+var ErrInvalidToken = errors.New("invalid access token")
+
+// Verifier validates Keycloak access tokens against the realm JWKS and extracts the caller.
+type Verifier struct {
+	jwks     *keyfunc.JWKS
+	issuer   string
+	clientID string
+}
+
+// keycloakClaims is the subset of a Keycloak access token the service reads.
+type keycloakClaims struct {
+	jwt.RegisteredClaims
+	RealmAccess struct {
+		Roles []string `json:"roles"`
+	} `json:"realm_access"`
+	ResourceAccess map[string]struct {
+		Roles []string `json:"roles"`
+	} `json:"resource_access"`
+}
+
+// NewVerifier fetches the JWKS and keeps it refreshed in the background.
+// Tokens must be issued by issuer for the audience clientID, which also
+// selects the resource_access entry that supplies client roles.
+func NewVerifier(jwksURL, issuer, clientID string) (*Verifier, error) {
+	if issuer == "" || clientID == "" {
+		return nil, errors.New("token issuer and client id are required")
+	}
+	jwks, err := keyfunc.Get(jwksURL, keyfunc.Options{
+		RefreshInterval:   time.Hour,
+		RefreshRateLimit:  5 * time.Minute,
+		RefreshTimeout:    10 * time.Second,
+		RefreshUnknownKID: true,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to load JWKS: %w", err)
+	}
+	return &Verifier{jwks: jwks, issuer: issuer, clientID: clientID}, nil
+}
+
+// Parse verifies a raw bearer token and returns its principal.
+func (v *Verifier) Parse(raw string) (*Principal, error) {
+	var claims keycloakClaims
+	token, err := jwt.ParseWithClaims(raw, &claims, v.jwks.Keyfunc,
+		jwt.WithExpirationRequired(), jwt.WithIssuer(v.issuer), jwt.WithAudience(v.clientID))
+	if err != nil || !token.Valid {
+		return nil, ErrInvalidToken
+	}
+
+	sub, err := uuid.Parse(claims.Subject)
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+
+	return &Principal{
+		Subject:     sub,
+		RealmRoles:  claims.RealmAccess.Roles,
+		ClientRoles: claims.ResourceAccess[v.clientID].Roles,
+	}, nil
+}
@@ -7,23 +7,36 @@ import (
 	"github.com/Sayan80bayev/go-project/pkg/logging"
 	"github.com/Sayan80bayev/go-project/pkg/messaging"
 	storage "github.com/Sayan80bayev/go-project/pkg/objectStorage"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/redis/go-redis/v9"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 	"time"
+	"userService/internal/auth"
+	"userService/internal/cache"
 	"userService/internal/config"
 	"userService/internal/events"
+	"userService/internal/keycloak"
+	"userService/internal/migrations"
 	"userService/internal/repository"
 	"userService/internal/service"
 )
 
 // Container holds all dependencies
 type Container struct {
+	// DB is set when DB_DRIVER is mongo, Postgres when it is postgres
 	DB          *mongo.Database
+	Postgres    *pgxpool.Pool
 	Redis       caching.CacheService
 	FileStorage storage.FileStorage
 	Producer    messaging.Producer
 	Consumer    messaging.Consumer
 	UserService *service.UserService
+	Lifecycle   *service.LifecycleService
+	Moderation  *service.ModerationService
+	Roles       *service.RoleService
+	OutboxRelay *service.OutboxRelay
+	Verifier    *auth.Verifier
 	Config      *config.Config
 	JWKSUrl     string
 }
@@ -37,7 +50,7 @@ func Init() (*Container, error) {
 		return nil, fmt.Errorf("failed to load config: %w", err)
 	}
 
-	db, err := initMongoDatabase(cfg)
+	store, err := initUserStore(cfg)
 	if err != nil {
 		return nil, err
 	}
@@ -57,21 +70,54 @@ func Init() (*Container, error) {
 		return nil, fmt.Errorf("failed to create Kafka producer: %w", err)
 	}
 
-	userRepository := repository.NewUserRepository(db)
-	userService := service.NewUserService(userRepository, fileStorage, producer, cacheService)
+	completion, err := completionPolicy(cfg)
+	if err != nil {
+		return nil, err
+	}
 
-	consumer, err := initKafkaConsumer(cfg, fileStorage, userRepository)
+	userRepository := store.repo
+	redisClient := initRedisClient(cfg)
+	blockSet := cache.NewRedisBlockSet(redisClient)
+	userService := service.NewUserService(userRepository, fileStorage, producer, cacheService).
+		WithMultiGetter(cache.NewRedisMultiGetter(redisClient)).
+		WithRevisions(store.revisions).
+		WithOutbox(store.tx, store.outbox).
+		WithCompletionPolicy(completion).
+		WithHandlePolicy(handlePolicy(cfg)).
+		WithHandleReservations(store.handles).
+		WithFollows(store.follows).
+		WithBlocks(store.blocks, blockSet)
+	lifecycle := service.NewLifecycleService(userRepository, fileStorage, producer, cacheService, retentionPolicy(cfg)).
+		WithRevisions(store.revisions).
+		WithOutbox(store.tx, store.outbox).
+		WithFollows(store.follows).
+		WithBlocks(store.blocks, blockSet).
+		WithHandleReservations(store.handles)
+	moderation := service.NewModerationService(userRepository, producer, cacheService).
+		WithRevisions(store.revisions).
+		WithOutbox(store.tx, store.outbox)
+	roles := service.NewRoleService(userRepository, producer, cacheService, roleDirectory(cfg), cfg.AssignableRoles).
+		WithRevisions(store.revisions).
+		WithOutbox(store.tx, store.outbox)
+	relay := service.NewOutboxRelay(store.outbox, producer, relayPolicy(cfg))
+
+	consumer, err := initKafkaConsumer(cfg, fileStorage, userService)
 	if err != nil {
 		return nil, err
 	}
 
 	jwksURL := buildJWKSURL(cfg)
+	verifier, err := auth.NewVerifier(jwksURL, tokenIssuer(cfg), cfg.KeycloakClientID)
+	if err != nil {
+		return nil, err
+	}
 
 	logger.Info("✅ Dependencies initialized successfully")
 	// Wait for shutdown signal
 
 	return &Container{
-		DB:          db,
+		DB:          store.mongo,
+		Postgres:    store.postgres,
 		Redis:       cacheService,
 		FileStorage: fileStorage,
 		Producer:    producer,
@@ -79,12 +125,18 @@ func Init() (*Container, error) {
 		Config:      cfg,
 		JWKSUrl:     jwksURL,
 		UserService: userService,
+		Lifecycle:   lifecycle,
+		Moderation:  moderation,
+		Roles:       roles,
+		OutboxRelay: relay,
+		Verifier:    verifier,
 	}, nil
 }
 
 // --- Helpers ---
 
-func initMongoDatabase(cfg *config.Config) (*mongo.Database, error) {
+// InitMongoDatabase connects to MongoDB and returns the configured database
+func InitMongoDatabase(cfg *config.Config) (*mongo.Database, error) {
 	logger := logging.GetLogger()
 
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
@@ -123,6 +175,16 @@ func initRedis(cfg *config.Config) (*caching.RedisService, error) {
 	return redisCache, nil
 }
 
+// initRedisClient opens a raw client for batch reads and block sets, which
+// caching.CacheService does not offer.
+func initRedisClient(cfg *config.Config) *redis.Client {
+	return redis.NewClient(&redis.Options{
+		Addr:     cfg.RedisAddr,
+		Password: cfg.RedisPass,
+		DB:       0,
+	})
+}
+
 func initMinio(cfg *config.Config) (storage.FileStorage, error) {
 	logger := logging.GetLogger()
 
@@ -143,7 +205,11 @@ func initMinio(cfg *config.Config) (storage.FileStorage, error) {
 	return fs, nil
 }
 
-func initKafkaConsumer(cfg *config.Config, fileStorage storage.FileStorage, repo service.UserRepository) (messaging.Consumer, error) {
+func initKafkaConsumer(
+	cfg *config.Config,
+	fileStorage storage.FileStorage,
+	userService *service.UserService,
+) (messaging.Consumer, error) {
 	consumer, err := messaging.NewKafkaConsumer(messaging.ConsumerConfig{
 		BootstrapServers: cfg.KafkaBrokers[0],
 		GroupID:          cfg.KafkaConsumerGroup,
@@ -154,7 +220,7 @@ func initKafkaConsumer(cfg *config.Config, fileStorage storage.FileStorage, repo
 	}
 
 	// Use typed event constants
-	consumer.RegisterHandler(events.UserCreated, service.CreateUserHandler(repo))
+	consumer.RegisterHandler(events.UserCreated, userService.CreateUserHandler())
 	consumer.RegisterHandler(events.UserUpdated, service.UserUpdatedHandler(fileStorage))
 	consumer.RegisterHandler(events.UserDeleted, service.UserDeletedHandler(fileStorage))
 
@@ -162,6 +228,183 @@ func initKafkaConsumer(cfg *config.Config, fileStorage storage.FileStorage, repo
 	return consumer, nil
 }
 
+// userStore is the backend selected by DB_DRIVER; only one connection is set.
+type userStore struct {
+	repo      service.UserRepository
+	revisions service.RevisionRepository
+	follows   service.FollowRepository
+	blocks    service.BlockRepository
+	handles   service.HandleRepository
+	tx        service.Transactor
+	outbox    service.OutboxRepository
+	mongo     *mongo.Database
+	postgres  *pgxpool.Pool
+}
+
+func initUserStore(cfg *config.Config) (*userStore, error) {
+	switch cfg.DBDriver {
+	case config.DriverMongo, "":
+		db, err := InitMongoDatabase(cfg)
+		if err != nil {
+			return nil, err
+		}
+		if cfg.MigrateOnStartup {
+			if err := runMigrations(db); err != nil {
+				return nil, err
+			}
+		}
+		tx, err := mongoTransactor(db.Client())
+		if err != nil {
+			return nil, err
+		}
+		return &userStore{
+			repo:      repository.NewUserRepository(db),
+			revisions: repository.NewRevisionRepository(db),
+			follows:   repository.NewFollowRepository(db),
+			blocks:    repository.NewBlockRepository(db),
+			handles:   repository.NewHandleRepository(db),
+			tx:        tx,
+			outbox:    repository.NewOutboxRepository(db),
+			mongo:     db,
+		}, nil
+
+	case config.DriverPostgres:
+		pool, err := InitPostgresPool(cfg)
+		if err != nil {
+			return nil, err
+		}
+		if cfg.MigrateOnStartup {
+			if err := runPostgresMigrations(pool); err != nil {
+				return nil, err
+			}
+		}
+		return &userStore{
+			repo:      repository.NewPostgresUserRepository(pool),
+			revisions: repository.NewPostgresRevisionRepository(pool),
+			follows:   repository.NewPostgresFollowRepository(pool),
+			blocks:    repository.NewPostgresBlockRepository(pool),
+			handles:   repository.NewPostgresHandleRepository(pool),
+			tx:        repository.NewPostgresTransactor(pool),
+			outbox:    repository.NewPostgresOutboxRepository(pool),
+			postgres:  pool,
+		}, nil
+	}
+	return nil, fmt.Errorf("unsupported DB_DRIVER %q", cfg.DBDriver)
+}
+
+// mongoTransactor returns a transactor when MongoDB supports transactions.
+// A standalone server gets none: events still go through the outbox, but are
+// enqueued after their write instead of with it.
+func mongoTransactor(client *mongo.Client) (service.Transactor, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	ok, err := repository.MongoSupportsTransactions(ctx, client)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check MongoDB transaction support: %w", err)
+	}
+	if !ok {
+		logging.GetLogger().Warn("MongoDB is not a replica set; outbox events are not written atomically with profile changes")
+		return nil, nil
+	}
+	return repository.NewMongoTransactor(client), nil
+}
+
+// InitPostgresPool connects to Postgres using POSTGRES_DSN.
+func InitPostgresPool(cfg *config.Config) (*pgxpool.Pool, error) {
+	logger := logging.GetLogger()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	pool, err := pgxpool.New(ctx, cfg.PostgresDSN)
+	if err != nil {
+		return nil, fmt.Errorf("postgres init failed: %w", err)
+	}
+	if err := pool.Ping(ctx); err != nil {
+		pool.Close()
+		return nil, fmt.Errorf("postgres connection failed: %w", err)
+	}
+
+	logger.Info("Connected to Postgres")
+	return pool, nil
+}
+
+func runPostgresMigrations(pool *pgxpool.Pool) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+
+	all, err := migrations.PostgresMigrations()
+	if err != nil {
+		return err
+	}
+	applied, err := migrations.NewPostgresMigrator(pool, all, false).Up(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to run migrations: %w", err)
+	}
+	logging.GetLogger().Infof("Migrations applied: %d", len(applied))
+	return nil
+}
+
+func runMigrations(db *mongo.Database) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+
+	applied, err := migrations.NewMigrator(db, migrations.All, false).Up(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to run migrations: %w", err)
+	}
+	logging.GetLogger().Infof("Migrations applied: %d", len(applied))
+	return nil
+}
+
+func retentionPolicy(cfg *config.Config) service.RetentionPolicy {
+	return service.RetentionPolicy{
+		RestoreGracePeriod: cfg.UserRestoreGracePeriod,
+		RetentionPeriod:    cfg.UserRetentionPeriod,
+	}
+}
+
+func relayPolicy(cfg *config.Config) service.RelayPolicy {
+	return service.RelayPolicy{
+		Interval:    cfg.OutboxRelayInterval,
+		MaxAttempts: cfg.OutboxMaxAttempts,
+	}
+}
+
+func handlePolicy(cfg *config.Config) service.HandlePolicy {
+	return service.HandlePolicy{
+		Reserved:       cfg.ReservedHandles,
+		RenameInterval: cfg.HandleRenameInterval,
+		RedirectPeriod: cfg.HandleRedirectPeriod,
+	}
+}
+
+func completionPolicy(cfg *config.Config) (service.CompletionPolicy, error) {
+	policy, err := service.NewCompletionPolicy(cfg.CompletionRequiredFields, cfg.CompletionRecommendedFields)
+	if err != nil {
+		return service.CompletionPolicy{}, fmt.Errorf("invalid completion policy: %w", err)
+	}
+	return policy, nil
+}
+
+// roleDirectory mirrors granted roles to Keycloak when admin credentials are configured.
+func roleDirectory(cfg *config.Config) service.RoleDirectory {
+	if cfg.KeycloakAdminClientSecret == "" {
+		logging.GetLogger().Warn("KEYCLOAK_ADMIN_CLIENT_SECRET is not set, roles will not be synced to Keycloak")
+		return nil
+	}
+	return keycloak.NewAdminClient(cfg.KeycloakURL, cfg.KeycloakRealm, cfg.KeycloakAdminClientID, cfg.KeycloakAdminClientSecret)
+}
+
 func buildJWKSURL(cfg *config.Config) string {
 	return fmt.Sprintf("%s/realms/%s/protocol/openid-connect/certs", cfg.KeycloakURL, cfg.KeycloakRealm)
 }
+
+// tokenIssuer is KEYCLOAK_ISSUER, or the realm URL when it is not set.
+func tokenIssuer(cfg *config.Config) string {
+	if cfg.KeycloakIssuer != "" {
+		return cfg.KeycloakIssuer
+	}
+	return fmt.Sprintf("%s/realms/%s", cfg.KeycloakURL, cfg.KeycloakRealm)
+}
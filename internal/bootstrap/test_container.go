@@ -3,12 +3,15 @@ package bootstrap
 import (
 	"fmt"
 	"github.com/Sayan80bayev/go-project/pkg/messaging"
+	"time"
+	"userService/internal/auth"
+	"userService/internal/cache"
 	"userService/internal/config"
 	"userService/internal/repository"
 	"userService/internal/service"
 )
 
-func NewTestContainer(mongoURI, kafkaAddr, minioHost, minioPort, redisAddr, jwksURL string) *Container {
+func NewTestContainer(mongoURI, kafkaAddr, minioHost, minioPort, redisAddr, jwksURL, keycloakURL string) *Container {
 	cfg := &config.Config{
 		GrpcPort:            "50052",
 		MongoURI:            mongoURI,
@@ -24,13 +27,38 @@ func NewTestContainer(mongoURI, kafkaAddr, minioHost, minioPort, redisAddr, jwks
 		KafkaProducerTopic:  "user-events",
 		KafkaConsumerGroup:  "user-service-test",
 		KafkaConsumerTopics: []string{"user-events"},
+		MigrateOnStartup:    true,
+
+		KeycloakURL:               keycloakURL,
+		KeycloakRealm:             "go-project",
+		KeycloakClientID:          "user-service",
+		KeycloakIssuer:            "http://go-project-keycloak:8080/realms/go-project",
+		KeycloakAdminClientID:     "user-service",
+		KeycloakAdminClientSecret: "test-secret",
+		AssignableRoles:           []string{auth.RoleAdmin, "moderator"},
+
+		UserRestoreGracePeriod: 24 * time.Hour,
+		UserRetentionPeriod:    48 * time.Hour,
+		UserPurgeInterval:      time.Minute,
+
+		ModerationExpiryInterval: time.Minute,
+
+		ReservedHandles:      []string{"admin", "support"},
+		HandleRenameInterval: time.Hour,
+		HandleRedirectPeriod: 24 * time.Hour,
+
+		OutboxRelayInterval: 100 * time.Millisecond,
+		OutboxMaxAttempts:   5,
 	}
 
 	// Mongo
-	db, err := initMongoDatabase(cfg)
+	db, err := InitMongoDatabase(cfg)
 	if err != nil {
 		panic(err)
 	}
+	if err := runMigrations(db); err != nil {
+		panic(err)
+	}
 
 	// Redis
 	cacheService, err := initRedis(cfg)
@@ -50,15 +78,54 @@ func NewTestContainer(mongoURI, kafkaAddr, minioHost, minioPort, redisAddr, jwks
 		panic(fmt.Errorf("failed to create Kafka producer: %w", err))
 	}
 
+	completion, err := completionPolicy(cfg)
+	if err != nil {
+		panic(err)
+	}
+
 	userRepository := repository.NewUserRepository(db)
-	userService := service.NewUserService(userRepository, fs, producer, cacheService)
+	revisions := repository.NewRevisionRepository(db)
+	tx := repository.NewMongoTransactor(db.Client())
+	outbox := repository.NewOutboxRepository(db)
+	redisClient := initRedisClient(cfg)
+	follows := repository.NewFollowRepository(db)
+	blocks := repository.NewBlockRepository(db)
+	blockSet := cache.NewRedisBlockSet(redisClient)
+	handles := repository.NewHandleRepository(db)
+	userService := service.NewUserService(userRepository, fs, producer, cacheService).
+		WithMultiGetter(cache.NewRedisMultiGetter(redisClient)).
+		WithRevisions(revisions).
+		WithOutbox(tx, outbox).
+		WithCompletionPolicy(completion).
+		WithHandlePolicy(handlePolicy(cfg)).
+		WithHandleReservations(handles).
+		WithFollows(follows).
+		WithBlocks(blocks, blockSet)
+	lifecycle := service.NewLifecycleService(userRepository, fs, producer, cacheService, retentionPolicy(cfg)).
+		WithRevisions(revisions).
+		WithOutbox(tx, outbox).
+		WithFollows(follows).
+		WithBlocks(blocks, blockSet).
+		WithHandleReservations(handles)
+	moderation := service.NewModerationService(userRepository, producer, cacheService).
+		WithRevisions(revisions).
+		WithOutbox(tx, outbox)
+	roles := service.NewRoleService(userRepository, producer, cacheService, roleDirectory(cfg), cfg.AssignableRoles).
+		WithRevisions(revisions).
+		WithOutbox(tx, outbox)
+	relay := service.NewOutboxRelay(outbox, producer, relayPolicy(cfg))
 	// Kafka Consumer
-	consumer, err := initKafkaConsumer(cfg, fs, userRepository)
+	consumer, err := initKafkaConsumer(cfg, fs, userService)
 	if err != nil {
 		panic(err)
 	}
 	// Use typed event constants
 
+	verifier, err := auth.NewVerifier(jwksURL, tokenIssuer(cfg), cfg.KeycloakClientID)
+	if err != nil {
+		panic(err)
+	}
+
 	return &Container{
 		DB:          db,
 		Redis:       cacheService,
@@ -66,6 +133,11 @@ func NewTestContainer(mongoURI, kafkaAddr, minioHost, minioPort, redisAddr, jwks
 		Producer:    producer,
 		Consumer:    consumer,
 		UserService: userService,
+		Lifecycle:   lifecycle,
+		Moderation:  moderation,
+		Roles:       roles,
+		OutboxRelay: relay,
+		Verifier:    verifier,
 		Config:      cfg,
 		JWKSUrl:     jwksURL,
 	}
@@ -0,0 +1,147 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+)
+
+// loadedMarker is always a member of a cached set, so that a user nobody
+// blocked is told apart from one whose set is not cached.
+const loadedMarker = "*"
+
+// generationTTL keeps a generation well past any load that read it.
+const generationTTL = 24 * time.Hour
+
+// storeIfCurrent replaces the set in KEYS[1] with ARGV[3:] for ARGV[2]
+// milliseconds, unless the generation in KEYS[2] moved on from ARGV[1].
+var storeIfCurrent = redis.NewScript(`
+if (redis.call('GET', KEYS[2]) or '0') ~= ARGV[1] then
+	return 0
+end
+redis.call('DEL', KEYS[1])
+redis.call('SADD', KEYS[1], unpack(ARGV, 3))
+redis.call('PEXPIRE', KEYS[1], ARGV[2])
+return 1
+`)
+
+// RedisBlockSet implements service.BlockSet with one Redis set per blocked
+// user holding the IDs of everyone that blocked them.
+type RedisBlockSet struct {
+	client *redis.Client
+}
+
+func NewRedisBlockSet(client *redis.Client) *RedisBlockSet {
+	return &RedisBlockSet{client: client}
+}
+
+func blockersKey(blockedID uuid.UUID) string {
+	return "blocked_by:" + blockedID.String()
+}
+
+func generationKey(blockedID uuid.UUID) string {
+	return "blocked_by_gen:" + blockedID.String()
+}
+
+// Blockers returns the cached blockers of blockedID; ok is false when the set
+// is not cached.
+func (s *RedisBlockSet) Blockers(ctx context.Context, blockedID uuid.UUID) (blockers []uuid.UUID, ok bool, err error) {
+	members, err := s.client.SMembers(ctx, blockersKey(blockedID)).Result()
+	if err != nil {
+		return nil, false, err
+	}
+
+	blockers = make([]uuid.UUID, 0, len(members))
+	for _, m := range members {
+		if m == loadedMarker {
+			ok = true
+			continue
+		}
+		id, err := uuid.Parse(m)
+		if err != nil {
+			return nil, false, fmt.Errorf("malformed blocker %q in %s: %w", m, blockersKey(blockedID), err)
+		}
+		blockers = append(blockers, id)
+	}
+	if !ok {
+		return nil, false, nil
+	}
+	return blockers, true, nil
+}
+
+// IsBlockedBy reports whether blockerID is among the cached blockers of
+// blockedID; ok is false when the set is not cached.
+func (s *RedisBlockSet) IsBlockedBy(ctx context.Context, blockedID, blockerID uuid.UUID) (blocked, ok bool, err error) {
+	key := blockersKey(blockedID)
+	var loaded, member *redis.BoolCmd
+	_, err = s.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		loaded = pipe.SIsMember(ctx, key, loadedMarker)
+		member = pipe.SIsMember(ctx, key, blockerID.String())
+		return nil
+	})
+	if err != nil {
+		return false, false, err
+	}
+	if !loaded.Val() {
+		return false, false, nil
+	}
+	return member.Val(), true, nil
+}
+
+// BlockedByAny reports, for each of blockerIDs, whether it is among the
+// cached blockers of blockedID; ok is false when the set is not cached.
+func (s *RedisBlockSet) BlockedByAny(ctx context.Context, blockedID uuid.UUID, blockerIDs []uuid.UUID) (blocked []bool, ok bool, err error) {
+	members := make([]any, 0, len(blockerIDs)+1)
+	members = append(members, loadedMarker)
+	for _, id := range blockerIDs {
+		members = append(members, id.String())
+	}
+
+	found, err := s.client.SMIsMember(ctx, blockersKey(blockedID), members...).Result()
+	if err != nil {
+		return nil, false, err
+	}
+	if !found[0] {
+		return nil, false, nil
+	}
+	return found[1:], true, nil
+}
+
+// Generation returns how often the blockers of blockedID were invalidated.
+func (s *RedisBlockSet) Generation(ctx context.Context, blockedID uuid.UUID) (int64, error) {
+	gen, err := s.client.Get(ctx, generationKey(blockedID)).Int64()
+	if errors.Is(err, redis.Nil) {
+		return 0, nil
+	}
+	return gen, err
+}
+
+// StoreBlockers replaces the cached blockers of blockedID for ttl, unless
+// they were invalidated after gen was read: the blockers were then loaded
+// before a change and would outlive it.
+func (s *RedisBlockSet) StoreBlockers(ctx context.Context, blockedID uuid.UUID, blockers []uuid.UUID, gen int64, ttl time.Duration) error {
+	args := make([]any, 0, len(blockers)+3)
+	args = append(args, strconv.FormatInt(gen, 10), ttl.Milliseconds(), loadedMarker)
+	for _, id := range blockers {
+		args = append(args, id.String())
+	}
+	keys := []string{blockersKey(blockedID), generationKey(blockedID)}
+	return storeIfCurrent.Run(ctx, s.client, keys, args...).Err()
+}
+
+// InvalidateBlockers drops the cached blockers of blockedID and moves its
+// generation on, so that loads already under way are not stored.
+func (s *RedisBlockSet) InvalidateBlockers(ctx context.Context, blockedID uuid.UUID) error {
+	_, err := s.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Incr(ctx, generationKey(blockedID))
+		pipe.Expire(ctx, generationKey(blockedID), generationTTL)
+		pipe.Del(ctx, blockersKey(blockedID))
+		return nil
+	})
+	return err
+}
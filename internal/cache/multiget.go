@@ -0,0 +1,32 @@
+package cache
+
+import (
+	"context"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// RedisMultiGetter implements service.MultiGetter with a single MGET.
+type RedisMultiGetter struct {
+	client *redis.Client
+}
+
+func NewRedisMultiGetter(client *redis.Client) *RedisMultiGetter {
+	return &RedisMultiGetter{client: client}
+}
+
+// MGet returns one value per key; keys that are missing or hold a non-string yield "".
+func (g *RedisMultiGetter) MGet(ctx context.Context, keys ...string) ([]string, error) {
+	vals, err := g.client.MGet(ctx, keys...).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]string, len(vals))
+	for i, v := range vals {
+		if s, ok := v.(string); ok {
+			out[i] = s
+		}
+	}
+	return out, nil
+}
@@ -3,9 +3,18 @@ package config
 import (
 	"github.com/Sayan80bayev/go-project/pkg/logging"
 	"github.com/spf13/viper"
+	"time"
+)
+
+// Supported values of DB_DRIVER.
+const (
+	DriverMongo    = "mongo"
+	DriverPostgres = "postgres"
 )
 
 type Config struct {
+	// MongoURI should point at a replica set: the outbox commits events with
+	// their writes only where MongoDB supports transactions
 	MongoURI    string `mapstructure:"MONGO_URI"`
 	MongoDBName string `mapstructure:"MONGO_DB_NAME"`
 	Port        string `mapstructure:"PORT"`
@@ -27,11 +36,76 @@ type Config struct {
 	KafkaConsumerTopics []string `mapstructure:"KAFKA_CONSUMER_TOPICS"`
 	KeycloakURL         string   `mapstructure:"KEYCLOAK_URL"`
 	KeycloakRealm       string   `mapstructure:"KEYCLOAK_REALM"`
+
+	// KeycloakClientID is the client of this service: access tokens must name
+	// it in aud, and its client roles count like realm roles
+	KeycloakClientID string `mapstructure:"KEYCLOAK_CLIENT_ID"`
+	// KeycloakIssuer is the iss of access tokens, the public realm URL.
+	// Defaults to KEYCLOAK_URL/realms/KEYCLOAK_REALM
+	KeycloakIssuer string `mapstructure:"KEYCLOAK_ISSUER"`
+
+	// Confidential client whose service account manages realm role mappings;
+	// roles are not mirrored to Keycloak without a secret
+	KeycloakAdminClientID     string `mapstructure:"KEYCLOAK_ADMIN_CLIENT_ID"`
+	KeycloakAdminClientSecret string `mapstructure:"KEYCLOAK_ADMIN_CLIENT_SECRET"`
+	// AssignableRoles are the realm roles the role API may grant and revoke
+	AssignableRoles []string `mapstructure:"ASSIGNABLE_ROLES"`
+
+	// DBDriver selects the user store: "mongo" (default) or "postgres"
+	DBDriver    string `mapstructure:"DB_DRIVER"`
+	PostgresDSN string `mapstructure:"POSTGRES_DSN"`
+
+	MigrateOnStartup bool `mapstructure:"MIGRATE_ON_STARTUP"`
+
+	UserRestoreGracePeriod time.Duration `mapstructure:"USER_RESTORE_GRACE_PERIOD"`
+	UserRetentionPeriod    time.Duration `mapstructure:"USER_RETENTION_PERIOD"`
+	UserPurgeInterval      time.Duration `mapstructure:"USER_PURGE_INTERVAL"`
+
+	// ModerationExpiryInterval is how often expired bans and suspensions are lifted
+	ModerationExpiryInterval time.Duration `mapstructure:"MODERATION_EXPIRY_INTERVAL"`
+
+	OutboxRelayInterval time.Duration `mapstructure:"OUTBOX_RELAY_INTERVAL"`
+	OutboxMaxAttempts   int           `mapstructure:"OUTBOX_MAX_ATTEMPTS"`
+
+	// Profile fields counted by the completion policy; names are always required
+	CompletionRequiredFields    []string `mapstructure:"COMPLETION_REQUIRED_FIELDS"`
+	CompletionRecommendedFields []string `mapstructure:"COMPLETION_RECOMMENDED_FIELDS"`
+
+	// ReservedHandles may only be assigned by admins
+	ReservedHandles []string `mapstructure:"RESERVED_HANDLES"`
+	// HandleRenameInterval is the least time between two handle renames by the owner
+	HandleRenameInterval time.Duration `mapstructure:"HANDLE_RENAME_INTERVAL"`
+	// HandleRedirectPeriod is how long a former handle keeps redirecting
+	HandleRedirectPeriod time.Duration `mapstructure:"HANDLE_REDIRECT_PERIOD"`
 }
 
 func LoadConfig() (*Config, error) {
 	viper.SetConfigFile("config/config.yaml")
 	viper.AutomaticEnv()
+	viper.SetDefault("MIGRATE_ON_STARTUP", true)
+	viper.SetDefault("DB_DRIVER", DriverMongo)
+	viper.SetDefault("POSTGRES_DSN", "")
+	viper.SetDefault("KEYCLOAK_CLIENT_ID", "")
+	viper.SetDefault("KEYCLOAK_ISSUER", "")
+	viper.SetDefault("KEYCLOAK_ADMIN_CLIENT_ID", "")
+	viper.SetDefault("KEYCLOAK_ADMIN_CLIENT_SECRET", "")
+	viper.SetDefault("ASSIGNABLE_ROLES", []string{"user-admin"})
+	viper.SetDefault("USER_RESTORE_GRACE_PERIOD", 30*24*time.Hour)
+	viper.SetDefault("USER_RETENTION_PERIOD", 90*24*time.Hour)
+	viper.SetDefault("USER_PURGE_INTERVAL", time.Hour)
+	viper.SetDefault("MODERATION_EXPIRY_INTERVAL", time.Minute)
+	viper.SetDefault("OUTBOX_RELAY_INTERVAL", time.Second)
+	viper.SetDefault("OUTBOX_MAX_ATTEMPTS", 20)
+	viper.SetDefault("COMPLETION_REQUIRED_FIELDS", []string{"firstname", "lastname"})
+	viper.SetDefault("COMPLETION_RECOMMENDED_FIELDS", []string{
+		"about", "date_of_birth", "gender", "location", "socials", "avatar_url",
+	})
+	viper.SetDefault("RESERVED_HANDLES", []string{
+		"admin", "administrator", "api", "help", "me", "moderator", "root",
+		"search", "security", "settings", "support", "system", "user", "users",
+	})
+	viper.SetDefault("HANDLE_RENAME_INTERVAL", 30*24*time.Hour)
+	viper.SetDefault("HANDLE_REDIRECT_PERIOD", 90*24*time.Hour)
 
 	if err := viper.ReadInConfig(); err != nil {
 		logging.Instance.Errorf("Couldn't load config.yaml: %v", err)
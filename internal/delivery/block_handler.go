@@ -0,0 +1,60 @@
+package delivery
+
+import (
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+// Block блокирует пользователя
+// @Summary Блокировка пользователя
+// @Description Блокирует пользователя от имени владельца токена: заблокированный больше не видит профиль владельца (404), не находит его в списках и поиске и не может на него подписаться; владелец так же не видит заблокированного. Подписки в обе стороны отменяются. Повторная блокировка ничего не меняет
+// @Tags users
+// @Produce json
+// @Param id path string true "ID блокируемого пользователя"
+// @Success 204
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 410 {object} map[string]string
+// @Failure 422 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /api/v1/users/{id}/block [put]
+func (h *UserHandler) Block(ctx *gin.Context) {
+	blockerID, blockedID, ok := callerAndTarget(ctx)
+	if !ok {
+		return
+	}
+
+	if err := h.service.Block(ctx.Request.Context(), blockerID, blockedID); err != nil {
+		writeError(ctx, err, "Could not block user")
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
+
+// Unblock снимает блокировку пользователя
+// @Summary Разблокировка пользователя
+// @Description Снимает блокировку, установленную владельцем токена. Отмененные блокировкой подписки не восстанавливаются. Разблокировка незаблокированного пользователя ничего не меняет
+// @Tags users
+// @Produce json
+// @Param id path string true "ID разблокируемого пользователя"
+// @Success 204
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 422 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /api/v1/users/{id}/block [delete]
+func (h *UserHandler) Unblock(ctx *gin.Context) {
+	blockerID, blockedID, ok := callerAndTarget(ctx)
+	if !ok {
+		return
+	}
+
+	if err := h.service.Unblock(ctx.Request.Context(), blockerID, blockedID); err != nil {
+		writeError(ctx, err, "Could not unblock user")
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
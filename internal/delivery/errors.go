@@ -0,0 +1,95 @@
+package delivery
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/Sayan80bayev/go-project/pkg/logging"
+	"github.com/gin-gonic/gin"
+	"userService/internal/service"
+)
+
+// errorResponse is how a service error is rendered to clients.
+type errorResponse struct {
+	status  int
+	code    string
+	message string
+}
+
+// errorResponses is matched in order, so specific errors come before the kind
+// they belong to. Codes are part of the API and must not change.
+var errorResponses = []struct {
+	err  error
+	resp errorResponse
+}{
+	{service.ErrPreconditionFailed, errorResponse{http.StatusPreconditionFailed, "PRECONDITION_FAILED", "Profile was changed since it was read"}},
+	{service.ErrVersionConflict, errorResponse{http.StatusConflict, "CONFLICT", "Profile was changed concurrently, retry with a fresh ETag"}},
+	{service.ErrNotDeleted, errorResponse{http.StatusConflict, "NOT_DELETED", "User is not deleted"}},
+	{service.ErrRestoreExpired, errorResponse{http.StatusGone, "RESTORE_EXPIRED", "Restore window has expired"}},
+	{service.ErrUserDeleted, errorResponse{http.StatusGone, "USER_DELETED", "User has been deleted"}},
+	{service.ErrUserNotFound, errorResponse{http.StatusNotFound, "NOT_FOUND", "User not found"}},
+	{service.ErrRevisionNotFound, errorResponse{http.StatusNotFound, "REVISION_NOT_FOUND", "Revision not found"}},
+	{service.ErrAccountRestricted, errorResponse{http.StatusForbidden, "ACCOUNT_RESTRICTED", "Account is banned or suspended"}},
+	{service.ErrNotModerated, errorResponse{http.StatusConflict, "NOT_MODERATED", "User is not banned or suspended"}},
+	{service.ErrRoleSync, errorResponse{http.StatusBadGateway, "ROLE_SYNC_FAILED", "Could not update roles in the identity provider"}},
+	{service.ErrSelfBlock, errorResponse{http.StatusUnprocessableEntity, "SELF_BLOCK", "You cannot block yourself"}},
+	{service.ErrSelfFollow, errorResponse{http.StatusUnprocessableEntity, "SELF_FOLLOW", "You cannot follow yourself"}},
+	{service.ErrHandleRenameTooSoon, errorResponse{http.StatusTooManyRequests, "HANDLE_RENAME_TOO_SOON", "Handle was changed too recently"}},
+
+	{service.ErrNotFound, errorResponse{http.StatusNotFound, "NOT_FOUND", "Not found"}},
+	{service.ErrConflict, errorResponse{http.StatusConflict, "CONFLICT", "Conflicts with the current state"}},
+	{service.ErrForbidden, errorResponse{http.StatusForbidden, "FORBIDDEN", "You are not allowed to do this"}},
+	{service.ErrDeleted, errorResponse{http.StatusGone, "DELETED", "No longer available"}},
+}
+
+// writeError renders err from the service layer. Validation and query errors
+// carry their details; anything unexpected is logged and answered with a bare
+// 500 and message so internal error text never reaches the client.
+func writeError(ctx *gin.Context, err error, message string) {
+	var verr *service.ValidationError
+	var conflict *service.ConflictError
+	switch {
+	case errors.As(err, &verr):
+		ctx.JSON(http.StatusUnprocessableEntity, gin.H{
+			"status":  "error",
+			"code":    "VALIDATION_FAILED",
+			"message": "Validation failed",
+			"details": verr.Fields,
+		})
+		return
+	case errors.As(err, &conflict):
+		ctx.JSON(http.StatusConflict, gin.H{
+			"status":  "error",
+			"code":    "ALREADY_TAKEN",
+			"message": conflict.Error(),
+			"details": []service.FieldError{{Field: conflict.Field, Message: "is already taken"}},
+		})
+		return
+	case service.IsQueryError(err):
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"status":  "error",
+			"code":    "INVALID_INPUT",
+			"message": "Invalid query parameters",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	for _, m := range errorResponses {
+		if errors.Is(err, m.err) {
+			ctx.JSON(m.resp.status, gin.H{
+				"status":  "error",
+				"code":    m.resp.code,
+				"message": m.resp.message,
+			})
+			return
+		}
+	}
+
+	logging.Instance.Errorf("%s: %v", message, err)
+	ctx.JSON(http.StatusInternalServerError, gin.H{
+		"status":  "error",
+		"code":    "SERVER_ERROR",
+		"message": message,
+	})
+}
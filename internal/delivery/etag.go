@@ -0,0 +1,36 @@
+package delivery
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+
+	"userService/internal/service"
+)
+
+var errInvalidIfMatch = errors.New("If-Match must be a single entity tag or *")
+
+// formatETag renders a profile version as a strong entity tag.
+func formatETag(version int64) string {
+	return strconv.Quote(strconv.FormatInt(version, 10))
+}
+
+// parseIfMatch reads the expected version from an If-Match header value.
+// "*" matches any version; weak tags are accepted since versions are exact.
+func parseIfMatch(header string) (int64, error) {
+	header = strings.TrimSpace(header)
+	if header == "*" {
+		return service.AnyVersion, nil
+	}
+
+	tag := strings.TrimPrefix(header, "W/")
+	raw, err := strconv.Unquote(tag)
+	if err != nil {
+		return 0, errInvalidIfMatch
+	}
+	version, err := strconv.ParseInt(raw, 10, 64)
+	if err != nil || version < 0 {
+		return 0, errInvalidIfMatch
+	}
+	return version, nil
+}
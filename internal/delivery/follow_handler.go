@@ -0,0 +1,169 @@
+package delivery
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"net/http"
+	"strconv"
+	"userService/internal/auth"
+	"userService/internal/service"
+)
+
+// Follow подписывает текущего пользователя на другого
+// @Summary Подписка на пользователя
+// @Description Подписывает владельца токена на пользователя. Повторная подписка ничего не меняет. Подписаться на самого себя нельзя
+// @Tags users
+// @Produce json
+// @Param id path string true "ID пользователя, на которого подписываются"
+// @Success 204
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 410 {object} map[string]string
+// @Failure 422 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /api/v1/users/{id}/follow [put]
+func (h *UserHandler) Follow(ctx *gin.Context) {
+	followerID, followeeID, ok := callerAndTarget(ctx)
+	if !ok {
+		return
+	}
+
+	if err := h.service.Follow(ctx.Request.Context(), followerID, followeeID); err != nil {
+		writeError(ctx, err, "Could not follow user")
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
+
+// Unfollow отписывает текущего пользователя от другого
+// @Summary Отписка от пользователя
+// @Description Отписывает владельца токена от пользователя. Отписка от пользователя, на которого нет подписки, ничего не меняет
+// @Tags users
+// @Produce json
+// @Param id path string true "ID пользователя, от которого отписываются"
+// @Success 204
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 422 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /api/v1/users/{id}/follow [delete]
+func (h *UserHandler) Unfollow(ctx *gin.Context) {
+	followerID, followeeID, ok := callerAndTarget(ctx)
+	if !ok {
+		return
+	}
+
+	if err := h.service.Unfollow(ctx.Request.Context(), followerID, followeeID); err != nil {
+		writeError(ctx, err, "Could not unfollow user")
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
+
+// ListFollowers возвращает подписчиков пользователя
+// @Summary Подписчики пользователя
+// @Description Возвращает подписчиков пользователя, начиная с подписавшихся последними. Удаленные и заблокированные пользователи не показываются; удаленные учитываются в счетчиках, пока профиль не стерт окончательно
+// @Tags users
+// @Produce json
+// @Param id path string true "ID пользователя"
+// @Param limit query int false "Размер страницы (по умолчанию 20, максимум 100)"
+// @Param cursor query string false "Курсор следующей страницы"
+// @Success 200 {object} response.UserPageResponse
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 410 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /api/v1/users/{id}/followers [get]
+func (h *UserHandler) ListFollowers(ctx *gin.Context) {
+	userUUID, ok := targetUserID(ctx)
+	if !ok {
+		return
+	}
+	params, ok := followParams(ctx)
+	if !ok {
+		return
+	}
+
+	page, err := h.service.ListFollowers(ctx.Request.Context(), userUUID, params)
+	if err != nil {
+		writeError(ctx, err, "Could not list followers")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, page)
+}
+
+// ListFollowing возвращает подписки пользователя
+// @Summary Подписки пользователя
+// @Description Возвращает пользователей, на которых подписан пользователь, начиная с последних подписок. Удаленные и заблокированные пользователи не показываются; удаленные учитываются в счетчиках, пока профиль не стерт окончательно
+// @Tags users
+// @Produce json
+// @Param id path string true "ID пользователя"
+// @Param limit query int false "Размер страницы (по умолчанию 20, максимум 100)"
+// @Param cursor query string false "Курсор следующей страницы"
+// @Success 200 {object} response.UserPageResponse
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 410 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /api/v1/users/{id}/following [get]
+func (h *UserHandler) ListFollowing(ctx *gin.Context) {
+	userUUID, ok := targetUserID(ctx)
+	if !ok {
+		return
+	}
+	params, ok := followParams(ctx)
+	if !ok {
+		return
+	}
+
+	page, err := h.service.ListFollowing(ctx.Request.Context(), userUUID, params)
+	if err != nil {
+		writeError(ctx, err, "Could not list following")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, page)
+}
+
+// callerAndTarget returns the caller and the user of the id path parameter,
+// such as follower and followee. On failure it writes the error response and
+// returns false.
+func callerAndTarget(ctx *gin.Context) (caller, target uuid.UUID, ok bool) {
+	p := auth.FromContext(ctx)
+	if p == nil {
+		ctx.JSON(http.StatusUnauthorized, gin.H{
+			"status":  "error",
+			"code":    "UNAUTHORIZED",
+			"message": "You're unauthorized",
+		})
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	target, ok = targetUserID(ctx)
+	return p.Subject, target, ok
+}
+
+// followParams reads the paging query parameters of follower lists. On
+// failure it writes the error response and returns false.
+func followParams(ctx *gin.Context) (service.FollowParams, bool) {
+	params := service.FollowParams{Cursor: ctx.Query("cursor")}
+	if raw := ctx.Query("limit"); raw != "" {
+		limit, err := strconv.Atoi(raw)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{
+				"status":  "error",
+				"code":    "INVALID_INPUT",
+				"message": "Invalid query parameters",
+				"details": service.ErrInvalidLimit.Error(),
+			})
+			return params, false
+		}
+		params.Limit = limit
+	}
+	return params, true
+}
@@ -0,0 +1,58 @@
+package delivery
+
+import (
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"userService/internal/auth"
+	"userService/internal/service"
+)
+
+type LifecycleHandler struct {
+	service *service.LifecycleService
+}
+
+func NewLifecycleHandler(lifecycleService *service.LifecycleService) *LifecycleHandler {
+	return &LifecycleHandler{service: lifecycleService}
+}
+
+// RestoreUser восстанавливает удаленного пользователя
+// @Summary Восстановление пользователя
+// @Description Отменяет мягкое удаление профиля. Владелец может восстановить профиль в течение льготного периода, администратор — до окончательного удаления
+// @Tags users
+// @Produce json
+// @Param id path string true "ID пользователя"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 410 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /api/v1/users/{id}/restore [post]
+func (h *LifecycleHandler) RestoreUser(ctx *gin.Context) {
+	principal := auth.FromContext(ctx)
+	if principal == nil {
+		ctx.JSON(http.StatusUnauthorized, gin.H{
+			"status":  "error",
+			"code":    "UNAUTHORIZED",
+			"message": "You're unauthorized",
+		})
+		return
+	}
+
+	userUUID, ok := targetUserID(ctx)
+	if !ok {
+		return
+	}
+
+	if err := h.service.RestoreUser(ctx.Request.Context(), userUUID, principal.IsAdmin()); err != nil {
+		writeError(ctx, err, "Could not restore user")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"status":  "success",
+		"message": "Successfully restored user",
+	})
+}
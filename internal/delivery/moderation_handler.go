@@ -0,0 +1,143 @@
+package delivery
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"userService/internal/service"
+	"userService/internal/transport/request"
+)
+
+type ModerationHandler struct {
+	service *service.ModerationService
+}
+
+func NewModerationHandler(moderationService *service.ModerationService) *ModerationHandler {
+	return &ModerationHandler{service: moderationService}
+}
+
+// BanUser блокирует пользователя
+// @Summary Блокировка пользователя
+// @Description Блокирует профиль бессрочно или до expires_at. Заблокированный профиль скрыт от всех, кроме владельца и администраторов, и недоступен владельцу для изменения. Доступно только администраторам
+// @Tags moderation
+// @Accept json
+// @Produce json
+// @Param id path string true "ID пользователя"
+// @Param moderation body request.ModerationRequest true "Причина и срок блокировки"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Failure 410 {object} map[string]string
+// @Failure 422 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /api/v1/users/{id}/ban [post]
+func (h *ModerationHandler) BanUser(ctx *gin.Context) {
+	h.restrict(ctx, h.service.BanUser, "Successfully banned user", "Could not ban user")
+}
+
+// SuspendUser приостанавливает пользователя
+// @Summary Приостановка пользователя
+// @Description Приостанавливает профиль до expires_at, после чего ограничение снимается автоматически. Доступно только администраторам
+// @Tags moderation
+// @Accept json
+// @Produce json
+// @Param id path string true "ID пользователя"
+// @Param moderation body request.ModerationRequest true "Причина и срок приостановки"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Failure 410 {object} map[string]string
+// @Failure 422 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /api/v1/users/{id}/suspend [post]
+func (h *ModerationHandler) SuspendUser(ctx *gin.Context) {
+	h.restrict(ctx, h.service.SuspendUser, "Successfully suspended user", "Could not suspend user")
+}
+
+// UnbanUser снимает блокировку или приостановку
+// @Summary Снятие ограничений
+// @Description Снимает блокировку или приостановку профиля. Доступно только администраторам
+// @Tags moderation
+// @Accept json
+// @Produce json
+// @Param id path string true "ID пользователя"
+// @Param moderation body request.ModerationRequest false "Причина снятия ограничений"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Failure 410 {object} map[string]string
+// @Failure 422 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /api/v1/users/{id}/unban [post]
+func (h *ModerationHandler) UnbanUser(ctx *gin.Context) {
+	userUUID, ok := targetUserID(ctx)
+	if !ok {
+		return
+	}
+	req, ok := bindModeration(ctx)
+	if !ok {
+		return
+	}
+
+	if err := h.service.UnbanUser(ctx.Request.Context(), userUUID, req.Reason); err != nil {
+		writeError(ctx, err, "Could not unban user")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"status":  "success",
+		"message": "Successfully unbanned user",
+	})
+}
+
+type restrictFunc func(ctx context.Context, userID uuid.UUID, a service.ModerationAction) error
+
+func (h *ModerationHandler) restrict(ctx *gin.Context, restrict restrictFunc, success, failure string) {
+	userUUID, ok := targetUserID(ctx)
+	if !ok {
+		return
+	}
+	req, ok := bindModeration(ctx)
+	if !ok {
+		return
+	}
+
+	action := service.ModerationAction{Reason: req.Reason, ExpiresAt: req.ExpiresAt}
+	if err := restrict(ctx.Request.Context(), userUUID, action); err != nil {
+		writeError(ctx, err, failure)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"status":  "success",
+		"message": success,
+	})
+}
+
+// bindModeration reads the optional request body.
+func bindModeration(ctx *gin.Context) (request.ModerationRequest, bool) {
+	var req request.ModerationRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"status":  "error",
+			"code":    "INVALID_INPUT",
+			"message": "Invalid request body",
+			"details": err.Error(),
+		})
+		return req, false
+	}
+	return req, true
+}
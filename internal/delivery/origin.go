@@ -0,0 +1,22 @@
+package delivery
+
+import (
+	"github.com/gin-gonic/gin"
+	"userService/internal/auth"
+	"userService/internal/service"
+)
+
+// TrackOrigin records HTTP as the source of the writes made by a request and
+// the caller as their actor. It must run after auth.Verifier.Identify.
+func TrackOrigin() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		origin := service.Origin{Source: service.SourceHTTP}
+		if p := auth.FromContext(ctx); p != nil {
+			subject := p.Subject
+			origin.Actor = &subject
+			origin.Admin = p.IsAdmin()
+		}
+		ctx.Request = ctx.Request.WithContext(service.WithOrigin(ctx.Request.Context(), origin))
+		ctx.Next()
+	}
+}
@@ -0,0 +1,109 @@
+package delivery
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"userService/internal/service"
+	"userService/internal/transport/request"
+)
+
+type RolesHandler struct {
+	service *service.RoleService
+}
+
+func NewRolesHandler(roleService *service.RoleService) *RolesHandler {
+	return &RolesHandler{service: roleService}
+}
+
+// GrantRoles назначает роли пользователю
+// @Summary Назначение ролей
+// @Description Добавляет пользователю роли приложения и синхронизирует их с Keycloak. Уже назначенные роли игнорируются. Доступно только администраторам
+// @Tags roles
+// @Accept json
+// @Produce json
+// @Param id path string true "ID пользователя"
+// @Param roles body request.RolesRequest true "Назначаемые роли"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Failure 410 {object} map[string]string
+// @Failure 422 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Failure 502 {object} map[string]string
+// @Router /api/v1/users/{id}/roles [post]
+func (h *RolesHandler) GrantRoles(ctx *gin.Context) {
+	userUUID, ok := targetUserID(ctx)
+	if !ok {
+		return
+	}
+
+	var req request.RolesRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"status":  "error",
+			"code":    "INVALID_INPUT",
+			"message": "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	roles, err := h.service.GrantRoles(ctx.Request.Context(), userUUID, req.Roles)
+	if err != nil {
+		writeError(ctx, err, "Could not grant roles")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"status": "success",
+		"roles":  nonNil(roles),
+	})
+}
+
+// RevokeRole отзывает роль у пользователя
+// @Summary Отзыв роли
+// @Description Снимает с пользователя роль приложения и синхронизирует изменение с Keycloak. Отзыв неназначенной роли ничего не меняет. Доступно только администраторам
+// @Tags roles
+// @Produce json
+// @Param id path string true "ID пользователя"
+// @Param role path string true "Название роли"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Failure 410 {object} map[string]string
+// @Failure 422 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Failure 502 {object} map[string]string
+// @Router /api/v1/users/{id}/roles/{role} [delete]
+func (h *RolesHandler) RevokeRole(ctx *gin.Context) {
+	userUUID, ok := targetUserID(ctx)
+	if !ok {
+		return
+	}
+
+	roles, err := h.service.RevokeRoles(ctx.Request.Context(), userUUID, []string{ctx.Param("role")})
+	if err != nil {
+		writeError(ctx, err, "Could not revoke role")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"status": "success",
+		"roles":  nonNil(roles),
+	})
+}
+
+// nonNil renders an empty role list as [] rather than null.
+func nonNil(roles []string) []string {
+	if roles == nil {
+		return []string{}
+	}
+	return roles
+}
@@ -1,14 +1,23 @@
 package delivery
 
 import (
+	"fmt"
 	"github.com/Sayan80bayev/go-project/pkg/logging"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
+	"io"
 	"net/http"
+	"strconv"
+	"strings"
+	"userService/internal/auth"
 	"userService/internal/service"
 	"userService/internal/transport/request"
+	"userService/internal/transport/response"
 )
 
+// maxPatchBodySize bounds merge patch documents; profiles are far smaller.
+const maxPatchBodySize = 64 << 10
+
 type UserHandler struct {
 	service *service.UserService
 }
@@ -19,44 +28,49 @@ func NewUserHandler(userService *service.UserService) *UserHandler {
 
 // UpdateUser обновляет информацию о пользователе
 // @Summary Обновление пользователя
-// @Description Позволяет обновить информацию о пользователе, включая аватар
+// @Description Позволяет обновить информацию о пользователе, включая аватар. Доступно владельцу и администраторам
 // @Tags users
 // @Accept multipart/form-data
 // @Produce json
-// @Param userId header string true "ID пользователя"
+// @Param id path string true "ID пользователя"
+// @Param If-Match header string true "ETag версии профиля, полученный из GET"
 // @Param avatar formData file false "Аватар пользователя"
 // @Param user body request.UserRequest true "Данные пользователя"
 // @Success 200 {object} map[string]string
 // @Failure 400 {object} map[string]string
 // @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Failure 412 {object} map[string]string
+// @Failure 422 {object} map[string]string
+// @Failure 428 {object} map[string]string
 // @Failure 500 {object} map[string]string
-// @Router /api/v1/users [put]
+// @Failure 410 {object} map[string]string
+// @Router /api/v1/users/{id} [put]
+// @Router /api/v1/users/me [put]
 func (h *UserHandler) UpdateUser(ctx *gin.Context) {
-	userID, exists := ctx.Get("user_id")
-	if !exists {
-		ctx.JSON(http.StatusUnauthorized, gin.H{
-			"status":  "error",
-			"code":    "UNAUTHORIZED",
-			"message": "User ID not found in context",
-		})
+	userUUID, ok := targetUserID(ctx)
+	if !ok {
 		return
 	}
 
-	userUUID, ok := userID.(uuid.UUID)
-	if !ok {
-		ctx.JSON(http.StatusUnauthorized, gin.H{
+	ifMatch := ctx.GetHeader("If-Match")
+	if ifMatch == "" {
+		ctx.JSON(http.StatusPreconditionRequired, gin.H{
 			"status":  "error",
-			"code":    "UNAUTHORIZED",
-			"message": "Invalid user ID type",
+			"code":    "PRECONDITION_REQUIRED",
+			"message": "If-Match header with the profile ETag is required",
 		})
 		return
 	}
-
-	if !exists {
-		ctx.JSON(http.StatusUnauthorized, gin.H{
+	expectedVersion, err := parseIfMatch(ifMatch)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
 			"status":  "error",
-			"code":    "UNAUTHORIZED",
-			"message": "You're unauthorized",
+			"code":    "INVALID_INPUT",
+			"message": "Invalid If-Match header",
+			"details": err.Error(),
 		})
 		return
 	}
@@ -82,20 +96,13 @@ func (h *UserHandler) UpdateUser(ctx *gin.Context) {
 			"status":  "error",
 			"code":    "INVALID_INPUT",
 			"message": "Could not get avatar",
-			"details": err.Error(),
 		})
 		logging.Instance.Warn("Error on getting avatar", err.Error())
 		return
 	}
 
-	if err := h.service.UpdateUser(ctx.Request.Context(), ur, userUUID); err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{
-			"status":  "error",
-			"code":    "SERVER_ERROR",
-			"message": "Could not update user",
-			"details": err.Error(),
-		})
-		logging.Instance.Warn("Error on updating user", err)
+	if err := h.service.UpdateUser(ctx.Request.Context(), ur, userUUID, expectedVersion); err != nil {
+		writeError(ctx, err, "Could not update user")
 		return
 	}
 
@@ -105,36 +112,154 @@ func (h *UserHandler) UpdateUser(ctx *gin.Context) {
 	})
 }
 
-// DeleteUser удаляет пользователя
-// @Summary Удаление пользователя
-// @Description Удаляет пользователя по ID
+// PatchUser частично обновляет профиль пользователя
+// @Summary Частичное обновление пользователя
+// @Description Применяет JSON Merge Patch (RFC 7396): изменяются только переданные поля, null удаляет поле. Доступно владельцу и администраторам
 // @Tags users
+// @Accept application/merge-patch+json
 // @Produce json
-// @Param userId header string true "ID пользователя"
-// @Success 200 {object} map[string]string
+// @Param id path string true "ID пользователя"
+// @Param If-Match header string false "ETag версии профиля, полученный из GET"
+// @Param patch body object true "Merge patch с полями firstname, lastname, about, date_of_birth, gender, location, socials"
+// @Success 200 {object} response.UserResponse
+// @Header 200 {string} ETag "Новая версия профиля"
+// @Failure 400 {object} map[string]string
 // @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Failure 412 {object} map[string]string
+// @Failure 415 {object} map[string]string
+// @Failure 422 {object} map[string]string
 // @Failure 500 {object} map[string]string
-// @Router /api/v1/users [delete]
-func (h *UserHandler) DeleteUser(ctx *gin.Context) {
-	userID, exists := ctx.Get("user_id")
-	if !exists {
-		ctx.JSON(http.StatusUnauthorized, gin.H{
+// @Failure 410 {object} map[string]string
+// @Router /api/v1/users/{id} [patch]
+// @Router /api/v1/users/me [patch]
+func (h *UserHandler) PatchUser(ctx *gin.Context) {
+	userUUID, ok := targetUserID(ctx)
+	if !ok {
+		return
+	}
+
+	switch ctx.ContentType() {
+	case "application/merge-patch+json", "application/json":
+	default:
+		ctx.JSON(http.StatusUnsupportedMediaType, gin.H{
 			"status":  "error",
-			"code":    "UNAUTHORIZED",
-			"message": "You're unauthorized",
+			"code":    "UNSUPPORTED_MEDIA_TYPE",
+			"message": "Content-Type must be application/merge-patch+json",
 		})
 		return
 	}
 
-	err := h.service.DeleteUserById(ctx.Request.Context(), userID.(uuid.UUID))
+	expectedVersion := service.AnyVersion
+	if ifMatch := ctx.GetHeader("If-Match"); ifMatch != "" {
+		var err error
+		if expectedVersion, err = parseIfMatch(ifMatch); err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{
+				"status":  "error",
+				"code":    "INVALID_INPUT",
+				"message": "Invalid If-Match header",
+				"details": err.Error(),
+			})
+			return
+		}
+	}
+
+	body, err := io.ReadAll(io.LimitReader(ctx.Request.Body, maxPatchBodySize))
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{
+		ctx.JSON(http.StatusBadRequest, gin.H{
 			"status":  "error",
-			"code":    "SERVER_ERROR",
-			"message": "Could not delete user",
+			"code":    "INVALID_INPUT",
+			"message": "Could not read request body",
+		})
+		return
+	}
+
+	patch, err := service.ParseMergePatch(body)
+	if err != nil {
+		writeError(ctx, err, "Could not update user")
+		return
+	}
+
+	user, err := h.service.PatchUser(ctx.Request.Context(), userUUID, patch, expectedVersion)
+	if err != nil {
+		writeError(ctx, err, "Could not update user")
+		return
+	}
+
+	ctx.Header("ETag", formatETag(user.Version))
+	ctx.JSON(http.StatusOK, user)
+}
+
+// UpdatePrivacy меняет видимость полей профиля
+// @Summary Настройки приватности
+// @Description Задает видимость полей email, date_of_birth, gender, location, socials: public — всем, authenticated — авторизованным пользователям, private — только владельцу и администраторам. null возвращает значение по умолчанию. Доступно владельцу и администраторам
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param id path string true "ID пользователя"
+// @Param privacy body request.PrivacyRequest true "Видимость по полям"
+// @Success 200 {object} response.UserResponse
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Failure 410 {object} map[string]string
+// @Failure 422 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /api/v1/users/{id}/privacy [patch]
+// @Router /api/v1/users/me/privacy [patch]
+func (h *UserHandler) UpdatePrivacy(ctx *gin.Context) {
+	userUUID, ok := targetUserID(ctx)
+	if !ok {
+		return
+	}
+
+	var req request.PrivacyRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"status":  "error",
+			"code":    "INVALID_INPUT",
+			"message": "Invalid request body",
 			"details": err.Error(),
 		})
-		logging.Instance.Warn("Error on deleting user ", err)
+		return
+	}
+
+	user, err := h.service.UpdatePrivacy(ctx.Request.Context(), userUUID, req)
+	if err != nil {
+		writeError(ctx, err, "Could not update privacy settings")
+		return
+	}
+
+	ctx.Header("ETag", formatETag(user.Version))
+	ctx.JSON(http.StatusOK, user)
+}
+
+// DeleteUser удаляет пользователя
+// @Summary Удаление пользователя
+// @Description Удаляет пользователя по ID. Доступно владельцу и администраторам
+// @Tags users
+// @Produce json
+// @Param id path string true "ID пользователя"
+// @Success 200 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 410 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /api/v1/users/{id} [delete]
+// @Router /api/v1/users/me [delete]
+func (h *UserHandler) DeleteUser(ctx *gin.Context) {
+	userUUID, ok := targetUserID(ctx)
+	if !ok {
+		return
+	}
+
+	err := h.service.DeleteUserById(ctx.Request.Context(), userUUID)
+	if err != nil {
+		writeError(ctx, err, "Could not delete user")
 		return
 	}
 
@@ -144,38 +269,135 @@ func (h *UserHandler) DeleteUser(ctx *gin.Context) {
 	})
 }
 
-// GetAllUsers возвращает список всех пользователей
-// @Summary Получение всех пользователей
-// @Description Возвращает список всех пользователей
+// GetAllUsers возвращает страницу пользователей
+// @Summary Получение пользователей
+// @Description Возвращает страницу пользователей с курсорной пагинацией и фильтрами
 // @Tags users
 // @Produce json
-// @Success 200 {array} model.User
+// @Param limit query int false "Размер страницы (по умолчанию 20, максимум 100)"
+// @Param cursor query string false "Курсор следующей страницы"
+// @Param sort query string false "Ключ сортировки: created_at, updated_at (префикс - для убывания)"
+// @Param gender query string false "Пол: male, female, other"
+// @Param location query string false "Местоположение (без учета регистра)"
+// @Param needs_completion query bool false "Профиль требует заполнения"
+// @Param has_avatar query bool false "Наличие аватара"
+// @Param moderated query bool false "Заблокированные и приостановленные профили (true — только для администраторов)"
+// @Param created_from query string false "Создан не раньше (RFC3339)"
+// @Param created_to query string false "Создан не позже (RFC3339)"
+// @Param updated_from query string false "Обновлен не раньше (RFC3339)"
+// @Param updated_to query string false "Обновлен не позже (RFC3339)"
+// @Param ids query string false "Список ID через запятую; возвращает response.UserBatchResponse и не сочетается с другими параметрами"
+// @Success 200 {object} response.UserPageResponse
+// @Failure 400 {object} map[string]string
 // @Failure 500 {object} map[string]string
 // @Router /api/v1/users [get]
 func (h *UserHandler) GetAllUsers(ctx *gin.Context) {
-	users, err := h.service.GetAllUsers(ctx.Request.Context())
+	if _, ok := ctx.GetQuery("ids"); ok {
+		h.batchGetUsers(ctx)
+		return
+	}
+
+	params, err := bindListParams(ctx)
+	if err != nil {
+		writeError(ctx, err, "Could not get users")
+		return
+	}
+
+	page, err := h.service.ListUsers(ctx.Request.Context(), params)
+	if err != nil {
+		writeError(ctx, err, "Could not get users")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, page)
+}
+
+// batchGetUsers serves GET /api/v1/users?ids=a,b,c
+func (h *UserHandler) batchGetUsers(ctx *gin.Context) {
+	if len(ctx.Request.URL.Query()) > 1 {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"status":  "error",
+			"code":    "INVALID_INPUT",
+			"message": "ids cannot be combined with other parameters",
+		})
+		return
+	}
+
+	ids, err := parseIDList(ctx.Query("ids"))
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{
+		ctx.JSON(http.StatusBadRequest, gin.H{
 			"status":  "error",
-			"code":    "SERVER_ERROR",
-			"message": "Could not get users",
+			"code":    "INVALID_INPUT",
+			"message": "Invalid ids",
 			"details": err.Error(),
 		})
-		logging.Instance.Warn("Error on getting users", err)
 		return
 	}
 
-	ctx.JSON(http.StatusOK, users)
+	batch, err := h.service.GetUsersByIds(ctx.Request.Context(), ids)
+	if err != nil {
+		writeError(ctx, err, "Could not get users")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, batch)
+}
+
+// SearchUsers выполняет полнотекстовый поиск пользователей
+// @Summary Поиск пользователей
+// @Description Ищет пользователей по имени, фамилии, описанию и местоположению, сортируя по релевантности
+// @Tags users
+// @Produce json
+// @Param q query string true "Поисковый запрос"
+// @Param limit query int false "Размер страницы (по умолчанию 20, максимум 100)"
+// @Param cursor query string false "Курсор следующей страницы"
+// @Success 200 {object} response.UserPageResponse
+// @Failure 400 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /api/v1/users/search [get]
+func (h *UserHandler) SearchUsers(ctx *gin.Context) {
+	params := service.SearchUsersParams{
+		Query:  ctx.Query("q"),
+		Cursor: ctx.Query("cursor"),
+	}
+	if raw := ctx.Query("limit"); raw != "" {
+		limit, err := strconv.Atoi(raw)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{
+				"status":  "error",
+				"code":    "INVALID_INPUT",
+				"message": "Invalid query parameters",
+				"details": service.ErrInvalidLimit.Error(),
+			})
+			return
+		}
+		params.Limit = limit
+	}
+
+	page, err := h.service.SearchUsers(ctx.Request.Context(), params)
+	if err != nil {
+		writeError(ctx, err, "Could not search users")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, page)
 }
 
 // GetUserById получает пользователя по ID
-// @Summary Получение пользователя по ID
-// @Description Возвращает информацию о пользователе по его ID
+// @Summary Получение пользователя по ID или handle
+// @Description Возвращает информацию о пользователе по его ID. Удаленные профили возвращают 410, если администратор не запросил include_deleted. Вместо ID можно передать handle с префиксом @ (обрабатывает GetUserByHandle): регистр не учитывается, а прежний handle после переименования отвечает 307 с адресом текущего, пока не истечет срок перенаправления; include_deleted для handle не поддерживается
 // @Tags users
 // @Produce json
-// @Param id path int true "ID пользователя"
+// @Param id path string true "ID пользователя или handle с префиксом @, например @alice"
+// @Param include_deleted query bool false "Вернуть удаленный профиль (только для администраторов, только по ID)"
 // @Success 200 {object} model.User
+// @Header 200 {string} ETag "Версия профиля для If-Match"
+// @Header 307 {string} Location "Адрес профиля по текущему handle"
 // @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 410 {object} map[string]string
+// @Failure 500 {object} map[string]string
 // @Router /api/v1/users/{id} [get]
 func (h *UserHandler) GetUserById(ctx *gin.Context) {
 	userID := ctx.Param("id")
@@ -185,21 +407,231 @@ func (h *UserHandler) GetUserById(ctx *gin.Context) {
 			"status":  "error",
 			"code":    "BAD_REQUEST",
 			"message": "Could not parse id",
-			"details": err.Error(),
 		})
 		return
 	}
 
-	user, err := h.service.GetUserById(ctx.Request.Context(), userUUID)
+	includeDeleted := false
+	if raw := ctx.Query("include_deleted"); raw != "" {
+		if includeDeleted, err = strconv.ParseBool(raw); err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{
+				"status":  "error",
+				"code":    "BAD_REQUEST",
+				"message": "include_deleted must be a boolean",
+			})
+			return
+		}
+	}
+
+	var user *response.UserResponse
+	if includeDeleted {
+		user, err = h.service.GetUserByIdIncludingDeleted(ctx.Request.Context(), userUUID)
+	} else {
+		user, err = h.service.GetUserById(ctx.Request.Context(), userUUID)
+	}
+
+	if err != nil {
+		writeError(ctx, err, "Could not get user")
+		return
+	}
+
+	ctx.Header("ETag", formatETag(user.Version))
+	ctx.JSON(http.StatusOK, user)
+}
+
+// GetUserByHandle получает пользователя по handle (GET /api/v1/users/@{handle}).
+// Swagger не допускает @ в шаблоне пути, поэтому маршрут описан вместе с
+// GetUserById.
+func (h *UserHandler) GetUserByHandle(ctx *gin.Context) {
+	handle := service.NormalizeHandle(ctx.Param("handle"))
+	user, err := h.service.GetUserByHandle(ctx.Request.Context(), handle)
 	if err != nil {
+		writeError(ctx, err, "Could not get user")
+		return
+	}
+
+	// A former handle: send the client to the current one. The redirect is
+	// temporary because the old handle becomes free again once it expires.
+	if user.Handle != handle {
+		ctx.Redirect(http.StatusTemporaryRedirect, "/api/v1/users/@"+user.Handle)
+		return
+	}
+
+	ctx.Header("ETag", formatETag(user.Version))
+	ctx.JSON(http.StatusOK, user)
+}
+
+// ChangeHandle задает handle пользователя
+// @Summary Смена handle
+// @Description Задает уникальный handle: от 3 до 30 латинских букв, цифр и подчеркиваний, начинается с буквы. Регистр не учитывается. Владелец может менять handle не чаще установленного интервала; прежний handle какое-то время перенаправляет на профиль. Зарезервированные handle назначают только администраторы
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param id path string true "ID пользователя"
+// @Param handle body request.HandleRequest true "Новый handle"
+// @Success 200 {object} response.UserResponse
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Failure 410 {object} map[string]string
+// @Failure 422 {object} map[string]string
+// @Failure 429 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /api/v1/users/{id}/handle [put]
+// @Router /api/v1/users/me/handle [put]
+func (h *UserHandler) ChangeHandle(ctx *gin.Context) {
+	userUUID, ok := targetUserID(ctx)
+	if !ok {
+		return
+	}
+
+	var req request.HandleRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
 		ctx.JSON(http.StatusBadRequest, gin.H{
 			"status":  "error",
-			"code":    "BAD_REQUEST",
-			"message": "Could not get user",
+			"code":    "INVALID_INPUT",
+			"message": "Invalid request body",
 			"details": err.Error(),
 		})
 		return
 	}
 
+	user, err := h.service.ChangeHandle(ctx.Request.Context(), userUUID, req.Handle)
+	if err != nil {
+		writeError(ctx, err, "Could not change handle")
+		return
+	}
+
+	ctx.Header("ETag", formatETag(user.Version))
 	ctx.JSON(http.StatusOK, user)
 }
+
+// GetMe возвращает профиль текущего пользователя
+// @Summary Получение своего профиля
+// @Description Возвращает полный профиль владельца токена, включая поля, скрытые из публичного профиля
+// @Tags users
+// @Produce json
+// @Success 200 {object} response.UserResponse
+// @Header 200 {string} ETag "Версия профиля для If-Match"
+// @Failure 401 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 410 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /api/v1/users/me [get]
+func (h *UserHandler) GetMe(ctx *gin.Context) {
+	userUUID, ok := targetUserID(ctx)
+	if !ok {
+		return
+	}
+
+	user, err := h.service.GetOwnProfile(ctx.Request.Context(), userUUID)
+	if err != nil {
+		writeError(ctx, err, "Could not get user")
+		return
+	}
+
+	ctx.Header("ETag", formatETag(user.Version))
+	ctx.JSON(http.StatusOK, user)
+}
+
+// GetUserByEmail получает пользователя по email
+// @Summary Получение пользователя по email
+// @Description Возвращает профиль по email (без учета регистра и пробелов). Доступно только администраторам
+// @Tags users
+// @Produce json
+// @Param email query string true "Email пользователя"
+// @Success 200 {object} response.UserResponse
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 410 {object} map[string]string
+// @Failure 422 {object} map[string]string
+// @Router /api/v1/users/by-email [get]
+func (h *UserHandler) GetUserByEmail(ctx *gin.Context) {
+	user, err := h.service.GetUserByEmail(ctx.Request.Context(), ctx.Query("email"))
+	if err != nil {
+		writeError(ctx, err, "Could not get user")
+		return
+	}
+
+	ctx.Header("ETag", formatETag(user.Version))
+	ctx.JSON(http.StatusOK, user)
+}
+
+// targetUserID returns the profile a request acts on: the id path parameter,
+// or the caller on /me routes. Route policies have already authorized the
+// caller. On failure it writes the error response and returns false.
+func targetUserID(ctx *gin.Context) (uuid.UUID, bool) {
+	raw := ctx.Param("id")
+	if raw == "" {
+		if p := auth.FromContext(ctx); p != nil {
+			return p.Subject, true
+		}
+		ctx.JSON(http.StatusUnauthorized, gin.H{
+			"status":  "error",
+			"code":    "UNAUTHORIZED",
+			"message": "You're unauthorized",
+		})
+		return uuid.Nil, false
+	}
+
+	id, err := uuid.Parse(raw)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"status":  "error",
+			"code":    "BAD_REQUEST",
+			"message": "Could not parse id",
+		})
+		return uuid.Nil, false
+	}
+	return id, true
+}
+
+func parseIDList(raw string) ([]uuid.UUID, error) {
+	parts := strings.Split(raw, ",")
+	ids := make([]uuid.UUID, 0, len(parts))
+	for _, part := range parts {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		id, err := uuid.Parse(part)
+		if err != nil {
+			return nil, fmt.Errorf("%q is not a valid id", part)
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+func bindListParams(ctx *gin.Context) (service.ListUsersParams, error) {
+	for key := range ctx.Request.URL.Query() {
+		if _, ok := request.ListUsersQueryKeys[key]; !ok {
+			return service.ListUsersParams{}, fmt.Errorf("%w: unknown parameter %q", service.ErrInvalidFilter, key)
+		}
+	}
+
+	var q request.ListUsersQuery
+	if err := ctx.ShouldBindQuery(&q); err != nil {
+		return service.ListUsersParams{}, fmt.Errorf("%w: %v", service.ErrInvalidFilter, err)
+	}
+
+	return service.ListUsersParams{
+		Limit:  q.Limit,
+		Cursor: q.Cursor,
+		Sort:   q.Sort,
+		Filter: service.UserFilter{
+			Gender:          q.Gender,
+			Location:        q.Location,
+			NeedsCompletion: q.NeedsCompletion,
+			HasAvatar:       q.HasAvatar,
+			Moderated:       q.Moderated,
+			CreatedFrom:     q.CreatedFrom,
+			CreatedTo:       q.CreatedTo,
+			UpdatedFrom:     q.UpdatedFrom,
+			UpdatedTo:       q.UpdatedTo,
+		},
+	}, nil
+}
@@ -0,0 +1,93 @@
+package delivery
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"net/http"
+	"strconv"
+	"userService/internal/service"
+)
+
+// GetUserHistory возвращает историю изменений профиля
+// @Summary История изменений пользователя
+// @Description Возвращает ревизии профиля от новых к старым: измененные поля со старыми и новыми значениями, автор, время и источник (http, grpc, event). Доступно владельцу и администраторам
+// @Tags users
+// @Produce json
+// @Param id path string true "ID пользователя"
+// @Param limit query int false "Размер страницы (по умолчанию 20, максимум 100)"
+// @Param cursor query string false "Курсор следующей страницы"
+// @Success 200 {object} response.UserRevisionPageResponse
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /api/v1/users/{id}/history [get]
+func (h *UserHandler) GetUserHistory(ctx *gin.Context) {
+	userUUID, ok := targetUserID(ctx)
+	if !ok {
+		return
+	}
+
+	params := service.HistoryParams{Cursor: ctx.Query("cursor")}
+	if raw := ctx.Query("limit"); raw != "" {
+		limit, err := strconv.Atoi(raw)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{
+				"status":  "error",
+				"code":    "INVALID_INPUT",
+				"message": "Invalid query parameters",
+				"details": service.ErrInvalidLimit.Error(),
+			})
+			return
+		}
+		params.Limit = limit
+	}
+
+	page, err := h.service.GetUserHistory(ctx.Request.Context(), userUUID, params)
+	if err != nil {
+		writeError(ctx, err, "Could not get user history")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, page)
+}
+
+// GetUserAtRevision возвращает профиль в состоянии на момент ревизии
+// @Summary Профиль на момент ревизии
+// @Description Восстанавливает профиль таким, каким он был сразу после указанной ревизии. Доступно владельцу и администраторам
+// @Tags users
+// @Produce json
+// @Param id path string true "ID пользователя"
+// @Param revision path string true "ID ревизии"
+// @Success 200 {object} response.UserResponse
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /api/v1/users/{id}/history/{revision} [get]
+func (h *UserHandler) GetUserAtRevision(ctx *gin.Context) {
+	userUUID, ok := targetUserID(ctx)
+	if !ok {
+		return
+	}
+
+	revisionUUID, err := uuid.Parse(ctx.Param("revision"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"status":  "error",
+			"code":    "BAD_REQUEST",
+			"message": "Could not parse revision id",
+		})
+		return
+	}
+
+	user, err := h.service.GetUserAtRevision(ctx.Request.Context(), userUUID, revisionUUID)
+	if err != nil {
+		writeError(ctx, err, "Could not get user revision")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, user)
+}
@@ -1,11 +1,34 @@
 package events
 
-import "github.com/google/uuid"
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
 
 const (
-	UserCreated = "UserCreated"
-	UserUpdated = "UserUpdated"
-	UserDeleted = "UserDeleted"
+	UserCreated  = "UserCreated"
+	UserUpdated  = "UserUpdated"
+	UserDeleted  = "UserDeleted"
+	UserRestored = "UserRestored"
+	UserPurged   = "UserPurged"
+
+	ProfileCompleted = "ProfileCompleted"
+
+	UserBanned   = "UserBanned"
+	UserUnbanned = "UserUnbanned"
+
+	UserRolesChanged = "UserRolesChanged"
+
+	UserHandleChanged = "UserHandleChanged"
+
+	UserPrivacyChanged = "UserPrivacyChanged"
+
+	UserFollowed   = "UserFollowed"
+	UserUnfollowed = "UserUnfollowed"
+
+	UserBlocked   = "UserBlocked"
+	UserUnblocked = "UserUnblocked"
 )
 
 type UserCreatedPayload struct {
@@ -24,3 +47,76 @@ type UserDeletedPayload struct {
 	UserID   uuid.UUID `json:"user_id"`
 	ImageURL string    `json:"image_url"`
 }
+
+type UserRestoredPayload struct {
+	UserID uuid.UUID `json:"user_id"`
+}
+
+// UserPurgedPayload is the last event for a user; the profile no longer exists afterwards.
+type UserPurgedPayload struct {
+	UserID   uuid.UUID `json:"user_id"`
+	ImageURL string    `json:"image_url"`
+}
+
+// ProfileCompletedPayload is published once, when a profile first meets the completion policy.
+type ProfileCompletedPayload struct {
+	UserID      uuid.UUID `json:"user_id"`
+	CompletedAt time.Time `json:"completed_at"`
+}
+
+// UserBannedPayload announces a ban or, with Status "suspended", a suspension.
+type UserBannedPayload struct {
+	UserID      uuid.UUID  `json:"user_id"`
+	Status      string     `json:"status"`
+	Reason      string     `json:"reason"`
+	ModeratorID uuid.UUID  `json:"moderator_id"`
+	ExpiresAt   *time.Time `json:"expires_at,omitempty"`
+}
+
+// UserUnbannedPayload announces a lifted restriction. Expired restrictions
+// are lifted by the service itself and carry no moderator.
+type UserUnbannedPayload struct {
+	UserID      uuid.UUID  `json:"user_id"`
+	Reason      string     `json:"reason"`
+	ModeratorID *uuid.UUID `json:"moderator_id,omitempty"`
+	Expired     bool       `json:"expired"`
+}
+
+// UserRolesChangedPayload carries the full set of roles after a grant or
+// revoke, along with what changed.
+type UserRolesChangedPayload struct {
+	UserID    uuid.UUID  `json:"user_id"`
+	Roles     []string   `json:"roles"`
+	Granted   []string   `json:"granted,omitempty"`
+	Revoked   []string   `json:"revoked,omitempty"`
+	ChangedBy *uuid.UUID `json:"changed_by,omitempty"`
+}
+
+// UserHandleChangedPayload announces a new handle so mentions of the old one
+// can be rewritten. OldHandle is empty when the user had no handle before.
+type UserHandleChangedPayload struct {
+	UserID    uuid.UUID `json:"user_id"`
+	Handle    string    `json:"handle"`
+	OldHandle string    `json:"old_handle,omitempty"`
+	// RedirectUntil is when the old handle stops resolving to the user
+	RedirectUntil *time.Time `json:"redirect_until,omitempty"`
+}
+
+// UserPrivacyChangedPayload carries the visibility overrides of a profile
+// after a change; fields not listed have their default visibility.
+type UserPrivacyChangedPayload struct {
+	UserID  uuid.UUID         `json:"user_id"`
+	Privacy map[string]string `json:"privacy"`
+}
+
+// FollowPayload is the edge a UserFollowed or UserUnfollowed event adds or removes.
+type FollowPayload struct {
+	FollowerID uuid.UUID `json:"follower_id"`
+	FolloweeID uuid.UUID `json:"followee_id"`
+}
+
+// BlockPayload is the block a UserBlocked or UserUnblocked event adds or removes.
+type BlockPayload struct {
+	BlockerID uuid.UUID `json:"blocker_id"`
+	BlockedID uuid.UUID `json:"blocked_id"`
+}
@@ -13,8 +13,7 @@ import (
 func SetupGRPCServer(c *bootstrap.Container) {
 	logger := logging.GetLogger()
 
-	s := c.UserService
-	h := NewUserHandler(s)
+	h := NewUserHandler(c.UserService)
 
 	go func() {
 		lis, err := net.Listen("tcp", ":"+c.Config.GrpcPort)
@@ -22,7 +21,7 @@ func SetupGRPCServer(c *bootstrap.Container) {
 			logger.Fatalf("failed to listen on :%s %v", c.Config.GrpcPort, err)
 		}
 
-		grpcServer := grpc.NewServer()
+		grpcServer := grpc.NewServer(grpc.ChainUnaryInterceptor(c.Verifier.UnaryInterceptor(), originInterceptor))
 		userpb.RegisterUserServiceServer(grpcServer, h)
 
 		logger.Infof("gRPC server started on %s", c.Config.GrpcPort)
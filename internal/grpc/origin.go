@@ -0,0 +1,21 @@
+package grpc
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+	"userService/internal/auth"
+	"userService/internal/service"
+)
+
+// originInterceptor records gRPC as the source of the writes made by a call
+// and the caller as their actor. It must be chained after auth's interceptor.
+func originInterceptor(ctx context.Context, req any, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	origin := service.Origin{Source: service.SourceGRPC}
+	if p := auth.FromContext(ctx); p != nil {
+		subject := p.Subject
+		origin.Actor = &subject
+		origin.Admin = p.IsAdmin()
+	}
+	return handler(service.WithOrigin(ctx, origin), req)
+}
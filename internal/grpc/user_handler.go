@@ -2,9 +2,15 @@ package grpc
 
 import (
 	"context"
+	"errors"
+	"github.com/Sayan80bayev/go-project/pkg/logging"
 	"github.com/google/uuid"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/types/known/timestamppb"
 	"userService/internal/service"
+	"userService/internal/transport/response"
 
 	userpb "github.com/Sayan80bayev/go-project/pkg/proto/user"
 )
@@ -20,17 +26,81 @@ func NewUserHandler(userService *service.UserService) *UserHandler {
 	return &UserHandler{userService: userService}
 }
 
-// GetUser handles gRPC request to fetch a user by ID
+// GetUser handles gRPC request to fetch a user by ID. Deleted users are NOT_FOUND.
 func (h *UserHandler) GetUser(ctx context.Context, req *userpb.GetUserRequest) (*userpb.GetUserResponse, error) {
 	userUUID, err := uuid.Parse(req.GetUserId())
 	if err != nil {
-		return nil, err
+		return nil, status.Error(codes.InvalidArgument, "invalid user id")
 	}
 	user, err := h.userService.GetUserById(ctx, userUUID)
 	if err != nil {
-		return nil, err
+		return nil, toStatusError(err)
 	}
 
+	return toProtoUser(user), nil
+}
+
+// toStatusError maps service errors to gRPC status codes. Unexpected errors
+// are logged and reported as Internal without their text.
+func toStatusError(err error) error {
+	switch {
+	case service.IsQueryError(err):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, service.ErrUserDeleted):
+		st, detailErr := status.New(codes.NotFound, err.Error()).WithDetails(&errdetails.ErrorInfo{
+			Reason: "USER_DELETED",
+			Domain: "userService",
+		})
+		if detailErr != nil {
+			return status.Error(codes.NotFound, err.Error())
+		}
+		return st.Err()
+	case errors.Is(err, service.ErrNotFound):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, service.ErrValidation):
+		return validationStatus(err)
+	case errors.Is(err, service.ErrPreconditionFailed):
+		return status.Error(codes.FailedPrecondition, err.Error())
+	case errors.Is(err, service.ErrVersionConflict):
+		return status.Error(codes.Aborted, err.Error())
+	case errors.Is(err, service.ErrRoleSync):
+		return status.Error(codes.Unavailable, err.Error())
+	case errors.Is(err, service.ErrNotModerated):
+		return status.Error(codes.FailedPrecondition, err.Error())
+	case errors.Is(err, service.ErrConflict):
+		return status.Error(codes.AlreadyExists, err.Error())
+	case errors.Is(err, service.ErrForbidden):
+		return status.Error(codes.PermissionDenied, err.Error())
+	case errors.Is(err, service.ErrDeleted):
+		return status.Error(codes.FailedPrecondition, err.Error())
+	}
+	logging.Instance.Errorf("gRPC request failed: %v", err)
+	return status.Error(codes.Internal, "internal error")
+}
+
+// validationStatus reports every rejected field as a BadRequest field violation.
+func validationStatus(err error) error {
+	var verr *service.ValidationError
+	if !errors.As(err, &verr) {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+	violations := make([]*errdetails.BadRequest_FieldViolation, 0, len(verr.Fields))
+	for _, f := range verr.Fields {
+		violations = append(violations, &errdetails.BadRequest_FieldViolation{
+			Field:       f.Field,
+			Description: f.Message,
+		})
+	}
+	st, detailErr := status.New(codes.InvalidArgument, "validation failed").
+		WithDetails(&errdetails.BadRequest{FieldViolations: violations})
+	if detailErr != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+	return st.Err()
+}
+
+// toProtoUser maps a UserResponse to its protobuf representation
+func toProtoUser(user *response.UserResponse) *userpb.GetUserResponse {
 	return &userpb.GetUserResponse{
 		Id:        user.ID.String(),
 		CreatedAt: timestamppb.New(user.CreatedAt),
@@ -60,5 +130,5 @@ func (h *UserHandler) GetUser(ctx context.Context, req *userpb.GetUserRequest) (
 		Location:        &user.Location,
 		Socials:         user.Socials,
 		NeedsCompletion: user.NeedsCompletion,
-	}, nil
+	}
 }
@@ -0,0 +1,189 @@
+// Package keycloak talks to the Keycloak admin REST API.
+package keycloak
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// AdminClient implements service.RoleDirectory with realm role mappings. It
+// authenticates as a confidential client through the client credentials grant;
+// the client's service account needs the manage-users role of realm-management.
+type AdminClient struct {
+	baseURL      string
+	realm        string
+	clientID     string
+	clientSecret string
+	http         *http.Client
+
+	mu      sync.Mutex
+	token   string
+	expires time.Time
+	// roles caches role representations by name; role ids never change
+	roles map[string]role
+}
+
+// role is the RoleRepresentation subset a role mapping needs.
+type role struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+func NewAdminClient(baseURL, realm, clientID, clientSecret string) *AdminClient {
+	return &AdminClient{
+		baseURL:      strings.TrimRight(baseURL, "/"),
+		realm:        realm,
+		clientID:     clientID,
+		clientSecret: clientSecret,
+		http:         &http.Client{Timeout: 10 * time.Second},
+		roles:        map[string]role{},
+	}
+}
+
+// AddRoles maps realm roles to the user.
+func (c *AdminClient) AddRoles(ctx context.Context, userID uuid.UUID, roles []string) error {
+	return c.mapRoles(ctx, http.MethodPost, userID, roles)
+}
+
+// RemoveRoles unmaps realm roles from the user.
+func (c *AdminClient) RemoveRoles(ctx context.Context, userID uuid.UUID, roles []string) error {
+	return c.mapRoles(ctx, http.MethodDelete, userID, roles)
+}
+
+func (c *AdminClient) mapRoles(ctx context.Context, method string, userID uuid.UUID, names []string) error {
+	reps := make([]role, 0, len(names))
+	for _, name := range names {
+		r, err := c.role(ctx, name)
+		if err != nil {
+			return err
+		}
+		reps = append(reps, r)
+	}
+
+	body, err := json.Marshal(reps)
+	if err != nil {
+		return err
+	}
+	path := fmt.Sprintf("/admin/realms/%s/users/%s/role-mappings/realm", url.PathEscape(c.realm), userID)
+	return c.do(ctx, method, path, body, nil)
+}
+
+// role looks up a realm role by name.
+func (c *AdminClient) role(ctx context.Context, name string) (role, error) {
+	c.mu.Lock()
+	r, ok := c.roles[name]
+	c.mu.Unlock()
+	if ok {
+		return r, nil
+	}
+
+	path := fmt.Sprintf("/admin/realms/%s/roles/%s", url.PathEscape(c.realm), url.PathEscape(name))
+	if err := c.do(ctx, http.MethodGet, path, nil, &r); err != nil {
+		return role{}, err
+	}
+
+	c.mu.Lock()
+	c.roles[name] = r
+	c.mu.Unlock()
+	return r, nil
+}
+
+// do sends an authenticated admin request and decodes the response into out
+// when it is not nil. A rejected token is refreshed once.
+func (c *AdminClient) do(ctx context.Context, method, path string, body []byte, out any) error {
+	for attempt := 0; ; attempt++ {
+		token, err := c.accessToken(ctx)
+		if err != nil {
+			return err
+		}
+
+		req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, bytes.NewReader(body))
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Authorization", "Bearer "+token)
+		if body != nil {
+			req.Header.Set("Content-Type", "application/json")
+		}
+
+		resp, err := c.http.Do(req)
+		if err != nil {
+			return fmt.Errorf("keycloak %s %s: %w", method, path, err)
+		}
+		if resp.StatusCode == http.StatusUnauthorized && attempt == 0 {
+			resp.Body.Close()
+			c.invalidateToken(token)
+			continue
+		}
+		return decodeResponse(resp, method, path, out)
+	}
+}
+
+func decodeResponse(resp *http.Response, method, path string, out any) error {
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("keycloak %s %s: %s: %s", method, path, resp.Status, bytes.TrimSpace(msg))
+	}
+	if out == nil {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+// accessToken returns a cached service account token, fetching a new one
+// shortly before the cached one expires.
+func (c *AdminClient) accessToken(ctx context.Context) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.token != "" && time.Now().Before(c.expires) {
+		return c.token, nil
+	}
+
+	form := url.Values{
+		"grant_type":    {"client_credentials"},
+		"client_id":     {c.clientID},
+		"client_secret": {c.clientSecret},
+	}
+	path := fmt.Sprintf("/realms/%s/protocol/openid-connect/token", url.PathEscape(c.realm))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+path, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("keycloak token: %w", err)
+	}
+	var token struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int    `json:"expires_in"`
+	}
+	if err := decodeResponse(resp, http.MethodPost, path, &token); err != nil {
+		return "", err
+	}
+
+	// Renew a little early so a token never expires in flight
+	c.token = token.AccessToken
+	c.expires = time.Now().Add(time.Duration(token.ExpiresIn)*time.Second - 10*time.Second)
+	return c.token, nil
+}
+
+func (c *AdminClient) invalidateToken(token string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.token == token {
+		c.token = ""
+	}
+}
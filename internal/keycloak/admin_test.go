@@ -0,0 +1,65 @@
+package keycloak
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"userService/tests/testutil"
+)
+
+func TestAdminClient_RoleMappings(t *testing.T) {
+	mux := http.NewServeMux()
+	kc := testutil.NewKeycloakAdmin(mux, "go-project")
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	client := NewAdminClient(server.URL+"/", "go-project", "user-service", "secret")
+	ctx := context.Background()
+	userID := uuid.New()
+
+	require.NoError(t, client.AddRoles(ctx, userID, []string{"user-admin", "moderator"}))
+	assert.Equal(t, []string{"moderator", "user-admin"}, kc.Roles(userID.String()))
+
+	// Adding a mapped role again is not an error
+	require.NoError(t, client.AddRoles(ctx, userID, []string{"moderator"}))
+
+	require.NoError(t, client.RemoveRoles(ctx, userID, []string{"moderator"}))
+	assert.Equal(t, []string{"user-admin"}, kc.Roles(userID.String()))
+
+	kc.SetFail(true)
+	err := client.RemoveRoles(ctx, userID, []string{"user-admin"})
+	assert.ErrorContains(t, err, "503")
+	assert.Equal(t, []string{"user-admin"}, kc.Roles(userID.String()))
+}
+
+func TestAdminClient_RefreshesRejectedToken(t *testing.T) {
+	var tokens int
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /realms/go-project/protocol/openid-connect/token", func(w http.ResponseWriter, r *http.Request) {
+		tokens++
+		_, _ = fmt.Fprintf(w, `{"access_token":"token-%d","expires_in":300}`, tokens)
+	})
+	mux.HandleFunc("GET /admin/realms/go-project/roles/{name}", func(w http.ResponseWriter, r *http.Request) {
+		// The first token was revoked before it expired
+		if r.Header.Get("Authorization") != "Bearer token-2" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		_, _ = fmt.Fprintf(w, `{"id":"1","name":%q}`, r.PathValue("name"))
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	client := NewAdminClient(server.URL, "go-project", "user-service", "secret")
+	r, err := client.role(context.Background(), "moderator")
+
+	require.NoError(t, err)
+	assert.Equal(t, "moderator", r.Name)
+	assert.Equal(t, 2, tokens)
+}
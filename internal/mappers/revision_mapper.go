@@ -0,0 +1,33 @@
+package mappers
+
+import (
+	"github.com/Sayan80bayev/go-project/pkg/mapper"
+	"userService/internal/model"
+	"userService/internal/transport/response"
+)
+
+type RevisionMapper struct {
+	mapper.MapFunc[model.UserRevision, response.UserRevisionResponse]
+}
+
+func NewRevisionMapper() *RevisionMapper {
+	return &RevisionMapper{MapFunc: RevisionToRevisionResponse}
+}
+
+// RevisionToRevisionResponse maps a UserRevision to UserRevisionResponse
+var RevisionToRevisionResponse = mapper.MapFunc[model.UserRevision, response.UserRevisionResponse](func(r model.UserRevision) response.UserRevisionResponse {
+	changes := make([]response.FieldChangeResponse, 0, len(r.Changes))
+	for _, c := range r.Changes {
+		changes = append(changes, response.FieldChangeResponse{Field: c.Field, Old: c.Old, New: c.New})
+	}
+	return response.UserRevisionResponse{
+		ID:        r.ID,
+		UserID:    r.UserID,
+		Version:   r.Version,
+		Action:    r.Action,
+		Changes:   changes,
+		Actor:     r.Actor,
+		Source:    r.Source,
+		CreatedAt: r.CreatedAt,
+	}
+})
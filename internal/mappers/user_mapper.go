@@ -24,12 +24,34 @@ var UserToUserResponse = mapper.MapFunc[model.User, response.UserResponse](func(
 		DateOfBirth:     u.DateOfBirth,
 		AvatarURL:       u.AvatarURL,
 		Email:           u.Email,
+		Handle:          u.Handle,
 		Socials:         u.Socials,
 		Gender:          u.Gender,
 		Location:        u.Location,
 		CreatedAt:       u.CreatedAt,
 		UpdatedAt:       u.UpdatedAt,
 		DeletedAt:       u.DeletedAt,
+		Version:         u.Version,
 		NeedsCompletion: u.NeedsCompletion,
+		CompletedAt:     u.CompletedAt,
+		AccountStatus:   u.Status(),
+		Moderation:      moderationToResponse(u.Moderation),
+		Roles:           u.Roles,
+		Privacy:         u.EffectivePrivacy(),
+		FollowersCount:  u.FollowersCount,
+		FollowingCount:  u.FollowingCount,
 	}
 })
+
+func moderationToResponse(m *model.Moderation) *response.ModerationResponse {
+	if m == nil {
+		return nil
+	}
+	return &response.ModerationResponse{
+		Status:      m.Status,
+		Reason:      m.Reason,
+		ModeratorID: m.ModeratorID,
+		Since:       m.Since,
+		ExpiresAt:   m.ExpiresAt,
+	}
+}
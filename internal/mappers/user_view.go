@@ -0,0 +1,67 @@
+package mappers
+
+import (
+	"userService/internal/model"
+	"userService/internal/transport/response"
+)
+
+// Audience is who a profile is rendered for.
+type Audience int
+
+const (
+	AudienceAnonymous Audience = iota
+	AudienceAuthenticated
+	AudienceOwner
+	AudienceAdmin
+)
+
+// sees reports whether a can read a field of the given visibility.
+func (a Audience) sees(visibility string) bool {
+	switch visibility {
+	case model.VisibilityPublic:
+		return true
+	case model.VisibilityAuthenticated:
+		return a >= AudienceAuthenticated
+	}
+	return a >= AudienceOwner
+}
+
+// UserView narrows ur, a full response as produced by UserToUserResponse or
+// read back from the cache, to what a may see. Owners and admins get ur as is;
+// others never see roles, moderation details, privacy settings or completion
+// state, which would tell which hidden fields are set.
+func UserView(ur response.UserResponse, a Audience) response.UserResponse {
+	if a >= AudienceOwner {
+		return ur
+	}
+
+	visible := func(field string) bool {
+		if v, ok := ur.Privacy[field]; ok {
+			return a.sees(v)
+		}
+		return a.sees(model.DefaultVisibility[field])
+	}
+	if !visible("email") {
+		ur.Email = ""
+	}
+	if !visible("date_of_birth") {
+		ur.DateOfBirth = nil
+	}
+	if !visible("gender") {
+		ur.Gender = ""
+	}
+	if !visible("location") {
+		ur.Location = ""
+	}
+	if !visible("socials") {
+		ur.Socials = nil
+	}
+	ur.Privacy = nil
+	ur.Roles = nil
+	ur.Moderation = nil
+	ur.NeedsCompletion = false
+	ur.Completeness = 0
+	ur.MissingFields = nil
+	ur.CompletedAt = nil
+	return ur
+}
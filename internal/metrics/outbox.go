@@ -0,0 +1,28 @@
+// Package metrics publishes service metrics through expvar at /debug/vars,
+// which only admins may read.
+package metrics
+
+import "expvar"
+
+// Outbox metrics, published under the "outbox" key.
+var (
+	// OutboxPending is the number of messages waiting to be relayed
+	OutboxPending = new(expvar.Int)
+	// OutboxOldestPendingSeconds is the age of the oldest waiting message
+	OutboxOldestPendingSeconds = new(expvar.Float)
+	// OutboxDead is the number of messages that ran out of attempts
+	OutboxDead = new(expvar.Int)
+	// OutboxPublished counts messages relayed since start
+	OutboxPublished = new(expvar.Int)
+	// OutboxFailedAttempts counts failed publish attempts since start
+	OutboxFailedAttempts = new(expvar.Int)
+)
+
+func init() {
+	outbox := expvar.NewMap("outbox")
+	outbox.Set("pending", OutboxPending)
+	outbox.Set("oldest_pending_seconds", OutboxOldestPendingSeconds)
+	outbox.Set("dead", OutboxDead)
+	outbox.Set("published_total", OutboxPublished)
+	outbox.Set("failed_attempts_total", OutboxFailedAttempts)
+}
@@ -0,0 +1,216 @@
+package migrations
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/Sayan80bayev/go-project/pkg/logging"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	migrationsCollection = "schema_migrations"
+	lockCollection       = "schema_migrations_lock"
+	lockID               = "lock"
+
+	// lockTTL lets a crashed run's lock be taken over instead of blocking forever
+	lockTTL = 10 * time.Minute
+)
+
+var (
+	ErrLocked       = errors.New("migrations are locked by another process")
+	ErrIrreversible = errors.New("migration cannot be rolled back")
+)
+
+// Migration is a single versioned change to the database.
+type Migration struct {
+	Version     int
+	Description string
+	Up          func(ctx context.Context, db *mongo.Database) error
+	// Down reverts Up. A nil Down marks the migration as irreversible.
+	Down func(ctx context.Context, db *mongo.Database) error
+}
+
+// Record is the document stored in schema_migrations for every applied version.
+type Record struct {
+	Version     int       `bson:"_id"`
+	Description string    `bson:"description"`
+	AppliedAt   time.Time `bson:"applied_at"`
+}
+
+// Status describes a known migration and whether it has been applied.
+type Status struct {
+	Migration
+	Applied   bool
+	AppliedAt time.Time
+}
+
+// Migrator applies and rolls back migrations, recording versions in schema_migrations.
+type Migrator struct {
+	db         *mongo.Database
+	records    *mongo.Collection
+	locks      *mongo.Collection
+	migrations []Migration
+	dryRun     bool
+}
+
+// NewMigrator creates a migrator for the given migrations. In dry-run mode
+// pending steps are only reported, never executed or recorded.
+func NewMigrator(db *mongo.Database, migrations []Migration, dryRun bool) *Migrator {
+	sorted := append([]Migration(nil), migrations...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Version < sorted[j].Version })
+
+	return &Migrator{
+		db:         db,
+		records:    db.Collection(migrationsCollection),
+		locks:      db.Collection(lockCollection),
+		migrations: sorted,
+		dryRun:     dryRun,
+	}
+}
+
+// Status lists every known migration with its applied state.
+func (m *Migrator) Status(ctx context.Context) ([]Status, error) {
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]Status, 0, len(m.migrations))
+	for _, mig := range m.migrations {
+		rec, ok := applied[mig.Version]
+		statuses = append(statuses, Status{Migration: mig, Applied: ok, AppliedAt: rec.AppliedAt})
+	}
+	return statuses, nil
+}
+
+// Up applies every pending migration in version order and returns the ones it ran.
+func (m *Migrator) Up(ctx context.Context) ([]Migration, error) {
+	logger := logging.GetLogger()
+
+	release, err := m.lock(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var ran []Migration
+	for _, mig := range m.migrations {
+		if _, ok := applied[mig.Version]; ok {
+			continue
+		}
+		if m.dryRun {
+			logger.Infof("[dry-run] would apply migration %d: %s", mig.Version, mig.Description)
+			ran = append(ran, mig)
+			continue
+		}
+
+		logger.Infof("Applying migration %d: %s", mig.Version, mig.Description)
+		if err := mig.Up(ctx, m.db); err != nil {
+			return ran, fmt.Errorf("migration %d failed: %w", mig.Version, err)
+		}
+		if _, err := m.records.InsertOne(ctx, Record{
+			Version:     mig.Version,
+			Description: mig.Description,
+			AppliedAt:   time.Now().UTC(),
+		}); err != nil {
+			return ran, fmt.Errorf("failed to record migration %d: %w", mig.Version, err)
+		}
+		ran = append(ran, mig)
+	}
+	return ran, nil
+}
+
+// Down rolls back the last steps applied migrations, newest first.
+func (m *Migrator) Down(ctx context.Context, steps int) ([]Migration, error) {
+	logger := logging.GetLogger()
+
+	release, err := m.lock(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var reverted []Migration
+	for i := len(m.migrations) - 1; i >= 0 && len(reverted) < steps; i-- {
+		mig := m.migrations[i]
+		if _, ok := applied[mig.Version]; !ok {
+			continue
+		}
+		if mig.Down == nil {
+			return reverted, fmt.Errorf("%w: %d %s", ErrIrreversible, mig.Version, mig.Description)
+		}
+		if m.dryRun {
+			logger.Infof("[dry-run] would roll back migration %d: %s", mig.Version, mig.Description)
+			reverted = append(reverted, mig)
+			continue
+		}
+
+		logger.Infof("Rolling back migration %d: %s", mig.Version, mig.Description)
+		if err := mig.Down(ctx, m.db); err != nil {
+			return reverted, fmt.Errorf("rollback of migration %d failed: %w", mig.Version, err)
+		}
+		if _, err := m.records.DeleteOne(ctx, bson.M{"_id": mig.Version}); err != nil {
+			return reverted, fmt.Errorf("failed to unrecord migration %d: %w", mig.Version, err)
+		}
+		reverted = append(reverted, mig)
+	}
+	return reverted, nil
+}
+
+func (m *Migrator) applied(ctx context.Context) (map[int]Record, error) {
+	cur, err := m.records.Find(ctx, bson.M{})
+	if err != nil {
+		return nil, err
+	}
+
+	var records []Record
+	if err := cur.All(ctx, &records); err != nil {
+		return nil, err
+	}
+
+	applied := make(map[int]Record, len(records))
+	for _, r := range records {
+		applied[r.Version] = r
+	}
+	return applied, nil
+}
+
+// lock takes the migration lock so concurrent replicas do not run the same steps.
+// The upsert only matches a missing or stale lock; a fresh one makes the insert
+// collide on _id.
+func (m *Migrator) lock(ctx context.Context) (func(), error) {
+	now := time.Now().UTC()
+	_, err := m.locks.UpdateOne(ctx,
+		bson.M{"_id": lockID, "locked_at": bson.M{"$lt": now.Add(-lockTTL)}},
+		bson.M{"$set": bson.M{"locked_at": now}},
+		options.Update().SetUpsert(true),
+	)
+	if mongo.IsDuplicateKeyError(err) {
+		return nil, ErrLocked
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return func() {
+		if _, err := m.locks.DeleteOne(context.WithoutCancel(ctx), bson.M{"_id": lockID}); err != nil {
+			logging.GetLogger().Errorf("failed to release migration lock: %v", err)
+		}
+	}, nil
+}
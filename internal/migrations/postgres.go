@@ -0,0 +1,262 @@
+package migrations
+
+import (
+	"context"
+	"embed"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Sayan80bayev/go-project/pkg/logging"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// postgresFiles holds NNNN_description.up.sql / .down.sql pairs.
+//
+//go:embed postgres/*.sql
+var postgresFiles embed.FS
+
+// postgresLockKey identifies the advisory lock held while migrating.
+const postgresLockKey = 727368
+
+// SQLMigration is a versioned change to the Postgres schema.
+type SQLMigration struct {
+	Version     int
+	Description string
+	Up          string
+	// Down reverts Up. An empty Down marks the migration as irreversible.
+	Down string
+}
+
+// SQLStatus describes a known SQL migration and whether it has been applied.
+type SQLStatus struct {
+	SQLMigration
+	Applied   bool
+	AppliedAt time.Time
+}
+
+// PostgresMigrations loads the embedded SQL migrations in version order.
+func PostgresMigrations() ([]SQLMigration, error) {
+	entries, err := fs.ReadDir(postgresFiles, "postgres")
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := map[int]*SQLMigration{}
+	for _, e := range entries {
+		name := e.Name()
+		base, direction, ok := strings.Cut(strings.TrimSuffix(name, ".sql"), ".")
+		versionStr, desc, ok2 := strings.Cut(base, "_")
+		version, err := strconv.Atoi(versionStr)
+		if !ok || !ok2 || err != nil || (direction != "up" && direction != "down") {
+			return nil, fmt.Errorf("unexpected migration file name %q", name)
+		}
+
+		body, err := postgresFiles.ReadFile(path.Join("postgres", name))
+		if err != nil {
+			return nil, err
+		}
+
+		m, exists := byVersion[version]
+		if !exists {
+			m = &SQLMigration{Version: version, Description: strings.ReplaceAll(desc, "_", " ")}
+			byVersion[version] = m
+		}
+		if direction == "up" {
+			m.Up = string(body)
+		} else {
+			m.Down = string(body)
+		}
+	}
+
+	out := make([]SQLMigration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if m.Up == "" {
+			return nil, fmt.Errorf("migration %d has no up script", m.Version)
+		}
+		out = append(out, *m)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Version < out[j].Version })
+	return out, nil
+}
+
+// PostgresMigrator is the Postgres counterpart of Migrator. Versions are
+// recorded in schema_migrations and every step runs in its own transaction.
+type PostgresMigrator struct {
+	pool       *pgxpool.Pool
+	migrations []SQLMigration
+	dryRun     bool
+}
+
+func NewPostgresMigrator(pool *pgxpool.Pool, migrations []SQLMigration, dryRun bool) *PostgresMigrator {
+	return &PostgresMigrator{pool: pool, migrations: migrations, dryRun: dryRun}
+}
+
+// Status lists every known migration with its applied state.
+func (m *PostgresMigrator) Status(ctx context.Context) ([]SQLStatus, error) {
+	conn, err := m.pool.Acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Release()
+
+	applied, err := m.applied(ctx, conn.Conn())
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]SQLStatus, 0, len(m.migrations))
+	for _, mig := range m.migrations {
+		at, ok := applied[mig.Version]
+		statuses = append(statuses, SQLStatus{SQLMigration: mig, Applied: ok, AppliedAt: at})
+	}
+	return statuses, nil
+}
+
+// Up applies every pending migration in version order and returns the ones it ran.
+func (m *PostgresMigrator) Up(ctx context.Context) ([]SQLMigration, error) {
+	logger := logging.GetLogger()
+
+	conn, release, err := m.lock(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	applied, err := m.applied(ctx, conn)
+	if err != nil {
+		return nil, err
+	}
+
+	var ran []SQLMigration
+	for _, mig := range m.migrations {
+		if _, ok := applied[mig.Version]; ok {
+			continue
+		}
+		if m.dryRun {
+			logger.Infof("[dry-run] would apply migration %d: %s", mig.Version, mig.Description)
+			ran = append(ran, mig)
+			continue
+		}
+
+		logger.Infof("Applying migration %d: %s", mig.Version, mig.Description)
+		err := pgx.BeginFunc(ctx, conn, func(tx pgx.Tx) error {
+			if _, err := tx.Exec(ctx, mig.Up); err != nil {
+				return err
+			}
+			_, err := tx.Exec(ctx,
+				`INSERT INTO schema_migrations (version, description, applied_at) VALUES ($1, $2, $3)`,
+				mig.Version, mig.Description, time.Now().UTC())
+			return err
+		})
+		if err != nil {
+			return ran, fmt.Errorf("migration %d failed: %w", mig.Version, err)
+		}
+		ran = append(ran, mig)
+	}
+	return ran, nil
+}
+
+// Down rolls back the last steps applied migrations, newest first.
+func (m *PostgresMigrator) Down(ctx context.Context, steps int) ([]SQLMigration, error) {
+	logger := logging.GetLogger()
+
+	conn, release, err := m.lock(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	applied, err := m.applied(ctx, conn)
+	if err != nil {
+		return nil, err
+	}
+
+	var reverted []SQLMigration
+	for i := len(m.migrations) - 1; i >= 0 && len(reverted) < steps; i-- {
+		mig := m.migrations[i]
+		if _, ok := applied[mig.Version]; !ok {
+			continue
+		}
+		if mig.Down == "" {
+			return reverted, fmt.Errorf("%w: %d %s", ErrIrreversible, mig.Version, mig.Description)
+		}
+		if m.dryRun {
+			logger.Infof("[dry-run] would roll back migration %d: %s", mig.Version, mig.Description)
+			reverted = append(reverted, mig)
+			continue
+		}
+
+		logger.Infof("Rolling back migration %d: %s", mig.Version, mig.Description)
+		err := pgx.BeginFunc(ctx, conn, func(tx pgx.Tx) error {
+			if _, err := tx.Exec(ctx, mig.Down); err != nil {
+				return err
+			}
+			_, err := tx.Exec(ctx, `DELETE FROM schema_migrations WHERE version = $1`, mig.Version)
+			return err
+		})
+		if err != nil {
+			return reverted, fmt.Errorf("rollback of migration %d failed: %w", mig.Version, err)
+		}
+		reverted = append(reverted, mig)
+	}
+	return reverted, nil
+}
+
+func (m *PostgresMigrator) applied(ctx context.Context, conn *pgx.Conn) (map[int]time.Time, error) {
+	if _, err := conn.Exec(ctx, `CREATE TABLE IF NOT EXISTS schema_migrations (
+		version     INTEGER PRIMARY KEY,
+		description TEXT NOT NULL,
+		applied_at  TIMESTAMPTZ NOT NULL
+	)`); err != nil {
+		return nil, err
+	}
+
+	rows, err := conn.Query(ctx, `SELECT version, applied_at FROM schema_migrations`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	applied := map[int]time.Time{}
+	for rows.Next() {
+		var version int
+		var at time.Time
+		if err := rows.Scan(&version, &at); err != nil {
+			return nil, err
+		}
+		applied[version] = at
+	}
+	return applied, rows.Err()
+}
+
+// lock takes a session advisory lock so concurrent replicas do not run the
+// same steps. The lock dies with the connection, so a crashed run cannot leave it behind.
+func (m *PostgresMigrator) lock(ctx context.Context) (*pgx.Conn, func(), error) {
+	conn, err := m.pool.Acquire(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var locked bool
+	if err := conn.QueryRow(ctx, `SELECT pg_try_advisory_lock($1)`, postgresLockKey).Scan(&locked); err != nil {
+		conn.Release()
+		return nil, nil, err
+	}
+	if !locked {
+		conn.Release()
+		return nil, nil, ErrLocked
+	}
+
+	return conn.Conn(), func() {
+		if _, err := conn.Exec(context.WithoutCancel(ctx), `SELECT pg_advisory_unlock($1)`, postgresLockKey); err != nil {
+			logging.GetLogger().Errorf("failed to release migration lock: %v", err)
+		}
+		conn.Release()
+	}, nil
+}
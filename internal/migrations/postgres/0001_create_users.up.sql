@@ -0,0 +1,34 @@
+CREATE TABLE users (
+    id               UUID PRIMARY KEY,
+    created_at       TIMESTAMPTZ NOT NULL,
+    updated_at       TIMESTAMPTZ NOT NULL,
+    deleted_at       TIMESTAMPTZ,
+    version          BIGINT      NOT NULL DEFAULT 1,
+
+    email            TEXT        NOT NULL DEFAULT '',
+    firstname        TEXT        NOT NULL DEFAULT '',
+    lastname         TEXT        NOT NULL DEFAULT '',
+    about            TEXT        NOT NULL DEFAULT '',
+    date_of_birth    TIMESTAMPTZ,
+    avatar_url       TEXT        NOT NULL DEFAULT '',
+    gender           TEXT        NOT NULL DEFAULT '' CHECK (gender IN ('', 'male', 'female', 'other')),
+    location         TEXT        NOT NULL DEFAULT '',
+    socials          TEXT[]      NOT NULL DEFAULT '{}',
+    needs_completion BOOLEAN     NOT NULL DEFAULT FALSE,
+
+    -- Same weights as the Mongo users_text_search index
+    search           TSVECTOR GENERATED ALWAYS AS (
+        setweight(to_tsvector('simple', firstname), 'A') ||
+        setweight(to_tsvector('simple', lastname), 'A') ||
+        setweight(to_tsvector('simple', location), 'B') ||
+        setweight(to_tsvector('simple', about), 'D')
+    ) STORED
+);
+
+-- Emails are stored normalized; profiles without one do not collide
+CREATE UNIQUE INDEX users_email_unique ON users (email) WHERE email <> '';
+
+CREATE INDEX users_live_created ON users (created_at, id) WHERE deleted_at IS NULL;
+CREATE INDEX users_live_updated ON users (updated_at, id) WHERE deleted_at IS NULL;
+CREATE INDEX users_deleted_at ON users (deleted_at) WHERE deleted_at IS NOT NULL;
+CREATE INDEX users_search ON users USING GIN (search);
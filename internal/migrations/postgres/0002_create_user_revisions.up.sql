@@ -0,0 +1,12 @@
+CREATE TABLE user_revisions (
+    id         UUID PRIMARY KEY,
+    user_id    UUID        NOT NULL,
+    version    BIGINT      NOT NULL,
+    action     TEXT        NOT NULL,
+    changes    JSONB       NOT NULL DEFAULT '[]',
+    actor      UUID,
+    source     TEXT        NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX user_revisions_user_created ON user_revisions (user_id, created_at DESC, id DESC);
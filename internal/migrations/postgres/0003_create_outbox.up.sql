@@ -0,0 +1,14 @@
+CREATE TABLE outbox (
+    id              UUID PRIMARY KEY,
+    event_type      TEXT        NOT NULL,
+    payload         JSONB       NOT NULL,
+    status          TEXT        NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'sent', 'dead')),
+    attempts        INTEGER     NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMPTZ NOT NULL,
+    last_error      TEXT        NOT NULL DEFAULT '',
+    created_at      TIMESTAMPTZ NOT NULL,
+    sent_at         TIMESTAMPTZ
+);
+
+CREATE INDEX outbox_pending ON outbox (id) WHERE status = 'pending';
+CREATE INDEX outbox_sent ON outbox (sent_at) WHERE status = 'sent';
@@ -0,0 +1,2 @@
+DROP INDEX users_moderated;
+ALTER TABLE users DROP COLUMN moderation;
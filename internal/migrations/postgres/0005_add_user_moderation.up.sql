@@ -0,0 +1,4 @@
+ALTER TABLE users ADD COLUMN moderation JSONB;
+
+-- Few accounts are ever restricted; the expiry job scans only those
+CREATE INDEX users_moderated ON users (id) WHERE moderation IS NOT NULL;
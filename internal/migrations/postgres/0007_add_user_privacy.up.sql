@@ -0,0 +1,2 @@
+-- Visibility overrides by field; NULL keeps every default
+ALTER TABLE users ADD COLUMN privacy JSONB;
@@ -0,0 +1,5 @@
+DROP INDEX users_former_handles;
+DROP INDEX users_handle_unique;
+ALTER TABLE users DROP COLUMN former_handles;
+ALTER TABLE users DROP COLUMN handle_changed_at;
+ALTER TABLE users DROP COLUMN handle;
@@ -0,0 +1,8 @@
+ALTER TABLE users ADD COLUMN handle TEXT NOT NULL DEFAULT '';
+ALTER TABLE users ADD COLUMN handle_changed_at TIMESTAMPTZ;
+-- Handles given up in renames with the time their redirect ends
+ALTER TABLE users ADD COLUMN former_handles JSONB;
+
+-- Handles are stored lower case; profiles without one do not collide
+CREATE UNIQUE INDEX users_handle_unique ON users (handle) WHERE handle <> '';
+CREATE INDEX users_former_handles ON users USING GIN (former_handles jsonb_path_ops);
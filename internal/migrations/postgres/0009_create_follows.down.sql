@@ -0,0 +1,3 @@
+ALTER TABLE users DROP COLUMN following_count;
+ALTER TABLE users DROP COLUMN followers_count;
+DROP TABLE follows;
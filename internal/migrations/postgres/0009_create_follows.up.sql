@@ -0,0 +1,15 @@
+CREATE TABLE follows (
+    follower_id UUID        NOT NULL,
+    followee_id UUID        NOT NULL,
+    created_at  TIMESTAMPTZ NOT NULL,
+    PRIMARY KEY (follower_id, followee_id),
+    CHECK (follower_id <> followee_id)
+);
+
+-- Followers and following lists, newest first
+CREATE INDEX follows_followee_created ON follows (followee_id, created_at DESC, follower_id DESC);
+CREATE INDEX follows_follower_created ON follows (follower_id, created_at DESC, followee_id DESC);
+
+-- Denormalized counts, kept in step with follows in the same transaction
+ALTER TABLE users ADD COLUMN followers_count BIGINT NOT NULL DEFAULT 0;
+ALTER TABLE users ADD COLUMN following_count BIGINT NOT NULL DEFAULT 0;
@@ -0,0 +1,10 @@
+CREATE TABLE blocks (
+    blocker_id UUID        NOT NULL,
+    blocked_id UUID        NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL,
+    PRIMARY KEY (blocker_id, blocked_id),
+    CHECK (blocker_id <> blocked_id)
+);
+
+-- Everyone that blocked a user, read when the blocker set is not cached
+CREATE INDEX blocks_blocked ON blocks (blocked_id, blocker_id);
@@ -0,0 +1,3 @@
+-- Deleted profiles no longer hold on to their email
+DROP INDEX users_email_unique;
+CREATE UNIQUE INDEX users_email_unique ON users (email) WHERE email <> '' AND deleted_at IS NULL;
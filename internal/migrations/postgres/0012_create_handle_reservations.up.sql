@@ -0,0 +1,20 @@
+-- Every handle in use, current (until NULL) or redirecting after a rename
+CREATE TABLE handle_reservations (
+    handle  TEXT PRIMARY KEY,
+    user_id UUID NOT NULL,
+    until   TIMESTAMPTZ
+);
+
+-- Reservations of a purged user, dropped with it
+CREATE INDEX handle_reservations_user ON handle_reservations (user_id);
+
+INSERT INTO handle_reservations (handle, user_id)
+SELECT handle, id FROM users WHERE handle <> '';
+
+INSERT INTO handle_reservations (handle, user_id, until)
+SELECT f->>'handle', u.id, (f->>'until')::timestamptz
+FROM users u, jsonb_array_elements(
+    CASE WHEN jsonb_typeof(u.former_handles) = 'array' THEN u.former_handles ELSE '[]' END
+) f
+WHERE (f->>'until')::timestamptz > now()
+ON CONFLICT (handle) DO NOTHING;
@@ -0,0 +1,88 @@
+package migrations
+
+import (
+	"context"
+	"errors"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// CreateIndexes returns a step that creates the given indexes on collection.
+func CreateIndexes(collection string, models ...mongo.IndexModel) func(ctx context.Context, db *mongo.Database) error {
+	return func(ctx context.Context, db *mongo.Database) error {
+		_, err := db.Collection(collection).Indexes().CreateMany(ctx, models)
+		return err
+	}
+}
+
+// DropIndexes returns a step that drops the named indexes from collection.
+func DropIndexes(collection string, names ...string) func(ctx context.Context, db *mongo.Database) error {
+	return func(ctx context.Context, db *mongo.Database) error {
+		for _, name := range names {
+			if _, err := db.Collection(collection).Indexes().DropOne(ctx, name); err != nil && !isIndexNotFound(err) {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+// RenameField returns a step that renames a field on every document that has it.
+func RenameField(collection, from, to string) func(ctx context.Context, db *mongo.Database) error {
+	return func(ctx context.Context, db *mongo.Database) error {
+		_, err := db.Collection(collection).UpdateMany(ctx,
+			bson.M{from: bson.M{"$exists": true}},
+			bson.M{"$rename": bson.M{from: to}},
+		)
+		return err
+	}
+}
+
+// Backfill returns a step that applies update to every document matching filter.
+func Backfill(collection string, filter, update interface{}) func(ctx context.Context, db *mongo.Database) error {
+	return func(ctx context.Context, db *mongo.Database) error {
+		_, err := db.Collection(collection).UpdateMany(ctx, filter, update)
+		return err
+	}
+}
+
+// DropCollection returns a step that drops collection with its indexes.
+func DropCollection(collection string) func(ctx context.Context, db *mongo.Database) error {
+	return func(ctx context.Context, db *mongo.Database) error {
+		return db.Collection(collection).Drop(ctx)
+	}
+}
+
+// Aggregate returns a step that runs pipeline on collection, for pipelines
+// that end in $merge or $out.
+func Aggregate(collection string, pipeline mongo.Pipeline) func(ctx context.Context, db *mongo.Database) error {
+	return func(ctx context.Context, db *mongo.Database) error {
+		cur, err := db.Collection(collection).Aggregate(ctx, pipeline)
+		if err != nil {
+			return err
+		}
+		return cur.Close(ctx)
+	}
+}
+
+// Sequence returns a step that runs steps in order, stopping at the first error.
+func Sequence(steps ...func(ctx context.Context, db *mongo.Database) error) func(ctx context.Context, db *mongo.Database) error {
+	return func(ctx context.Context, db *mongo.Database) error {
+		for _, step := range steps {
+			if err := step(ctx, db); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+// Noop is a Down step for migrations whose Up leaves nothing to undo.
+func Noop(context.Context, *mongo.Database) error { return nil }
+
+func isIndexNotFound(err error) bool {
+	var cmdErr mongo.CommandError
+	// 27 is IndexNotFound
+	return errors.As(err, &cmdErr) && cmdErr.Code == 27
+}
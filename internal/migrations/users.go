@@ -0,0 +1,247 @@
+package migrations
+
+import (
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	usersCollection     = "users"
+	revisionsCollection = "user_revisions"
+	outboxCollection    = "outbox"
+	followsCollection   = "follows"
+	blocksCollection    = "blocks"
+	handlesCollection   = "handle_reservations"
+)
+
+// UsersEmailIndex is the unique index on normalized emails of live profiles;
+// duplicate key errors naming it mean the email is taken.
+const UsersEmailIndex = "users_email_unique"
+
+// UsersHandleIndex is the unique index on handles; duplicate key errors
+// naming it mean the handle is taken.
+const UsersHandleIndex = "users_handle_unique"
+
+// All lists the migrations of this service. Append new steps with the next
+// version number; never edit or renumber a migration that has shipped.
+var All = []Migration{
+	{
+		Version:     1,
+		Description: "index users for soft-delete filtering and paginated listing",
+		Up: CreateIndexes(usersCollection,
+			mongo.IndexModel{
+				Keys:    bson.D{{Key: "deleted_at", Value: 1}, {Key: "created_at", Value: 1}, {Key: "_id", Value: 1}},
+				Options: options.Index().SetName("users_deleted_created"),
+			},
+			mongo.IndexModel{
+				Keys:    bson.D{{Key: "deleted_at", Value: 1}, {Key: "updated_at", Value: 1}, {Key: "_id", Value: 1}},
+				Options: options.Index().SetName("users_deleted_updated"),
+			},
+		),
+		Down: DropIndexes(usersCollection, "users_deleted_created", "users_deleted_updated"),
+	},
+	{
+		Version:     2,
+		Description: "weighted text index for user search",
+		Up: CreateIndexes(usersCollection, mongo.IndexModel{
+			Keys: bson.D{
+				{Key: "firstname", Value: "text"},
+				{Key: "lastname", Value: "text"},
+				{Key: "about", Value: "text"},
+				{Key: "location", Value: "text"},
+			},
+			Options: options.Index().
+				SetName("users_text_search").
+				SetDefaultLanguage("none").
+				SetWeights(bson.D{
+					{Key: "firstname", Value: 10},
+					{Key: "lastname", Value: 10},
+					{Key: "location", Value: 3},
+					{Key: "about", Value: 1},
+				}),
+		}),
+		Down: DropIndexes(usersCollection, "users_text_search"),
+	},
+	{
+		Version:     3,
+		Description: "backfill needs_completion on profiles created without it",
+		Up: Backfill(usersCollection,
+			bson.M{"needs_completion": bson.M{"$exists": false}},
+			bson.M{"$set": bson.M{"needs_completion": false}},
+		),
+		Down: Noop,
+	},
+	{
+		Version:     4,
+		Description: "backfill version counter for optimistic concurrency",
+		Up: Backfill(usersCollection,
+			bson.M{"version": bson.M{"$exists": false}},
+			bson.M{"$set": bson.M{"version": 1}},
+		),
+		Down: Noop,
+	},
+	{
+		Version:     5,
+		Description: "normalize emails to trimmed lower case",
+		Up: Backfill(usersCollection,
+			bson.M{"email": bson.M{"$type": "string"}},
+			mongo.Pipeline{{{Key: "$set", Value: bson.M{
+				"email": bson.M{"$toLower": bson.M{"$trim": bson.M{"input": "$email"}}},
+			}}}},
+		),
+		Down: Noop,
+	},
+	{
+		// Fails while duplicate emails exist; those have to be resolved by hand first
+		Version:     6,
+		Description: "unique index on email",
+		Up:          CreateIndexes(usersCollection, emailIndexV6),
+		Down:        DropIndexes(usersCollection, UsersEmailIndex),
+	},
+	{
+		Version:     7,
+		Description: "index user revisions for paginated history",
+		Up: CreateIndexes(revisionsCollection, mongo.IndexModel{
+			Keys:    bson.D{{Key: "user_id", Value: 1}, {Key: "created_at", Value: -1}, {Key: "_id", Value: -1}},
+			Options: options.Index().SetName("user_revisions_user_created"),
+		}),
+		Down: DropIndexes(revisionsCollection, "user_revisions_user_created"),
+	},
+	{
+		Version:     8,
+		Description: "index the outbox by status for relaying and cleanup",
+		Up: CreateIndexes(outboxCollection,
+			mongo.IndexModel{
+				Keys:    bson.D{{Key: "status", Value: 1}, {Key: "_id", Value: 1}},
+				Options: options.Index().SetName("outbox_status"),
+			},
+			mongo.IndexModel{
+				Keys:    bson.D{{Key: "status", Value: 1}, {Key: "sent_at", Value: 1}},
+				Options: options.Index().SetName("outbox_status_sent"),
+			},
+		),
+		Down: DropIndexes(outboxCollection, "outbox_status", "outbox_status_sent"),
+	},
+	{
+		Version:     9,
+		Description: "index moderation expiry for lifting suspensions",
+		Up: CreateIndexes(usersCollection, mongo.IndexModel{
+			Keys: bson.D{{Key: "moderation.expires_at", Value: 1}},
+			Options: options.Index().
+				SetName("users_moderation_expires").
+				SetPartialFilterExpression(bson.M{"moderation.expires_at": bson.M{"$exists": true}}),
+		}),
+		Down: DropIndexes(usersCollection, "users_moderation_expires"),
+	},
+	{
+		Version:     10,
+		Description: "unique index on handles and lookup of former handles",
+		Up: CreateIndexes(usersCollection,
+			mongo.IndexModel{
+				Keys: bson.D{{Key: "handle", Value: 1}},
+				Options: options.Index().
+					SetName(UsersHandleIndex).
+					SetUnique(true).
+					SetPartialFilterExpression(bson.M{"handle": bson.M{"$gt": ""}}),
+			},
+			mongo.IndexModel{
+				Keys: bson.D{{Key: "former_handles.handle", Value: 1}},
+				Options: options.Index().
+					SetName("users_former_handles").
+					SetPartialFilterExpression(bson.M{"former_handles": bson.M{"$exists": true}}),
+			},
+		),
+		Down: DropIndexes(usersCollection, UsersHandleIndex, "users_former_handles"),
+	},
+	{
+		Version:     11,
+		Description: "index the follow graph for uniqueness and both list directions",
+		Up: CreateIndexes(followsCollection,
+			mongo.IndexModel{
+				Keys:    bson.D{{Key: "follower_id", Value: 1}, {Key: "followee_id", Value: 1}},
+				Options: options.Index().SetName("follows_pair_unique").SetUnique(true),
+			},
+			mongo.IndexModel{
+				Keys:    bson.D{{Key: "followee_id", Value: 1}, {Key: "created_at", Value: -1}, {Key: "follower_id", Value: -1}},
+				Options: options.Index().SetName("follows_followee_created"),
+			},
+			mongo.IndexModel{
+				Keys:    bson.D{{Key: "follower_id", Value: 1}, {Key: "created_at", Value: -1}, {Key: "followee_id", Value: -1}},
+				Options: options.Index().SetName("follows_follower_created"),
+			},
+		),
+		Down: DropIndexes(followsCollection, "follows_pair_unique", "follows_followee_created", "follows_follower_created"),
+	},
+	{
+		Version:     12,
+		Description: "index blocks for uniqueness and lookup of everyone that blocked a user",
+		Up: CreateIndexes(blocksCollection,
+			mongo.IndexModel{
+				Keys:    bson.D{{Key: "blocker_id", Value: 1}, {Key: "blocked_id", Value: 1}},
+				Options: options.Index().SetName("blocks_pair_unique").SetUnique(true),
+			},
+			mongo.IndexModel{
+				Keys:    bson.D{{Key: "blocked_id", Value: 1}, {Key: "blocker_id", Value: 1}},
+				Options: options.Index().SetName("blocks_blocked"),
+			},
+		),
+		Down: DropIndexes(blocksCollection, "blocks_pair_unique", "blocks_blocked"),
+	},
+	{
+		// Partial indexes cannot select documents without deleted_at, so the
+		// deletion time joins the key: every live profile has none, and no two
+		// deletions share one
+		Version:     13,
+		Description: "scope email uniqueness to live profiles",
+		Up: Sequence(
+			DropIndexes(usersCollection, UsersEmailIndex),
+			CreateIndexes(usersCollection, mongo.IndexModel{
+				Keys: bson.D{{Key: "email", Value: 1}, {Key: "deleted_at", Value: 1}},
+				Options: options.Index().
+					SetName(UsersEmailIndex).
+					SetUnique(true).
+					SetPartialFilterExpression(bson.M{"email": bson.M{"$gt": ""}}),
+			}),
+		),
+		// Fails while a deleted profile shares its email with another one
+		Down: Sequence(
+			DropIndexes(usersCollection, UsersEmailIndex),
+			CreateIndexes(usersCollection, emailIndexV6),
+		),
+	},
+	{
+		// Handles are the _id of their reservation, which keeps them unique
+		Version:     14,
+		Description: "reserve current and redirecting handles",
+		Up: Sequence(
+			CreateIndexes(handlesCollection, mongo.IndexModel{
+				Keys:    bson.D{{Key: "user_id", Value: 1}},
+				Options: options.Index().SetName("handle_reservations_user"),
+			}),
+			Aggregate(usersCollection, mongo.Pipeline{
+				{{Key: "$match", Value: bson.M{"handle": bson.M{"$gt": ""}}}},
+				{{Key: "$project", Value: bson.M{"_id": "$handle", "user_id": "$_id"}}},
+				{{Key: "$merge", Value: bson.M{"into": handlesCollection, "whenMatched": "keepExisting"}}},
+			}),
+			Aggregate(usersCollection, mongo.Pipeline{
+				{{Key: "$match", Value: bson.M{"former_handles": bson.M{"$type": "array"}}}},
+				{{Key: "$unwind", Value: "$former_handles"}},
+				{{Key: "$match", Value: bson.M{"$expr": bson.M{"$gt": bson.A{"$former_handles.until", "$$NOW"}}}}},
+				{{Key: "$project", Value: bson.M{"_id": "$former_handles.handle", "user_id": "$_id", "until": "$former_handles.until"}}},
+				{{Key: "$merge", Value: bson.M{"into": handlesCollection, "whenMatched": "keepExisting"}}},
+			}),
+		),
+		Down: DropCollection(handlesCollection),
+	},
+}
+
+// emailIndexV6 is the email index as migration 6 created it.
+var emailIndexV6 = mongo.IndexModel{
+	Keys: bson.D{{Key: "email", Value: 1}},
+	Options: options.Index().
+		SetName(UsersEmailIndex).
+		SetUnique(true).
+		// Profiles created without an email do not collide
+		SetPartialFilterExpression(bson.M{"email": bson.M{"$gt": ""}}),
+}
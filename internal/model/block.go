@@ -0,0 +1,15 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Block hides BlockerID from BlockedID: the blocked user no longer finds the
+// blocker's profile anywhere.
+type Block struct {
+	BlockerID uuid.UUID `bson:"blocker_id" json:"blocker_id"`
+	BlockedID uuid.UUID `bson:"blocked_id" json:"blocked_id"`
+	CreatedAt time.Time `bson:"created_at" json:"created_at"`
+}
@@ -0,0 +1,14 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Follow is a directed edge of the follow graph: FollowerID follows FolloweeID.
+type Follow struct {
+	FollowerID uuid.UUID `bson:"follower_id" json:"follower_id"`
+	FolloweeID uuid.UUID `bson:"followee_id" json:"followee_id"`
+	CreatedAt  time.Time `bson:"created_at" json:"created_at"`
+}
@@ -0,0 +1,33 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Account statuses. Profiles without a Moderation are active.
+const (
+	StatusActive    = "active"
+	StatusSuspended = "suspended"
+	StatusBanned    = "banned"
+)
+
+// Moderation is a restriction placed on an account by a moderator. Restricted
+// profiles are hidden from everyone but admins and their owner.
+type Moderation struct {
+	Status      string    `bson:"status" json:"status"`
+	Reason      string    `bson:"reason" json:"reason"`
+	ModeratorID uuid.UUID `bson:"moderator_id" json:"moderator_id"`
+	Since       time.Time `bson:"since" json:"since"`
+	// ExpiresAt lifts the restriction automatically; suspensions always have one
+	ExpiresAt *time.Time `bson:"expires_at,omitempty" json:"expires_at,omitempty"`
+}
+
+// Status returns the account status of u.
+func (u *User) Status() string {
+	if u.Moderation == nil {
+		return StatusActive
+	}
+	return u.Moderation.Status
+}
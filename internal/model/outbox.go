@@ -0,0 +1,30 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Outbox message states.
+const (
+	OutboxPending = "pending"
+	OutboxSent    = "sent"
+	// OutboxDead marks messages that ran out of attempts; they need manual attention
+	OutboxDead = "dead"
+)
+
+// OutboxMessage is an event stored with the write that caused it and relayed to Kafka afterwards
+type OutboxMessage struct {
+	ID        uuid.UUID `bson:"_id" json:"id"`
+	EventType string    `bson:"event_type" json:"event_type"`
+	// Payload is the JSON encoded event
+	Payload []byte `bson:"payload" json:"payload"`
+
+	Status        string     `bson:"status" json:"status"`
+	Attempts      int        `bson:"attempts" json:"attempts"`
+	NextAttemptAt time.Time  `bson:"next_attempt_at" json:"next_attempt_at"`
+	LastError     string     `bson:"last_error,omitempty" json:"last_error,omitempty"`
+	CreatedAt     time.Time  `bson:"created_at" json:"created_at"`
+	SentAt        *time.Time `bson:"sent_at,omitempty" json:"sent_at,omitempty"`
+}
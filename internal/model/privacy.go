@@ -0,0 +1,42 @@
+package model
+
+// Visibility of a profile field.
+const (
+	// VisibilityPublic shows the field to everyone, anonymous callers included
+	VisibilityPublic = "public"
+	// VisibilityAuthenticated shows the field to any signed-in user
+	VisibilityAuthenticated = "authenticated"
+	// VisibilityPrivate shows the field to its owner and admins only
+	VisibilityPrivate = "private"
+)
+
+// DefaultVisibility lists the fields whose visibility users may choose, with
+// the visibility they have until the user changes it.
+var DefaultVisibility = map[string]string{
+	"email":         VisibilityPrivate,
+	"date_of_birth": VisibilityAuthenticated,
+	"gender":        VisibilityPublic,
+	"location":      VisibilityPublic,
+	"socials":       VisibilityPublic,
+}
+
+// Privacy holds a user's visibility choices by field. Fields absent from it
+// have their DefaultVisibility.
+type Privacy map[string]string
+
+// Visibility returns the visibility of a field listed in DefaultVisibility.
+func (u *User) Visibility(field string) string {
+	if v, ok := u.Privacy[field]; ok {
+		return v
+	}
+	return DefaultVisibility[field]
+}
+
+// EffectivePrivacy returns the visibility of every field in DefaultVisibility.
+func (u *User) EffectivePrivacy() Privacy {
+	p := make(Privacy, len(DefaultVisibility))
+	for field := range DefaultVisibility {
+		p[field] = u.Visibility(field)
+	}
+	return p
+}
@@ -0,0 +1,31 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// UserRevision records one change of a user profile in the user_revisions collection
+type UserRevision struct {
+	ID     uuid.UUID `bson:"_id" json:"id"`
+	UserID uuid.UUID `bson:"user_id" json:"user_id"`
+	// Version is the profile version after the change
+	Version int64  `bson:"version" json:"version"`
+	Action  string `bson:"action" json:"action"`
+
+	Changes []FieldChange `bson:"changes" json:"changes"`
+
+	// Actor is the caller who made the change; empty for changes driven by events
+	Actor     *uuid.UUID `bson:"actor,omitempty" json:"actor,omitempty"`
+	Source    string     `bson:"source" json:"source"`
+	CreatedAt time.Time  `bson:"created_at" json:"created_at"`
+}
+
+// FieldChange holds the old and new value of one profile field. A nil value
+// means the field was not set.
+type FieldChange struct {
+	Field string `bson:"field" json:"field"`
+	Old   any    `bson:"old,omitempty" json:"old"`
+	New   any    `bson:"new,omitempty" json:"new"`
+}
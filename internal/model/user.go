@@ -6,13 +6,17 @@ import (
 	"github.com/google/uuid"
 )
 
-// User represents a user profile in MongoDB
+// User represents a user profile in MongoDB.
+// The validate tags document the rules service.ValidateUser enforces.
 type User struct {
 	ID        uuid.UUID  `bson:"_id,omitempty" json:"id" validate:"omitempty"`
 	CreatedAt time.Time  `bson:"created_at,omitempty" json:"created_at" validate:"omitempty"`
 	UpdatedAt time.Time  `bson:"updated_at,omitempty" json:"updated_at" validate:"omitempty"`
 	DeletedAt *time.Time `bson:"deleted_at,omitempty" json:"deleted_at" validate:"omitempty"`
 
+	// Version is incremented on every write and guards against lost updates
+	Version int64 `bson:"version" json:"version"`
+
 	Email     string `bson:"email" json:"email" validate:"required,email"`
 	Firstname string `bson:"firstname" json:"firstname" validate:"required,min=2,max=20"`
 	Lastname  string `bson:"lastname" json:"lastname" validate:"required,min=2,max=20"`
@@ -27,4 +31,36 @@ type User struct {
 
 	Socials         []string `bson:"socials,omitempty" json:"socials,omitempty" validate:"omitempty,dive,url"`
 	NeedsCompletion bool     `bson:"needs_completion" json:"needs_completion"`
+
+	// CompletedAt is when the profile first met the completion policy
+	CompletedAt *time.Time `bson:"completed_at,omitempty" json:"completed_at,omitempty"`
+
+	// Moderation is set while the account is banned or suspended
+	Moderation *Moderation `bson:"moderation,omitempty" json:"moderation,omitempty"`
+
+	// Roles are the application roles granted through the admin API, sorted;
+	// they are mirrored to Keycloak as realm roles
+	Roles []string `bson:"roles,omitempty" json:"roles,omitempty"`
+
+	// Privacy overrides the default visibility of sensitive fields
+	Privacy Privacy `bson:"privacy,omitempty" json:"privacy,omitempty"`
+
+	// Handle is the unique, lower case name the profile is addressed by as @handle
+	Handle string `bson:"handle,omitempty" json:"handle,omitempty"`
+	// HandleChangedAt is when the handle was last renamed; renames are rate-limited from it
+	HandleChangedAt *time.Time `bson:"handle_changed_at,omitempty" json:"handle_changed_at,omitempty"`
+	// FormerHandles keep redirecting to the profile after a rename, newest first
+	FormerHandles []FormerHandle `bson:"former_handles,omitempty" json:"former_handles,omitempty"`
+
+	// Follow counts are kept in step with the follows collection; profile
+	// writes never touch them
+	FollowersCount int64 `bson:"followers_count,omitempty" json:"followers_count"`
+	FollowingCount int64 `bson:"following_count,omitempty" json:"following_count"`
+}
+
+// FormerHandle is a handle given up in a rename. Until it expires it
+// redirects to its former owner and nobody else may claim it.
+type FormerHandle struct {
+	Handle string    `bson:"handle" json:"handle"`
+	Until  time.Time `bson:"until" json:"until"`
 }
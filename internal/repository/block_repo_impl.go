@@ -0,0 +1,127 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/Sayan80bayev/go-project/pkg/logging"
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"userService/internal/model"
+)
+
+type MongoBlockRepository struct {
+	collection *mongo.Collection
+}
+
+func NewBlockRepository(db *mongo.Database) *MongoBlockRepository {
+	return &MongoBlockRepository{
+		collection: db.Collection("blocks"),
+	}
+}
+
+// AddBlock stores b unless it exists and reports whether it was new. An
+// upsert is used rather than catching duplicate keys, which would abort the
+// surrounding transaction.
+func (r *MongoBlockRepository) AddBlock(ctx context.Context, b *model.Block) (bool, error) {
+	res, err := r.collection.UpdateOne(ctx,
+		bson.M{"blocker_id": b.BlockerID, "blocked_id": b.BlockedID},
+		bson.M{"$setOnInsert": bson.M{"created_at": b.CreatedAt}},
+		options.Update().SetUpsert(true),
+	)
+	if err != nil {
+		return false, err
+	}
+	return res.UpsertedCount == 1, nil
+}
+
+// RemoveBlock deletes the block and reports whether it existed.
+func (r *MongoBlockRepository) RemoveBlock(ctx context.Context, blockerID, blockedID uuid.UUID) (bool, error) {
+	res, err := r.collection.DeleteOne(ctx, bson.M{"blocker_id": blockerID, "blocked_id": blockedID})
+	if err != nil {
+		return false, err
+	}
+	return res.DeletedCount == 1, nil
+}
+
+// ListBlockers returns everyone that blocked blockedID.
+func (r *MongoBlockRepository) ListBlockers(ctx context.Context, blockedID uuid.UUID) ([]uuid.UUID, error) {
+	logger := logging.GetLogger()
+
+	cur, err := r.collection.Find(ctx, bson.M{"blocked_id": blockedID},
+		options.Find().SetProjection(bson.M{"blocker_id": 1}))
+	if err != nil {
+		return nil, err
+	}
+
+	defer func(cur *mongo.Cursor, ctx context.Context) {
+		if err := cur.Close(ctx); err != nil {
+			logger.Errorf("Couldn't close cursor: %v", err)
+		}
+	}(cur, ctx)
+
+	var blocks []model.Block
+	if err = cur.All(ctx, &blocks); err != nil {
+		return nil, err
+	}
+	blockers := make([]uuid.UUID, len(blocks))
+	for i, b := range blocks {
+		blockers[i] = b.BlockerID
+	}
+	return blockers, nil
+}
+
+// ListBlocked returns everyone blockerID blocked.
+func (r *MongoBlockRepository) ListBlocked(ctx context.Context, blockerID uuid.UUID) ([]uuid.UUID, error) {
+	logger := logging.GetLogger()
+
+	cur, err := r.collection.Find(ctx, bson.M{"blocker_id": blockerID},
+		options.Find().SetProjection(bson.M{"blocked_id": 1}))
+	if err != nil {
+		return nil, err
+	}
+
+	defer func(cur *mongo.Cursor, ctx context.Context) {
+		if err := cur.Close(ctx); err != nil {
+			logger.Errorf("Couldn't close cursor: %v", err)
+		}
+	}(cur, ctx)
+
+	var blocks []model.Block
+	if err = cur.All(ctx, &blocks); err != nil {
+		return nil, err
+	}
+	blocked := make([]uuid.UUID, len(blocks))
+	for i, b := range blocks {
+		blocked[i] = b.BlockedID
+	}
+	return blocked, nil
+}
+
+// RemoveBlocksOf deletes every block by or of userID and returns them.
+func (r *MongoBlockRepository) RemoveBlocksOf(ctx context.Context, userID uuid.UUID) ([]model.Block, error) {
+	logger := logging.GetLogger()
+
+	filter := bson.M{"$or": bson.A{bson.M{"blocker_id": userID}, bson.M{"blocked_id": userID}}}
+	cur, err := r.collection.Find(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	defer func(cur *mongo.Cursor, ctx context.Context) {
+		if err := cur.Close(ctx); err != nil {
+			logger.Errorf("Couldn't close cursor: %v", err)
+		}
+	}(cur, ctx)
+
+	var blocks []model.Block
+	if err = cur.All(ctx, &blocks); err != nil {
+		return nil, err
+	}
+	if _, err = r.collection.DeleteMany(ctx, filter); err != nil {
+		return nil, err
+	}
+	return blocks, nil
+}
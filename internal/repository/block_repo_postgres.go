@@ -0,0 +1,98 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"userService/internal/model"
+)
+
+// PostgresBlockRepository stores blocks in the blocks table.
+type PostgresBlockRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewPostgresBlockRepository(pool *pgxpool.Pool) *PostgresBlockRepository {
+	return &PostgresBlockRepository{pool: pool}
+}
+
+// AddBlock stores b unless it exists and reports whether it was new.
+func (r *PostgresBlockRepository) AddBlock(ctx context.Context, b *model.Block) (bool, error) {
+	tag, err := pgConn(ctx, r.pool).Exec(ctx, `INSERT INTO blocks (blocker_id, blocked_id, created_at)
+		VALUES ($1, $2, $3) ON CONFLICT DO NOTHING`, b.BlockerID, b.BlockedID, b.CreatedAt)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() == 1, nil
+}
+
+// RemoveBlock deletes the block and reports whether it existed.
+func (r *PostgresBlockRepository) RemoveBlock(ctx context.Context, blockerID, blockedID uuid.UUID) (bool, error) {
+	tag, err := pgConn(ctx, r.pool).Exec(ctx,
+		`DELETE FROM blocks WHERE blocker_id = $1 AND blocked_id = $2`, blockerID, blockedID)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() == 1, nil
+}
+
+// ListBlockers returns everyone that blocked blockedID.
+func (r *PostgresBlockRepository) ListBlockers(ctx context.Context, blockedID uuid.UUID) ([]uuid.UUID, error) {
+	rows, err := pgConn(ctx, r.pool).Query(ctx, `SELECT blocker_id FROM blocks WHERE blocked_id = $1`, blockedID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var blockers []uuid.UUID
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		blockers = append(blockers, id)
+	}
+	return blockers, rows.Err()
+}
+
+// ListBlocked returns everyone blockerID blocked.
+func (r *PostgresBlockRepository) ListBlocked(ctx context.Context, blockerID uuid.UUID) ([]uuid.UUID, error) {
+	rows, err := pgConn(ctx, r.pool).Query(ctx, `SELECT blocked_id FROM blocks WHERE blocker_id = $1`, blockerID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var blocked []uuid.UUID
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		blocked = append(blocked, id)
+	}
+	return blocked, rows.Err()
+}
+
+// RemoveBlocksOf deletes every block by or of userID and returns them.
+func (r *PostgresBlockRepository) RemoveBlocksOf(ctx context.Context, userID uuid.UUID) ([]model.Block, error) {
+	rows, err := pgConn(ctx, r.pool).Query(ctx, `DELETE FROM blocks
+		WHERE blocker_id = $1 OR blocked_id = $1
+		RETURNING blocker_id, blocked_id, created_at`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var blocks []model.Block
+	for rows.Next() {
+		var b model.Block
+		if err := rows.Scan(&b.BlockerID, &b.BlockedID, &b.CreatedAt); err != nil {
+			return nil, err
+		}
+		blocks = append(blocks, b)
+	}
+	return blocks, rows.Err()
+}
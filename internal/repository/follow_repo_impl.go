@@ -0,0 +1,134 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/Sayan80bayev/go-project/pkg/logging"
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"userService/internal/model"
+	"userService/internal/service"
+)
+
+type MongoFollowRepository struct {
+	collection *mongo.Collection
+}
+
+func NewFollowRepository(db *mongo.Database) *MongoFollowRepository {
+	return &MongoFollowRepository{
+		collection: db.Collection("follows"),
+	}
+}
+
+// AddFollow stores f unless the edge exists and reports whether it was new.
+// An upsert is used rather than catching duplicate keys, which would abort
+// the surrounding transaction.
+func (r *MongoFollowRepository) AddFollow(ctx context.Context, f *model.Follow) (bool, error) {
+	res, err := r.collection.UpdateOne(ctx,
+		bson.M{"follower_id": f.FollowerID, "followee_id": f.FolloweeID},
+		bson.M{"$setOnInsert": bson.M{"created_at": f.CreatedAt}},
+		options.Update().SetUpsert(true),
+	)
+	if err != nil {
+		return false, err
+	}
+	return res.UpsertedCount == 1, nil
+}
+
+// RemoveFollow deletes the edge and reports whether it existed.
+func (r *MongoFollowRepository) RemoveFollow(ctx context.Context, followerID, followeeID uuid.UUID) (bool, error) {
+	res, err := r.collection.DeleteOne(ctx, bson.M{"follower_id": followerID, "followee_id": followeeID})
+	if err != nil {
+		return false, err
+	}
+	return res.DeletedCount == 1, nil
+}
+
+// IsFollowing reports whether followerID follows followeeID.
+func (r *MongoFollowRepository) IsFollowing(ctx context.Context, followerID, followeeID uuid.UUID) (bool, error) {
+	err := r.collection.FindOne(ctx,
+		bson.M{"follower_id": followerID, "followee_id": followeeID},
+		options.FindOne().SetProjection(bson.M{"_id": 1}),
+	).Err()
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// ListFollowers returns up to limit follows of userID, newest first, starting
+// after the cursor, whose ID is a follower.
+func (r *MongoFollowRepository) ListFollowers(ctx context.Context, userID uuid.UUID, limit int, after *service.Cursor) ([]model.Follow, error) {
+	return r.list(ctx, "followee_id", "follower_id", userID, limit, after)
+}
+
+// ListFollowing returns up to limit follows by userID, newest first, starting
+// after the cursor, whose ID is a followee.
+func (r *MongoFollowRepository) ListFollowing(ctx context.Context, userID uuid.UUID, limit int, after *service.Cursor) ([]model.Follow, error) {
+	return r.list(ctx, "follower_id", "followee_id", userID, limit, after)
+}
+
+// list pages through the edges whose key field is userID; ties on created_at
+// are broken by the other end of the edge.
+func (r *MongoFollowRepository) list(ctx context.Context, key, other string, userID uuid.UUID, limit int, after *service.Cursor) ([]model.Follow, error) {
+	logger := logging.GetLogger()
+
+	filter := bson.M{key: userID}
+	if after != nil {
+		filter["$or"] = bson.A{
+			bson.M{"created_at": bson.M{"$lt": after.Value}},
+			bson.M{"created_at": after.Value, other: bson.M{"$lt": after.ID}},
+		}
+	}
+
+	opts := options.Find().
+		SetSort(bson.D{{Key: "created_at", Value: -1}, {Key: other, Value: -1}}).
+		SetLimit(int64(limit))
+
+	cur, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	defer func(cur *mongo.Cursor, ctx context.Context) {
+		if err := cur.Close(ctx); err != nil {
+			logger.Errorf("Couldn't close cursor: %v", err)
+		}
+	}(cur, ctx)
+
+	follows := make([]model.Follow, 0, limit)
+	if err = cur.All(ctx, &follows); err != nil {
+		return nil, err
+	}
+	return follows, nil
+}
+
+// RemoveFollowsOf deletes every edge from or to userID and returns them.
+func (r *MongoFollowRepository) RemoveFollowsOf(ctx context.Context, userID uuid.UUID) ([]model.Follow, error) {
+	logger := logging.GetLogger()
+
+	filter := bson.M{"$or": bson.A{bson.M{"follower_id": userID}, bson.M{"followee_id": userID}}}
+	cur, err := r.collection.Find(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	defer func(cur *mongo.Cursor, ctx context.Context) {
+		if err := cur.Close(ctx); err != nil {
+			logger.Errorf("Couldn't close cursor: %v", err)
+		}
+	}(cur, ctx)
+
+	var follows []model.Follow
+	if err = cur.All(ctx, &follows); err != nil {
+		return nil, err
+	}
+	if _, err = r.collection.DeleteMany(ctx, filter); err != nil {
+		return nil, err
+	}
+	return follows, nil
+}
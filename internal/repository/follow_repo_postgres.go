@@ -0,0 +1,110 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"userService/internal/model"
+	"userService/internal/service"
+)
+
+// PostgresFollowRepository stores the follow graph in the follows table.
+type PostgresFollowRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewPostgresFollowRepository(pool *pgxpool.Pool) *PostgresFollowRepository {
+	return &PostgresFollowRepository{pool: pool}
+}
+
+// AddFollow stores f unless the edge exists and reports whether it was new.
+func (r *PostgresFollowRepository) AddFollow(ctx context.Context, f *model.Follow) (bool, error) {
+	tag, err := pgConn(ctx, r.pool).Exec(ctx, `INSERT INTO follows (follower_id, followee_id, created_at)
+		VALUES ($1, $2, $3) ON CONFLICT DO NOTHING`, f.FollowerID, f.FolloweeID, f.CreatedAt)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() == 1, nil
+}
+
+// RemoveFollow deletes the edge and reports whether it existed.
+func (r *PostgresFollowRepository) RemoveFollow(ctx context.Context, followerID, followeeID uuid.UUID) (bool, error) {
+	tag, err := pgConn(ctx, r.pool).Exec(ctx,
+		`DELETE FROM follows WHERE follower_id = $1 AND followee_id = $2`, followerID, followeeID)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() == 1, nil
+}
+
+// IsFollowing reports whether followerID follows followeeID.
+func (r *PostgresFollowRepository) IsFollowing(ctx context.Context, followerID, followeeID uuid.UUID) (bool, error) {
+	var following bool
+	err := pgConn(ctx, r.pool).QueryRow(ctx,
+		`SELECT EXISTS (SELECT 1 FROM follows WHERE follower_id = $1 AND followee_id = $2)`,
+		followerID, followeeID).Scan(&following)
+	return following, err
+}
+
+// ListFollowers returns up to limit follows of userID, newest first, starting
+// after the cursor, whose ID is a follower.
+func (r *PostgresFollowRepository) ListFollowers(ctx context.Context, userID uuid.UUID, limit int, after *service.Cursor) ([]model.Follow, error) {
+	return r.list(ctx, "followee_id", "follower_id", userID, limit, after)
+}
+
+// ListFollowing returns up to limit follows by userID, newest first, starting
+// after the cursor, whose ID is a followee.
+func (r *PostgresFollowRepository) ListFollowing(ctx context.Context, userID uuid.UUID, limit int, after *service.Cursor) ([]model.Follow, error) {
+	return r.list(ctx, "follower_id", "followee_id", userID, limit, after)
+}
+
+// list pages through the edges whose key column is userID; ties on created_at
+// are broken by the other end of the edge. Column names are constants.
+func (r *PostgresFollowRepository) list(ctx context.Context, key, other string, userID uuid.UUID, limit int, after *service.Cursor) ([]model.Follow, error) {
+	sql := `SELECT follower_id, followee_id, created_at FROM follows WHERE ` + key + ` = $1`
+	args := []any{userID, limit}
+	if after != nil {
+		sql += ` AND (created_at, ` + other + `) < ($3, $4)`
+		args = append(args, after.Value, after.ID)
+	}
+	sql += ` ORDER BY created_at DESC, ` + other + ` DESC LIMIT $2`
+
+	rows, err := pgConn(ctx, r.pool).Query(ctx, sql, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	follows := make([]model.Follow, 0, limit)
+	for rows.Next() {
+		var f model.Follow
+		if err := rows.Scan(&f.FollowerID, &f.FolloweeID, &f.CreatedAt); err != nil {
+			return nil, err
+		}
+		follows = append(follows, f)
+	}
+	return follows, rows.Err()
+}
+
+// RemoveFollowsOf deletes every edge from or to userID and returns them.
+func (r *PostgresFollowRepository) RemoveFollowsOf(ctx context.Context, userID uuid.UUID) ([]model.Follow, error) {
+	rows, err := pgConn(ctx, r.pool).Query(ctx, `DELETE FROM follows
+		WHERE follower_id = $1 OR followee_id = $1
+		RETURNING follower_id, followee_id, created_at`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var follows []model.Follow
+	for rows.Next() {
+		var f model.Follow
+		if err := rows.Scan(&f.FollowerID, &f.FolloweeID, &f.CreatedAt); err != nil {
+			return nil, err
+		}
+		follows = append(follows, f)
+	}
+	return follows, rows.Err()
+}
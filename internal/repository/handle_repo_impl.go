@@ -0,0 +1,50 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"userService/internal/service"
+)
+
+// MongoHandleRepository keeps one document per reserved handle, keyed by the
+// handle itself so that _id enforces uniqueness.
+type MongoHandleRepository struct {
+	collection *mongo.Collection
+}
+
+func NewHandleRepository(db *mongo.Database) *MongoHandleRepository {
+	return &MongoHandleRepository{
+		collection: db.Collection("handle_reservations"),
+	}
+}
+
+// ReserveHandle gives handle to userID until until, or for good when until is
+// nil. The upsert only matches a reservation of userID or an expired one;
+// otherwise it inserts a second document with the same _id and fails.
+func (r *MongoHandleRepository) ReserveHandle(ctx context.Context, handle string, userID uuid.UUID, until *time.Time, now time.Time) error {
+	// A null until never matches $lte, so current handles do not expire
+	_, err := r.collection.UpdateOne(ctx,
+		bson.M{"_id": handle, "$or": bson.A{
+			bson.M{"user_id": userID},
+			bson.M{"until": bson.M{"$lte": now}},
+		}},
+		bson.M{"$set": bson.M{"user_id": userID, "until": until}},
+		options.Update().SetUpsert(true),
+	)
+	if mongo.IsDuplicateKeyError(err) {
+		return &service.ConflictError{Field: service.FieldHandle}
+	}
+	return err
+}
+
+// ReleaseHandles drops every reservation of userID.
+func (r *MongoHandleRepository) ReleaseHandles(ctx context.Context, userID uuid.UUID) error {
+	_, err := r.collection.DeleteMany(ctx, bson.M{"user_id": userID})
+	return err
+}
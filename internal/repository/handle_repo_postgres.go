@@ -0,0 +1,43 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"userService/internal/service"
+)
+
+// PostgresHandleRepository keeps reserved handles in the handle_reservations table.
+type PostgresHandleRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewPostgresHandleRepository(pool *pgxpool.Pool) *PostgresHandleRepository {
+	return &PostgresHandleRepository{pool: pool}
+}
+
+// ReserveHandle gives handle to userID until until, or for good when until is
+// nil. A reservation of another user is only taken over once it expired.
+func (r *PostgresHandleRepository) ReserveHandle(ctx context.Context, handle string, userID uuid.UUID, until *time.Time, now time.Time) error {
+	tag, err := pgConn(ctx, r.pool).Exec(ctx, `INSERT INTO handle_reservations (handle, user_id, until)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (handle) DO UPDATE SET user_id = EXCLUDED.user_id, until = EXCLUDED.until
+		WHERE handle_reservations.user_id = EXCLUDED.user_id OR handle_reservations.until <= $4`,
+		handle, userID, until, now)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return &service.ConflictError{Field: service.FieldHandle}
+	}
+	return nil
+}
+
+// ReleaseHandles drops every reservation of userID.
+func (r *PostgresHandleRepository) ReleaseHandles(ctx context.Context, userID uuid.UUID) error {
+	_, err := pgConn(ctx, r.pool).Exec(ctx, `DELETE FROM handle_reservations WHERE user_id = $1`, userID)
+	return err
+}
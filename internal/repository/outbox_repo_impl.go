@@ -0,0 +1,120 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"userService/internal/model"
+	"userService/internal/service"
+)
+
+type MongoOutboxRepository struct {
+	collection *mongo.Collection
+}
+
+func NewOutboxRepository(db *mongo.Database) *MongoOutboxRepository {
+	return &MongoOutboxRepository{
+		collection: db.Collection("outbox"),
+	}
+}
+
+// Enqueue inserts a message; inside a transaction it commits with the write that caused it.
+func (r *MongoOutboxRepository) Enqueue(ctx context.Context, msg *model.OutboxMessage) error {
+	_, err := r.collection.InsertOne(ctx, msg)
+	return err
+}
+
+// ClaimPending hides up to limit due messages, oldest first, until now+lease and returns them.
+func (r *MongoOutboxRepository) ClaimPending(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]model.OutboxMessage, error) {
+	filter := bson.M{
+		"status":          model.OutboxPending,
+		"next_attempt_at": bson.M{"$lte": now},
+	}
+	update := bson.M{"$set": bson.M{"next_attempt_at": now.Add(lease)}}
+	opts := options.FindOneAndUpdate().
+		SetSort(bson.D{{Key: "_id", Value: 1}}).
+		SetReturnDocument(options.After)
+
+	// One document at a time: every claim is atomic, so concurrent relays never share a message
+	messages := make([]model.OutboxMessage, 0, limit)
+	for len(messages) < limit {
+		var msg model.OutboxMessage
+		err := r.collection.FindOneAndUpdate(ctx, filter, update, opts).Decode(&msg)
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			break
+		}
+		if err != nil {
+			return messages, err
+		}
+		messages = append(messages, msg)
+	}
+	return messages, nil
+}
+
+// MarkSent records that a message was published.
+func (r *MongoOutboxRepository) MarkSent(ctx context.Context, id uuid.UUID, sentAt time.Time) error {
+	_, err := r.collection.UpdateOne(ctx, bson.M{"_id": id}, bson.M{
+		"$set":   bson.M{"status": model.OutboxSent, "sent_at": sentAt},
+		"$unset": bson.M{"last_error": ""},
+	})
+	return err
+}
+
+// MarkFailed records a failed attempt and when to retry.
+func (r *MongoOutboxRepository) MarkFailed(ctx context.Context, id uuid.UUID, attempts int, nextAttempt time.Time, lastErr string, dead bool) error {
+	status := model.OutboxPending
+	if dead {
+		status = model.OutboxDead
+	}
+	_, err := r.collection.UpdateOne(ctx, bson.M{"_id": id}, bson.M{"$set": bson.M{
+		"status":          status,
+		"attempts":        attempts,
+		"next_attempt_at": nextAttempt,
+		"last_error":      lastErr,
+	}})
+	return err
+}
+
+// DeleteSentBefore removes messages published before cutoff.
+func (r *MongoOutboxRepository) DeleteSentBefore(ctx context.Context, cutoff time.Time) (int64, error) {
+	res, err := r.collection.DeleteMany(ctx, bson.M{
+		"status":  model.OutboxSent,
+		"sent_at": bson.M{"$lt": cutoff},
+	})
+	if err != nil {
+		return 0, err
+	}
+	return res.DeletedCount, nil
+}
+
+// Stats counts pending and dead messages and finds the oldest pending one.
+func (r *MongoOutboxRepository) Stats(ctx context.Context) (service.OutboxStats, error) {
+	var stats service.OutboxStats
+
+	var err error
+	if stats.Pending, err = r.collection.CountDocuments(ctx, bson.M{"status": model.OutboxPending}); err != nil {
+		return stats, err
+	}
+	if stats.Dead, err = r.collection.CountDocuments(ctx, bson.M{"status": model.OutboxDead}); err != nil {
+		return stats, err
+	}
+
+	var oldest model.OutboxMessage
+	err = r.collection.FindOne(ctx, bson.M{"status": model.OutboxPending},
+		options.FindOne().SetSort(bson.D{{Key: "_id", Value: 1}}),
+	).Decode(&oldest)
+	switch {
+	case errors.Is(err, mongo.ErrNoDocuments):
+	case err != nil:
+		return stats, err
+	default:
+		stats.OldestPending = oldest.CreatedAt
+	}
+	return stats, nil
+}
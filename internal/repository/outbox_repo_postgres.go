@@ -0,0 +1,127 @@
+package repository
+
+import (
+	"bytes"
+	"context"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"userService/internal/model"
+	"userService/internal/service"
+)
+
+const outboxColumns = `id, event_type, payload, status, attempts, next_attempt_at, last_error, created_at, sent_at`
+
+// PostgresOutboxRepository stores outbox messages in the outbox table.
+type PostgresOutboxRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewPostgresOutboxRepository(pool *pgxpool.Pool) *PostgresOutboxRepository {
+	return &PostgresOutboxRepository{pool: pool}
+}
+
+// Enqueue inserts a message; inside a transaction it commits with the write that caused it.
+func (r *PostgresOutboxRepository) Enqueue(ctx context.Context, msg *model.OutboxMessage) error {
+	_, err := pgConn(ctx, r.pool).Exec(ctx, `INSERT INTO outbox (`+outboxColumns+`)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)`,
+		msg.ID, msg.EventType, string(msg.Payload), msg.Status, msg.Attempts,
+		msg.NextAttemptAt, msg.LastError, msg.CreatedAt, msg.SentAt,
+	)
+	return err
+}
+
+// ClaimPending hides up to limit due messages, oldest first, until now+lease and returns them.
+// SKIP LOCKED lets concurrent relays claim disjoint batches.
+func (r *PostgresOutboxRepository) ClaimPending(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]model.OutboxMessage, error) {
+	rows, err := pgConn(ctx, r.pool).Query(ctx, `UPDATE outbox SET next_attempt_at = $2
+		WHERE id IN (
+			SELECT id FROM outbox
+			WHERE status = 'pending' AND next_attempt_at <= $1
+			ORDER BY id
+			LIMIT $3
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING `+outboxColumns, now, now.Add(lease), limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	messages := make([]model.OutboxMessage, 0, limit)
+	for rows.Next() {
+		var msg model.OutboxMessage
+		var payload string
+		if err := rows.Scan(
+			&msg.ID, &msg.EventType, &payload, &msg.Status, &msg.Attempts,
+			&msg.NextAttemptAt, &msg.LastError, &msg.CreatedAt, &msg.SentAt,
+		); err != nil {
+			return nil, err
+		}
+		msg.Payload = []byte(payload)
+		messages = append(messages, msg)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// RETURNING does not keep the subquery's order
+	sortOutbox(messages)
+	return messages, nil
+}
+
+// MarkSent records that a message was published.
+func (r *PostgresOutboxRepository) MarkSent(ctx context.Context, id uuid.UUID, sentAt time.Time) error {
+	_, err := pgConn(ctx, r.pool).Exec(ctx,
+		`UPDATE outbox SET status = 'sent', sent_at = $2, last_error = '' WHERE id = $1`, id, sentAt)
+	return err
+}
+
+// MarkFailed records a failed attempt and when to retry.
+func (r *PostgresOutboxRepository) MarkFailed(ctx context.Context, id uuid.UUID, attempts int, nextAttempt time.Time, lastErr string, dead bool) error {
+	status := model.OutboxPending
+	if dead {
+		status = model.OutboxDead
+	}
+	_, err := pgConn(ctx, r.pool).Exec(ctx, `UPDATE outbox
+		SET status = $2, attempts = $3, next_attempt_at = $4, last_error = $5
+		WHERE id = $1`, id, status, attempts, nextAttempt, lastErr)
+	return err
+}
+
+// DeleteSentBefore removes messages published before cutoff.
+func (r *PostgresOutboxRepository) DeleteSentBefore(ctx context.Context, cutoff time.Time) (int64, error) {
+	tag, err := pgConn(ctx, r.pool).Exec(ctx, `DELETE FROM outbox WHERE status = 'sent' AND sent_at < $1`, cutoff)
+	if err != nil {
+		return 0, err
+	}
+	return tag.RowsAffected(), nil
+}
+
+// Stats counts pending and dead messages and finds the oldest pending one.
+func (r *PostgresOutboxRepository) Stats(ctx context.Context) (service.OutboxStats, error) {
+	var stats service.OutboxStats
+	var oldest *time.Time
+	err := pgConn(ctx, r.pool).QueryRow(ctx, `SELECT
+			count(*) FILTER (WHERE status = 'pending'),
+			count(*) FILTER (WHERE status = 'dead'),
+			min(created_at) FILTER (WHERE status = 'pending')
+		FROM outbox WHERE status <> 'sent'`).Scan(&stats.Pending, &stats.Dead, &oldest)
+	if err != nil {
+		return stats, err
+	}
+	if oldest != nil {
+		stats.OldestPending = *oldest
+	}
+	return stats, nil
+}
+
+// sortOutbox orders messages by ID, which are time ordered UUIDv7.
+func sortOutbox(messages []model.OutboxMessage) {
+	sort.Slice(messages, func(i, j int) bool {
+		return bytes.Compare(messages[i].ID[:], messages[j].ID[:]) < 0
+	})
+}
@@ -0,0 +1,496 @@
+// Package repotest holds a conformance suite that every service.UserRepository
+// implementation has to pass, so the backends stay interchangeable.
+package repotest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"userService/internal/model"
+	"userService/internal/service"
+)
+
+// Factory returns an empty repository. It is called once per subtest.
+type Factory func(t *testing.T) service.UserRepository
+
+// RunUserRepository runs the conformance suite against repositories built by newRepo.
+func RunUserRepository(t *testing.T, newRepo Factory) {
+	tests := []struct {
+		name string
+		run  func(t *testing.T, repo service.UserRepository)
+	}{
+		{"CreateAndGet", testCreateAndGet},
+		{"DuplicateCreate", testDuplicateCreate},
+		{"NotFound", testNotFound},
+		{"SoftDelete", testSoftDelete},
+		{"UpdateDeleted", testUpdateDeleted},
+		{"VersionConflict", testVersionConflict},
+		{"RestoreAndPurge", testRestoreAndPurge},
+		{"ListUsers", testListUsers},
+		{"Moderation", testModeration},
+		{"Roles", testRoles},
+		{"Privacy", testPrivacy},
+		{"Handles", testHandles},
+		{"FollowCounts", testFollowCounts},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.run(t, newRepo(t))
+		})
+	}
+}
+
+func newUser(email string) *model.User {
+	return &model.User{
+		ID:        uuid.New(),
+		Firstname: "Ada",
+		Lastname:  "Lovelace",
+		Email:     email,
+		Location:  "London",
+		Socials:   []string{"https://example.com/ada"},
+	}
+}
+
+func create(t *testing.T, repo service.UserRepository, email string) *model.User {
+	t.Helper()
+	user := newUser(email)
+	require.NoError(t, repo.CreateUser(context.Background(), user))
+	return user
+}
+
+func testCreateAndGet(t *testing.T, repo service.UserRepository) {
+	ctx := context.Background()
+	user := create(t, repo, "ada@example.com")
+	assert.Equal(t, int64(1), user.Version)
+	assert.False(t, user.CreatedAt.IsZero())
+
+	got, err := repo.GetUserById(ctx, user.ID)
+	require.NoError(t, err)
+	require.NotNil(t, got)
+	assert.Equal(t, user.Firstname, got.Firstname)
+	assert.Equal(t, user.Email, got.Email)
+	assert.Equal(t, user.Socials, got.Socials)
+	assert.Equal(t, int64(1), got.Version)
+	assert.WithinDuration(t, user.CreatedAt, got.CreatedAt, time.Millisecond)
+	assert.Nil(t, got.DeletedAt)
+
+	byEmail, err := repo.GetUserByEmail(ctx, "ada@example.com")
+	require.NoError(t, err)
+	require.NotNil(t, byEmail)
+	assert.Equal(t, user.ID, byEmail.ID)
+}
+
+func testDuplicateCreate(t *testing.T, repo service.UserRepository) {
+	ctx := context.Background()
+	user := create(t, repo, "dup@example.com")
+
+	var conflict *service.ConflictError
+
+	sameID := newUser("other@example.com")
+	sameID.ID = user.ID
+	err := repo.CreateUser(ctx, sameID)
+	require.ErrorIs(t, err, service.ErrConflict)
+	require.ErrorAs(t, err, &conflict)
+	assert.Equal(t, "id", conflict.Field)
+
+	err = repo.CreateUser(ctx, newUser("dup@example.com"))
+	require.ErrorIs(t, err, service.ErrConflict)
+	require.ErrorAs(t, err, &conflict)
+	assert.Equal(t, "email", conflict.Field)
+
+	// Taking another user's email on update is rejected the same way
+	other := create(t, repo, "other@example.com")
+	other.Email = "dup@example.com"
+	err = repo.UpdateUser(ctx, other)
+	require.ErrorAs(t, err, &conflict)
+	assert.Equal(t, "email", conflict.Field)
+
+	// Profiles without an email never collide
+	create(t, repo, "")
+	create(t, repo, "")
+
+	// The stored user is untouched by the rejected writes
+	got, err := repo.GetUserById(ctx, user.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "dup@example.com", got.Email)
+}
+
+func testNotFound(t *testing.T, repo service.UserRepository) {
+	ctx := context.Background()
+	missing := newUser("missing@example.com")
+
+	got, err := repo.GetUserById(ctx, missing.ID)
+	assert.NoError(t, err)
+	assert.Nil(t, got)
+
+	got, err = repo.GetUserByEmail(ctx, missing.Email)
+	assert.NoError(t, err)
+	assert.Nil(t, got)
+
+	users, err := repo.GetUsersByIds(ctx, []uuid.UUID{missing.ID})
+	assert.NoError(t, err)
+	assert.Empty(t, users)
+
+	missing.Version = 1
+	err = repo.UpdateUser(ctx, missing)
+	assertMissing(t, err, service.ErrUserNotFound)
+
+	_, err = repo.PatchUser(ctx, missing.ID, 1, service.UserPatch{Fields: []string{service.FieldAbout}, About: "x"})
+	assertMissing(t, err, service.ErrUserNotFound)
+
+	assert.ErrorIs(t, repo.DeleteUserById(ctx, missing.ID), service.ErrUserNotFound)
+
+	err = repo.RestoreUser(ctx, missing.ID, time.Time{})
+	assert.ErrorIs(t, err, service.ErrUserNotFound)
+
+	purged, err := repo.PurgeUser(ctx, missing.ID, time.Now())
+	assert.NoError(t, err)
+	assert.False(t, purged)
+}
+
+// assertMissing checks a write to an absent or deleted user fails with want
+// without being mistaken for a lost version race.
+func assertMissing(t *testing.T, err, want error) {
+	t.Helper()
+	assert.ErrorIs(t, err, want)
+	assert.NotErrorIs(t, err, service.ErrVersionConflict)
+}
+
+func testSoftDelete(t *testing.T, repo service.UserRepository) {
+	ctx := context.Background()
+	user := create(t, repo, "deleted@example.com")
+	kept := create(t, repo, "kept@example.com")
+
+	require.NoError(t, repo.DeleteUserById(ctx, user.ID))
+	assert.ErrorIs(t, repo.DeleteUserById(ctx, user.ID), service.ErrUserDeleted, "second delete must fail")
+
+	// Direct lookups still see the tombstone
+	got, err := repo.GetUserById(ctx, user.ID)
+	require.NoError(t, err)
+	require.NotNil(t, got)
+	assert.NotNil(t, got.DeletedAt)
+
+	got, err = repo.GetUserByEmail(ctx, user.Email)
+	require.NoError(t, err)
+	require.NotNil(t, got)
+	assert.NotNil(t, got.DeletedAt)
+
+	// Listings do not
+	listed, err := repo.ListUsers(ctx, service.ListQuery{Limit: 10, SortField: service.SortCreatedAt})
+	require.NoError(t, err)
+	assert.Equal(t, []uuid.UUID{kept.ID}, ids(listed))
+
+	batch, err := repo.GetUsersByIds(ctx, []uuid.UUID{user.ID, kept.ID})
+	require.NoError(t, err)
+	assert.Equal(t, []uuid.UUID{kept.ID}, ids(batch))
+
+	found, err := repo.SearchUsers(ctx, service.SearchQuery{Text: "Lovelace", Limit: 10})
+	require.NoError(t, err)
+	assert.Equal(t, []uuid.UUID{kept.ID}, ids(found))
+
+	// Deleted profiles give up their email to a new live one
+	successor := create(t, repo, user.Email)
+	got, err = repo.GetUserByEmail(ctx, user.Email)
+	require.NoError(t, err)
+	require.NotNil(t, got)
+	assert.Equal(t, successor.ID, got.ID)
+
+	err = repo.CreateUser(ctx, newUser(user.Email))
+	assert.ErrorIs(t, err, service.ErrConflict)
+
+	// and cannot be restored while it holds it
+	err = repo.RestoreUser(ctx, user.ID, time.Time{})
+	assert.ErrorIs(t, err, service.ErrConflict)
+}
+
+func testUpdateDeleted(t *testing.T, repo service.UserRepository) {
+	ctx := context.Background()
+	user := create(t, repo, "gone@example.com")
+	require.NoError(t, repo.DeleteUserById(ctx, user.ID))
+
+	user.About = "changed"
+	assertMissing(t, repo.UpdateUser(ctx, user), service.ErrUserDeleted)
+
+	_, err := repo.PatchUser(ctx, user.ID, user.Version, service.UserPatch{Fields: []string{service.FieldAbout}, About: "changed"})
+	assertMissing(t, err, service.ErrUserDeleted)
+
+	got, err := repo.GetUserById(ctx, user.ID)
+	require.NoError(t, err)
+	assert.Empty(t, got.About)
+	assert.Equal(t, int64(1), got.Version)
+}
+
+func testVersionConflict(t *testing.T, repo service.UserRepository) {
+	ctx := context.Background()
+	user := create(t, repo, "race@example.com")
+
+	user.About = "first"
+	require.NoError(t, repo.UpdateUser(ctx, user))
+	assert.Equal(t, int64(2), user.Version)
+
+	stale := *user
+	stale.Version = 1
+	assert.ErrorIs(t, repo.UpdateUser(ctx, &stale), service.ErrVersionConflict)
+
+	patched, err := repo.PatchUser(ctx, user.ID, 2, service.UserPatch{
+		Fields:   []string{service.FieldAbout, service.FieldLocation},
+		About:    "second",
+		Location: "",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "second", patched.About)
+	assert.Empty(t, patched.Location)
+	assert.Equal(t, "Lovelace", patched.Lastname)
+	assert.Equal(t, int64(3), patched.Version)
+
+	_, err = repo.PatchUser(ctx, user.ID, 2, service.UserPatch{Fields: []string{service.FieldAbout}, About: "third"})
+	assert.ErrorIs(t, err, service.ErrVersionConflict)
+}
+
+func testRestoreAndPurge(t *testing.T, repo service.UserRepository) {
+	ctx := context.Background()
+	live := create(t, repo, "live@example.com")
+	assert.ErrorIs(t, repo.RestoreUser(ctx, live.ID, time.Time{}), service.ErrNotDeleted)
+
+	user := create(t, repo, "restore@example.com")
+	require.NoError(t, repo.DeleteUserById(ctx, user.ID))
+
+	assert.ErrorIs(t, repo.RestoreUser(ctx, user.ID, time.Now().Add(time.Hour)), service.ErrRestoreExpired)
+	require.NoError(t, repo.RestoreUser(ctx, user.ID, time.Now().Add(-time.Hour)))
+
+	got, err := repo.GetUserById(ctx, user.ID)
+	require.NoError(t, err)
+	assert.Nil(t, got.DeletedAt)
+	assert.Equal(t, int64(2), got.Version)
+
+	require.NoError(t, repo.DeleteUserById(ctx, user.ID))
+
+	deleted, err := repo.ListDeletedBefore(ctx, time.Now().Add(time.Minute), 10)
+	require.NoError(t, err)
+	assert.Equal(t, []uuid.UUID{user.ID}, ids(deleted))
+
+	purged, err := repo.PurgeUser(ctx, live.ID, time.Now().Add(time.Minute))
+	require.NoError(t, err)
+	assert.False(t, purged, "live users are never purged")
+
+	purged, err = repo.PurgeUser(ctx, user.ID, time.Now().Add(-time.Hour))
+	require.NoError(t, err)
+	assert.False(t, purged, "users deleted after the cutoff are kept")
+
+	purged, err = repo.PurgeUser(ctx, user.ID, time.Now().Add(time.Minute))
+	require.NoError(t, err)
+	assert.True(t, purged)
+
+	got, err = repo.GetUserById(ctx, user.ID)
+	require.NoError(t, err)
+	assert.Nil(t, got)
+}
+
+func testListUsers(t *testing.T, repo service.UserRepository) {
+	ctx := context.Background()
+	var created []uuid.UUID
+	for i := 0; i < 3; i++ {
+		created = append(created, create(t, repo, "").ID)
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	q := service.ListQuery{Limit: 2, SortField: service.SortCreatedAt}
+	first, err := repo.ListUsers(ctx, q)
+	require.NoError(t, err)
+	require.Len(t, first, 2)
+
+	last := first[len(first)-1]
+	q.After = &service.Cursor{Sort: service.SortCreatedAt, Value: last.CreatedAt, ID: last.ID}
+	second, err := repo.ListUsers(ctx, q)
+	require.NoError(t, err)
+
+	assert.Equal(t, created, append(ids(first), ids(second)...))
+
+	desc, err := repo.ListUsers(ctx, service.ListQuery{Limit: 10, SortField: service.SortCreatedAt, Desc: true})
+	require.NoError(t, err)
+	assert.Equal(t, []uuid.UUID{created[2], created[1], created[0]}, ids(desc))
+
+	excluded, err := repo.ListUsers(ctx, service.ListQuery{
+		Limit: 10, SortField: service.SortCreatedAt, Filter: service.UserFilter{ExcludeIDs: created[:2]},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []uuid.UUID{created[2]}, ids(excluded))
+}
+
+func testModeration(t *testing.T, repo service.UserRepository) {
+	ctx := context.Background()
+	visible := create(t, repo, "visible@example.com")
+	banned := create(t, repo, "banned@example.com")
+	suspended := create(t, repo, "suspended@example.com")
+
+	since := time.Now().UTC().Truncate(time.Millisecond)
+	expiresAt := since.Add(time.Hour)
+	moderate := func(u *model.User, m *model.Moderation) {
+		t.Helper()
+		patch := service.UserPatch{Fields: []string{service.FieldModeration}, Moderation: m}
+		_, err := repo.PatchUser(ctx, u.ID, u.Version, patch)
+		require.NoError(t, err)
+	}
+	moderate(banned, &model.Moderation{Status: model.StatusBanned, Reason: "spam", ModeratorID: uuid.New(), Since: since})
+	moderate(suspended, &model.Moderation{Status: model.StatusSuspended, Reason: "abuse", Since: since, ExpiresAt: &expiresAt})
+
+	got, err := repo.GetUserById(ctx, suspended.ID)
+	require.NoError(t, err)
+	require.NotNil(t, got.Moderation)
+	assert.Equal(t, model.StatusSuspended, got.Status())
+	assert.Equal(t, "abuse", got.Moderation.Reason)
+	assert.True(t, expiresAt.Equal(*got.Moderation.ExpiresAt))
+
+	hidden := false
+	listed, err := repo.ListUsers(ctx, service.ListQuery{Limit: 10, SortField: service.SortCreatedAt, Filter: service.UserFilter{Moderated: &hidden}})
+	require.NoError(t, err)
+	assert.Equal(t, []uuid.UUID{visible.ID}, ids(listed))
+
+	expired, err := repo.ListModerationExpiredBefore(ctx, expiresAt.Add(time.Minute), 10)
+	require.NoError(t, err)
+	assert.Equal(t, []uuid.UUID{suspended.ID}, ids(expired), "bans without expiry never expire")
+
+	expired, err = repo.ListModerationExpiredBefore(ctx, expiresAt.Add(-time.Minute), 10)
+	require.NoError(t, err)
+	assert.Empty(t, expired)
+
+	// Clearing the moderation lifts the restriction
+	moderate(got, nil)
+	got, err = repo.GetUserById(ctx, suspended.ID)
+	require.NoError(t, err)
+	assert.Nil(t, got.Moderation)
+	assert.Equal(t, model.StatusActive, got.Status())
+}
+
+func testRoles(t *testing.T, repo service.UserRepository) {
+	ctx := context.Background()
+	user := create(t, repo, "roles@example.com")
+
+	patch := service.UserPatch{Fields: []string{service.FieldRoles}, Roles: []string{"moderator", "user-admin"}}
+	got, err := repo.PatchUser(ctx, user.ID, user.Version, patch)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"moderator", "user-admin"}, got.Roles)
+
+	// Profile updates keep the roles
+	got.About = "edited"
+	require.NoError(t, repo.UpdateUser(ctx, got))
+	got, err = repo.GetUserById(ctx, user.ID)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"moderator", "user-admin"}, got.Roles)
+
+	got, err = repo.PatchUser(ctx, user.ID, got.Version, service.UserPatch{Fields: []string{service.FieldRoles}})
+	require.NoError(t, err)
+	assert.Nil(t, got.Roles)
+}
+
+func testPrivacy(t *testing.T, repo service.UserRepository) {
+	ctx := context.Background()
+	user := create(t, repo, "privacy@example.com")
+	assert.Nil(t, user.Privacy)
+
+	privacy := model.Privacy{"email": model.VisibilityPublic, "location": model.VisibilityPrivate}
+	got, err := repo.PatchUser(ctx, user.ID, user.Version, service.UserPatch{Fields: []string{service.FieldPrivacy}, Privacy: privacy})
+	require.NoError(t, err)
+	assert.Equal(t, privacy, got.Privacy)
+
+	// Profile updates keep the settings
+	got.About = "edited"
+	require.NoError(t, repo.UpdateUser(ctx, got))
+	got, err = repo.GetUserById(ctx, user.ID)
+	require.NoError(t, err)
+	assert.Equal(t, privacy, got.Privacy)
+
+	got, err = repo.PatchUser(ctx, user.ID, got.Version, service.UserPatch{Fields: []string{service.FieldPrivacy}})
+	require.NoError(t, err)
+	assert.Nil(t, got.Privacy)
+}
+
+func testHandles(t *testing.T, repo service.UserRepository) {
+	ctx := context.Background()
+	ada := create(t, repo, "handle-ada@example.com")
+	bob := create(t, repo, "handle-bob@example.com")
+
+	claim := service.UserPatch{Fields: []string{service.FieldHandle}, Handle: "ada"}
+	ada, err := repo.PatchUser(ctx, ada.ID, ada.Version, claim)
+	require.NoError(t, err)
+
+	got, err := repo.GetUserByHandle(ctx, "ada")
+	require.NoError(t, err)
+	require.NotNil(t, got)
+	assert.Equal(t, ada.ID, got.ID)
+
+	// Taken handles are rejected as conflicts
+	_, err = repo.PatchUser(ctx, bob.ID, bob.Version, claim)
+	var conflict *service.ConflictError
+	require.ErrorAs(t, err, &conflict)
+	assert.Equal(t, "handle", conflict.Field)
+
+	// Former handles resolve until they expire
+	now := time.Now().UTC().Truncate(time.Millisecond)
+	changedAt := now
+	rename := service.UserPatch{
+		Fields:          []string{service.FieldHandle, service.FieldHandleChangedAt, service.FieldFormerHandles},
+		Handle:          "lovelace",
+		HandleChangedAt: &changedAt,
+		FormerHandles:   []model.FormerHandle{{Handle: "ada", Until: now.Add(time.Hour)}},
+	}
+	ada, err = repo.PatchUser(ctx, ada.ID, ada.Version, rename)
+	require.NoError(t, err)
+	assert.Equal(t, "lovelace", ada.Handle)
+	assert.Len(t, ada.FormerHandles, 1)
+
+	got, err = repo.GetUserByHandle(ctx, "ada")
+	require.NoError(t, err)
+	assert.Nil(t, got)
+
+	got, err = repo.GetUserByFormerHandle(ctx, "ada", now)
+	require.NoError(t, err)
+	require.NotNil(t, got)
+	assert.Equal(t, ada.ID, got.ID)
+
+	got, err = repo.GetUserByFormerHandle(ctx, "ada", now.Add(2*time.Hour))
+	require.NoError(t, err)
+	assert.Nil(t, got)
+}
+
+func testFollowCounts(t *testing.T, repo service.UserRepository) {
+	ctx := context.Background()
+	ada := create(t, repo, "follow-ada@example.com")
+	bob := create(t, repo, "follow-bob@example.com")
+
+	require.NoError(t, repo.AdjustFollowCounts(ctx, ada.ID, bob.ID, 1))
+	require.NoError(t, repo.AdjustFollowCounts(ctx, bob.ID, ada.ID, 1))
+	require.NoError(t, repo.AdjustFollowCounts(ctx, ada.ID, bob.ID, -1))
+
+	got, err := repo.GetUserById(ctx, ada.ID)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), got.FollowersCount)
+	assert.Equal(t, int64(0), got.FollowingCount)
+	// Counts are not profile edits
+	assert.Equal(t, ada.Version, got.Version)
+
+	// Profile updates keep the counts
+	got.About = "edited"
+	require.NoError(t, repo.UpdateUser(ctx, got))
+	got, err = repo.GetUserById(ctx, bob.ID)
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), got.FollowersCount)
+	assert.Equal(t, int64(1), got.FollowingCount)
+
+	got, err = repo.GetUserById(ctx, ada.ID)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), got.FollowersCount)
+}
+
+func ids(users []model.User) []uuid.UUID {
+	out := make([]uuid.UUID, 0, len(users))
+	for _, u := range users {
+		out = append(out, u.ID)
+	}
+	return out
+}
@@ -0,0 +1,85 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"github.com/Sayan80bayev/go-project/pkg/logging"
+
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"userService/internal/model"
+	"userService/internal/service"
+)
+
+type MongoRevisionRepository struct {
+	collection *mongo.Collection
+}
+
+func NewRevisionRepository(db *mongo.Database) *MongoRevisionRepository {
+	return &MongoRevisionRepository{
+		collection: db.Collection("user_revisions"),
+	}
+}
+
+// AppendRevision inserts a revision; revisions are never modified afterwards.
+func (r *MongoRevisionRepository) AppendRevision(ctx context.Context, rev *model.UserRevision) error {
+	_, err := r.collection.InsertOne(ctx, rev)
+	return err
+}
+
+// ListRevisions returns up to limit revisions of userID, newest first, starting after the cursor.
+func (r *MongoRevisionRepository) ListRevisions(ctx context.Context, userID uuid.UUID, limit int, after *service.Cursor) ([]model.UserRevision, error) {
+	logger := logging.GetLogger()
+
+	filter := bson.M{"user_id": userID}
+	// Keyset pagination: ties on created_at are broken by _id
+	if after != nil {
+		filter["$or"] = bson.A{
+			bson.M{"created_at": bson.M{"$lt": after.Value}},
+			bson.M{"created_at": after.Value, "_id": bson.M{"$lt": after.ID}},
+		}
+	}
+
+	opts := options.Find().
+		SetSort(bson.D{{Key: "created_at", Value: -1}, {Key: "_id", Value: -1}}).
+		SetLimit(int64(limit))
+
+	cur, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	defer func(cur *mongo.Cursor, ctx context.Context) {
+		if err := cur.Close(ctx); err != nil {
+			logger.Errorf("Couldn't close cursor: %v", err)
+		}
+	}(cur, ctx)
+
+	revisions := make([]model.UserRevision, 0, limit)
+	if err = cur.All(ctx, &revisions); err != nil {
+		return nil, err
+	}
+	return revisions, nil
+}
+
+// GetRevision finds a revision of userID by its ID.
+func (r *MongoRevisionRepository) GetRevision(ctx context.Context, userID, id uuid.UUID) (*model.UserRevision, error) {
+	var rev model.UserRevision
+	err := r.collection.FindOne(ctx, bson.M{"_id": id, "user_id": userID}).Decode(&rev)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &rev, nil
+}
+
+// DeleteRevisions removes the whole history of userID.
+func (r *MongoRevisionRepository) DeleteRevisions(ctx context.Context, userID uuid.UUID) error {
+	_, err := r.collection.DeleteMany(ctx, bson.M{"user_id": userID})
+	return err
+}
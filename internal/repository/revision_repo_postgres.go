@@ -0,0 +1,89 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"userService/internal/model"
+	"userService/internal/service"
+)
+
+const revisionColumns = `id, user_id, version, action, changes, actor, source, created_at`
+
+// PostgresRevisionRepository stores profile history in the user_revisions table.
+type PostgresRevisionRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewPostgresRevisionRepository(pool *pgxpool.Pool) *PostgresRevisionRepository {
+	return &PostgresRevisionRepository{pool: pool}
+}
+
+// AppendRevision inserts a revision; revisions are never modified afterwards.
+func (r *PostgresRevisionRepository) AppendRevision(ctx context.Context, rev *model.UserRevision) error {
+	_, err := pgConn(ctx, r.pool).Exec(ctx, `INSERT INTO user_revisions (`+revisionColumns+`)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`,
+		rev.ID, rev.UserID, rev.Version, rev.Action, rev.Changes, rev.Actor, rev.Source, rev.CreatedAt,
+	)
+	return err
+}
+
+// ListRevisions returns up to limit revisions of userID, newest first, starting after the cursor.
+func (r *PostgresRevisionRepository) ListRevisions(ctx context.Context, userID uuid.UUID, limit int, after *service.Cursor) ([]model.UserRevision, error) {
+	sql := `SELECT ` + revisionColumns + ` FROM user_revisions WHERE user_id = $1`
+	args := []any{userID, limit}
+	if after != nil {
+		sql += ` AND (created_at, id) < ($3, $4)`
+		args = append(args, after.Value, after.ID)
+	}
+	sql += ` ORDER BY created_at DESC, id DESC LIMIT $2`
+
+	rows, err := pgConn(ctx, r.pool).Query(ctx, sql, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	revisions := make([]model.UserRevision, 0, limit)
+	for rows.Next() {
+		rev, err := scanRevision(rows)
+		if err != nil {
+			return nil, err
+		}
+		revisions = append(revisions, *rev)
+	}
+	return revisions, rows.Err()
+}
+
+// GetRevision finds a revision of userID by its ID.
+func (r *PostgresRevisionRepository) GetRevision(ctx context.Context, userID, id uuid.UUID) (*model.UserRevision, error) {
+	rev, err := scanRevision(pgConn(ctx, r.pool).QueryRow(ctx,
+		`SELECT `+revisionColumns+` FROM user_revisions WHERE id = $1 AND user_id = $2`, id, userID))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	return rev, err
+}
+
+// DeleteRevisions removes the whole history of userID.
+func (r *PostgresRevisionRepository) DeleteRevisions(ctx context.Context, userID uuid.UUID) error {
+	_, err := pgConn(ctx, r.pool).Exec(ctx, `DELETE FROM user_revisions WHERE user_id = $1`, userID)
+	return err
+}
+
+// scanRevision reads a row selected with revisionColumns.
+func scanRevision(row pgx.Row) (*model.UserRevision, error) {
+	var rev model.UserRevision
+	err := row.Scan(
+		&rev.ID, &rev.UserID, &rev.Version, &rev.Action, &rev.Changes,
+		&rev.Actor, &rev.Source, &rev.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &rev, nil
+}
@@ -0,0 +1,57 @@
+package repository
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// MongoTransactor runs service writes in multi-document transactions, which
+// need MongoDB to run as a replica set.
+type MongoTransactor struct {
+	client *mongo.Client
+}
+
+func NewMongoTransactor(client *mongo.Client) *MongoTransactor {
+	return &MongoTransactor{client: client}
+}
+
+// MongoSupportsTransactions reports whether client is connected to a replica
+// set or a sharded cluster, the deployments that run transactions.
+func MongoSupportsTransactions(ctx context.Context, client *mongo.Client) (bool, error) {
+	var hello struct {
+		SetName string `bson:"setName"`
+		Msg     string `bson:"msg"`
+	}
+	err := client.Database("admin").RunCommand(ctx, bson.D{{Key: "hello", Value: 1}}).Decode(&hello)
+	if err != nil {
+		return false, err
+	}
+	return hello.SetName != "" || hello.Msg == "isdbgrid", nil
+}
+
+// WithinTransaction commits fn's writes atomically. Nested calls join the outer transaction.
+func (t *MongoTransactor) WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	if mongo.SessionFromContext(ctx) != nil {
+		return fn(ctx)
+	}
+
+	sess, err := t.client.StartSession()
+	if err != nil {
+		return err
+	}
+	defer sess.EndSession(context.WithoutCancel(ctx))
+
+	// The callback is not retried as a whole: it may have side effects on its arguments
+	return mongo.WithSession(ctx, sess, func(sc mongo.SessionContext) error {
+		if err := sess.StartTransaction(); err != nil {
+			return err
+		}
+		if err := fn(sc); err != nil {
+			_ = sess.AbortTransaction(context.WithoutCancel(sc))
+			return err
+		}
+		return sess.CommitTransaction(sc)
+	})
+}
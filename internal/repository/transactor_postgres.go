@@ -0,0 +1,45 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// pgQuerier is what the Postgres repositories need from a pool or a transaction.
+type pgQuerier interface {
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+}
+
+type pgTxKey struct{}
+
+// pgConn returns the transaction started by PostgresTransactor for ctx, or pool outside of one.
+func pgConn(ctx context.Context, pool *pgxpool.Pool) pgQuerier {
+	if tx, ok := ctx.Value(pgTxKey{}).(pgx.Tx); ok {
+		return tx
+	}
+	return pool
+}
+
+// PostgresTransactor runs service writes in a single Postgres transaction.
+type PostgresTransactor struct {
+	pool *pgxpool.Pool
+}
+
+func NewPostgresTransactor(pool *pgxpool.Pool) *PostgresTransactor {
+	return &PostgresTransactor{pool: pool}
+}
+
+// WithinTransaction commits fn's writes atomically. Nested calls join the outer transaction.
+func (t *PostgresTransactor) WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	if _, ok := ctx.Value(pgTxKey{}).(pgx.Tx); ok {
+		return fn(ctx)
+	}
+	return pgx.BeginFunc(ctx, t.pool, func(tx pgx.Tx) error {
+		return fn(context.WithValue(ctx, pgTxKey{}, tx))
+	})
+}
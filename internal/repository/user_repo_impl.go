@@ -4,13 +4,18 @@ import (
 	"context"
 	"errors"
 	"github.com/Sayan80bayev/go-project/pkg/logging"
+	"regexp"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 
+	"userService/internal/migrations"
 	"userService/internal/model"
+	"userService/internal/service"
 )
 
 type MongoUserRepository struct {
@@ -31,21 +36,36 @@ func (r *MongoUserRepository) CreateUser(ctx context.Context, user *model.User)
 	now := time.Now().UTC()
 	user.CreatedAt = now
 	user.UpdatedAt = now
+	user.Version = 1
 
 	_, err := r.collection.InsertOne(ctx, user)
 	if mongo.IsDuplicateKeyError(err) {
-		return errors.New("user already exists")
+		return duplicateKeyConflict(err)
 	}
 	return err
 }
 
+// duplicateKeyConflict tells which unique index rejected a write.
+func duplicateKeyConflict(err error) error {
+	if strings.Contains(err.Error(), migrations.UsersEmailIndex) {
+		return &service.ConflictError{Field: "email"}
+	}
+	if strings.Contains(err.Error(), migrations.UsersHandleIndex) {
+		return &service.ConflictError{Field: "handle"}
+	}
+	return &service.ConflictError{Field: "id"}
+}
+
 // UpdateUser updates mutable fields and sets UpdatedAt timestamp.
+// The write only succeeds if the stored version still equals user.Version,
+// which is then incremented.
 func (r *MongoUserRepository) UpdateUser(ctx context.Context, user *model.User) error {
-	user.UpdatedAt = time.Now().UTC()
+	updatedAt := time.Now().UTC()
 
 	filter := bson.M{
 		"_id":        user.ID,
 		"deleted_at": bson.M{"$exists": false},
+		"version":    user.Version,
 	}
 	update := bson.M{"$set": bson.M{
 		"firstname":     user.Firstname,
@@ -57,19 +77,86 @@ func (r *MongoUserRepository) UpdateUser(ctx context.Context, user *model.User)
 		"gender":        user.Gender,
 		"location":      user.Location,
 		"socials":       user.Socials,
-		"updated_at":    user.UpdatedAt,
-	}}
+		"updated_at":    updatedAt,
+		// Maintained by the service's completion policy
+		"needs_completion": user.NeedsCompletion,
+		"completed_at":     user.CompletedAt,
+	}, "$inc": bson.M{"version": 1}}
 
 	res, err := r.collection.UpdateOne(ctx, filter, update)
+	if mongo.IsDuplicateKeyError(err) {
+		return duplicateKeyConflict(err)
+	}
 	if err != nil {
 		return err
 	}
 	if res.MatchedCount == 0 {
-		return errors.New("user not found or deleted")
+		return r.missedUpdateError(ctx, user.ID)
 	}
+
+	user.UpdatedAt = updatedAt
+	user.Version++
 	return nil
 }
 
+// PatchUser sets or unsets only the fields named in patch, guarded by version
+// like UpdateUser, and returns the updated document.
+func (r *MongoUserRepository) PatchUser(ctx context.Context, id uuid.UUID, version int64, patch service.UserPatch) (*model.User, error) {
+	set := bson.M{"updated_at": time.Now().UTC()}
+	unset := bson.M{}
+	for _, field := range patch.Fields {
+		if value, ok := patch.Value(field); ok {
+			set[field] = value
+		} else {
+			unset[field] = ""
+		}
+	}
+
+	update := bson.M{"$set": set, "$inc": bson.M{"version": 1}}
+	if len(unset) > 0 {
+		update["$unset"] = unset
+	}
+	filter := bson.M{
+		"_id":        id,
+		"deleted_at": bson.M{"$exists": false},
+		"version":    version,
+	}
+
+	var user model.User
+	err := r.collection.FindOneAndUpdate(ctx, filter, update,
+		options.FindOneAndUpdate().SetReturnDocument(options.After),
+	).Decode(&user)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, r.missedUpdateError(ctx, id)
+	}
+	if mongo.IsDuplicateKeyError(err) {
+		return nil, duplicateKeyConflict(err)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// missedUpdateError explains why a guarded write matched nothing: the document
+// is missing, soft-deleted, or lost a version race.
+func (r *MongoUserRepository) missedUpdateError(ctx context.Context, id uuid.UUID) error {
+	var user model.User
+	err := r.collection.FindOne(ctx, bson.M{"_id": id},
+		options.FindOne().SetProjection(bson.M{"deleted_at": 1}),
+	).Decode(&user)
+	switch {
+	case errors.Is(err, mongo.ErrNoDocuments):
+		return service.ErrUserNotFound
+	case err != nil:
+		return err
+	case user.DeletedAt != nil:
+		return service.ErrUserDeleted
+	default:
+		return service.ErrVersionConflict
+	}
+}
+
 // DeleteUserById performs a soft delete by setting DeletedAt timestamp.
 func (r *MongoUserRepository) DeleteUserById(ctx context.Context, userId uuid.UUID) error {
 	filter := bson.M{
@@ -85,22 +172,106 @@ func (r *MongoUserRepository) DeleteUserById(ctx context.Context, userId uuid.UU
 		return err
 	}
 	if res.MatchedCount == 0 {
-		return errors.New("user not found or already deleted")
+		return r.missedUpdateError(ctx, userId)
 	}
 	return nil
 }
 
-// GetAllUsers returns all non-deleted users.
-func (r *MongoUserRepository) GetAllUsers(ctx context.Context) ([]model.User, error) {
+// RestoreUser clears DeletedAt of a user deleted at or after deletedSince.
+func (r *MongoUserRepository) RestoreUser(ctx context.Context, id uuid.UUID, deletedSince time.Time) error {
+	filter := bson.M{
+		"_id":        id,
+		"deleted_at": bson.M{"$gte": deletedSince},
+	}
+	update := bson.M{
+		"$unset": bson.M{"deleted_at": ""},
+		"$set":   bson.M{"updated_at": time.Now().UTC()},
+		"$inc":   bson.M{"version": 1},
+	}
+
+	res, err := r.collection.UpdateOne(ctx, filter, update)
+	if mongo.IsDuplicateKeyError(err) {
+		// A live profile took the email meanwhile
+		return duplicateKeyConflict(err)
+	}
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount > 0 {
+		return nil
+	}
+
+	// Explain why nothing matched
+	var user model.User
+	err = r.collection.FindOne(ctx, bson.M{"_id": id}).Decode(&user)
+	switch {
+	case errors.Is(err, mongo.ErrNoDocuments):
+		return service.ErrUserNotFound
+	case err != nil:
+		return err
+	case user.DeletedAt == nil:
+		return service.ErrNotDeleted
+	default:
+		return service.ErrRestoreExpired
+	}
+}
+
+// ListDeletedBefore returns up to limit users soft-deleted before cutoff, oldest first.
+func (r *MongoUserRepository) ListDeletedBefore(ctx context.Context, cutoff time.Time, limit int) ([]model.User, error) {
 	logger := logging.GetLogger()
-	cur, err := r.collection.Find(ctx, bson.M{"deleted_at": bson.M{"$exists": false}})
+
+	opts := options.Find().
+		SetSort(bson.D{{Key: "deleted_at", Value: 1}}).
+		SetLimit(int64(limit))
+	cur, err := r.collection.Find(ctx, bson.M{"deleted_at": bson.M{"$lt": cutoff}}, opts)
 	if err != nil {
 		return nil, err
 	}
 
 	defer func(cur *mongo.Cursor, ctx context.Context) {
-		err = cur.Close(ctx)
-		if err != nil {
+		if err := cur.Close(ctx); err != nil {
+			logger.Errorf("Couldn't close cursor: %v", err)
+		}
+	}(cur, ctx)
+
+	users := make([]model.User, 0, limit)
+	if err = cur.All(ctx, &users); err != nil {
+		return nil, err
+	}
+	return users, nil
+}
+
+// PurgeUser hard-deletes a user that is still soft-deleted before cutoff and
+// reports whether a document was removed.
+func (r *MongoUserRepository) PurgeUser(ctx context.Context, id uuid.UUID, cutoff time.Time) (bool, error) {
+	res, err := r.collection.DeleteOne(ctx, bson.M{
+		"_id":        id,
+		"deleted_at": bson.M{"$lt": cutoff},
+	})
+	if err != nil {
+		return false, err
+	}
+	return res.DeletedCount > 0, nil
+}
+
+// ListModerationExpiredBefore returns up to limit live users whose
+// restriction expires before cutoff, soonest first.
+func (r *MongoUserRepository) ListModerationExpiredBefore(ctx context.Context, cutoff time.Time, limit int) ([]model.User, error) {
+	logger := logging.GetLogger()
+	filter := bson.M{
+		"moderation.expires_at": bson.M{"$lt": cutoff},
+		"deleted_at":            bson.M{"$exists": false},
+	}
+	opts := options.Find().
+		SetSort(bson.D{{Key: "moderation.expires_at", Value: 1}}).
+		SetLimit(int64(limit))
+
+	cur, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer func(cur *mongo.Cursor, ctx context.Context) {
+		if err := cur.Close(ctx); err != nil {
 			logger.Errorf("Couldn't close cursor: %v", err)
 		}
 	}(cur, ctx)
@@ -112,7 +283,137 @@ func (r *MongoUserRepository) GetAllUsers(ctx context.Context) ([]model.User, er
 	return users, nil
 }
 
-// GetUserById finds a non-deleted user by ID.
+// ListUsers returns up to q.Limit non-deleted users ordered by q.SortField, starting after q.After.
+func (r *MongoUserRepository) ListUsers(ctx context.Context, q service.ListQuery) ([]model.User, error) {
+	logger := logging.GetLogger()
+
+	filter := userFilterToBson(q.Filter)
+	dir, op := 1, "$gt"
+	if q.Desc {
+		dir, op = -1, "$lt"
+	}
+
+	// Keyset pagination: ties on the sort field are broken by _id
+	if q.After != nil {
+		filter["$or"] = bson.A{
+			bson.M{q.SortField: bson.M{op: q.After.Value}},
+			bson.M{q.SortField: q.After.Value, "_id": bson.M{op: q.After.ID}},
+		}
+	}
+
+	opts := options.Find().
+		SetSort(bson.D{{Key: q.SortField, Value: dir}, {Key: "_id", Value: dir}}).
+		SetLimit(int64(q.Limit))
+
+	cur, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	defer func(cur *mongo.Cursor, ctx context.Context) {
+		if err := cur.Close(ctx); err != nil {
+			logger.Errorf("Couldn't close cursor: %v", err)
+		}
+	}(cur, ctx)
+
+	users := make([]model.User, 0, q.Limit)
+	if err = cur.All(ctx, &users); err != nil {
+		return nil, err
+	}
+	return users, nil
+}
+
+// SearchUsers returns non-deleted users matching q.Text ordered by text score.
+func (r *MongoUserRepository) SearchUsers(ctx context.Context, q service.SearchQuery) ([]model.User, error) {
+	logger := logging.GetLogger()
+
+	filter := bson.M{
+		"$text":      bson.M{"$search": q.Text},
+		"deleted_at": bson.M{"$exists": false},
+	}
+	if !q.IncludeModerated {
+		filter["moderation"] = bson.M{"$exists": false}
+	}
+	if len(q.ExcludeIDs) > 0 {
+		filter["_id"] = bson.M{"$nin": q.ExcludeIDs}
+	}
+	score := bson.M{"$meta": "textScore"}
+	opts := options.Find().
+		SetProjection(bson.M{"score": score}).
+		SetSort(bson.D{{Key: "score", Value: score}, {Key: "_id", Value: 1}}).
+		SetSkip(int64(q.Offset)).
+		SetLimit(int64(q.Limit))
+
+	cur, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	defer func(cur *mongo.Cursor, ctx context.Context) {
+		if err := cur.Close(ctx); err != nil {
+			logger.Errorf("Couldn't close cursor: %v", err)
+		}
+	}(cur, ctx)
+
+	users := make([]model.User, 0, q.Limit)
+	if err = cur.All(ctx, &users); err != nil {
+		return nil, err
+	}
+	return users, nil
+}
+
+// userFilterToBson translates a service filter into a query on non-deleted users.
+func userFilterToBson(f service.UserFilter) bson.M {
+	filter := bson.M{"deleted_at": bson.M{"$exists": false}}
+
+	if f.Gender != "" {
+		filter["gender"] = f.Gender
+	}
+	if f.Location != "" {
+		filter["location"] = bson.M{"$regex": "^" + regexp.QuoteMeta(f.Location) + "$", "$options": "i"}
+	}
+	if f.NeedsCompletion != nil {
+		filter["needs_completion"] = *f.NeedsCompletion
+	}
+	if f.HasAvatar != nil {
+		// $in/$nin with nil also match documents where the field is missing
+		if *f.HasAvatar {
+			filter["avatar_url"] = bson.M{"$nin": bson.A{nil, ""}}
+		} else {
+			filter["avatar_url"] = bson.M{"$in": bson.A{nil, ""}}
+		}
+	}
+	if r := timeRange(f.CreatedFrom, f.CreatedTo); r != nil {
+		filter["created_at"] = r
+	}
+	if r := timeRange(f.UpdatedFrom, f.UpdatedTo); r != nil {
+		filter["updated_at"] = r
+	}
+	if f.Moderated != nil {
+		filter["moderation"] = bson.M{"$exists": *f.Moderated}
+	}
+	if len(f.ExcludeIDs) > 0 {
+		filter["_id"] = bson.M{"$nin": f.ExcludeIDs}
+	}
+
+	return filter
+}
+
+func timeRange(from, to *time.Time) bson.M {
+	if from == nil && to == nil {
+		return nil
+	}
+	r := bson.M{}
+	if from != nil {
+		r["$gte"] = *from
+	}
+	if to != nil {
+		r["$lte"] = *to
+	}
+	return r
+}
+
+// GetUserById finds a user by ID, soft-deleted ones included; callers decide how to treat DeletedAt.
 func (r *MongoUserRepository) GetUserById(ctx context.Context, id uuid.UUID) (*model.User, error) {
 	var user model.User
 	err := r.collection.FindOne(ctx, bson.M{
@@ -123,3 +424,82 @@ func (r *MongoUserRepository) GetUserById(ctx context.Context, id uuid.UUID) (*m
 	}
 	return &user, err
 }
+
+// GetUserByEmail finds a user by normalized email, soft-deleted ones included.
+// The live profile holding the email comes before deleted ones.
+func (r *MongoUserRepository) GetUserByEmail(ctx context.Context, email string) (*model.User, error) {
+	var user model.User
+	// Missing deleted_at sorts first
+	err := r.collection.FindOne(ctx, bson.M{
+		"email": email,
+	}, options.FindOne().SetSort(bson.D{{Key: "deleted_at", Value: 1}})).Decode(&user)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, nil
+	}
+	return &user, err
+}
+
+// AdjustFollowCounts adds delta to the following count of followerID and the
+// followers count of followeeID. Versions are left alone: counts are not
+// profile edits.
+func (r *MongoUserRepository) AdjustFollowCounts(ctx context.Context, followerID, followeeID uuid.UUID, delta int64) error {
+	if _, err := r.collection.UpdateByID(ctx, followerID, bson.M{"$inc": bson.M{"following_count": delta}}); err != nil {
+		return err
+	}
+	_, err := r.collection.UpdateByID(ctx, followeeID, bson.M{"$inc": bson.M{"followers_count": delta}})
+	return err
+}
+
+// GetUserByHandle finds a user by current handle, soft-deleted ones included.
+func (r *MongoUserRepository) GetUserByHandle(ctx context.Context, handle string) (*model.User, error) {
+	var user model.User
+	err := r.collection.FindOne(ctx, bson.M{
+		"handle": handle,
+	}).Decode(&user)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, nil
+	}
+	return &user, err
+}
+
+// GetUserByFormerHandle finds the live user that gave up handle and whose
+// redirect is still in effect at now.
+func (r *MongoUserRepository) GetUserByFormerHandle(ctx context.Context, handle string, now time.Time) (*model.User, error) {
+	var user model.User
+	err := r.collection.FindOne(ctx, bson.M{
+		"deleted_at": bson.M{"$exists": false},
+		"former_handles": bson.M{"$elemMatch": bson.M{
+			"handle": handle,
+			"until":  bson.M{"$gt": now},
+		}},
+	}).Decode(&user)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, nil
+	}
+	return &user, err
+}
+
+// GetUsersByIds finds the non-deleted users among ids with a single $in query.
+func (r *MongoUserRepository) GetUsersByIds(ctx context.Context, ids []uuid.UUID) ([]model.User, error) {
+	logger := logging.GetLogger()
+
+	cur, err := r.collection.Find(ctx, bson.M{
+		"_id":        bson.M{"$in": ids},
+		"deleted_at": bson.M{"$exists": false},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	defer func(cur *mongo.Cursor, ctx context.Context) {
+		if err := cur.Close(ctx); err != nil {
+			logger.Errorf("Couldn't close cursor: %v", err)
+		}
+	}(cur, ctx)
+
+	users := make([]model.User, 0, len(ids))
+	if err = cur.All(ctx, &users); err != nil {
+		return nil, err
+	}
+	return users, nil
+}
@@ -0,0 +1,484 @@
+package repository
+
+import (
+	"bytes"
+	"context"
+	"maps"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"github.com/google/uuid"
+
+	"userService/internal/model"
+	"userService/internal/service"
+)
+
+// searchWeights mirrors the weights of the users_text_search index.
+var searchWeights = map[string]int{
+	"firstname": 10,
+	"lastname":  10,
+	"location":  3,
+	"about":     1,
+}
+
+// MemoryUserRepository is a thread-safe in-memory service.UserRepository for
+// tests and local runs. It follows the Mongo repository's semantics, including
+// soft deletes, versions and email and handle uniqueness. Like the database
+// indexes, only live profiles reserve their email.
+type MemoryUserRepository struct {
+	mu    sync.RWMutex
+	users map[uuid.UUID]*model.User
+}
+
+func NewMemoryUserRepository() *MemoryUserRepository {
+	return &MemoryUserRepository{users: map[uuid.UUID]*model.User{}}
+}
+
+// CreateUser stores a copy of user, setting CreatedAt/UpdatedAt and Version.
+func (r *MemoryUserRepository) CreateUser(_ context.Context, user *model.User) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if user.ID == uuid.Nil {
+		user.ID = uuid.New()
+	}
+	if _, ok := r.users[user.ID]; ok {
+		return &service.ConflictError{Field: "id"}
+	}
+	if user.Email != "" && r.findByEmail(user.Email) != nil {
+		return &service.ConflictError{Field: "email"}
+	}
+	if user.Handle != "" && r.findByHandle(user.Handle) != nil {
+		return &service.ConflictError{Field: "handle"}
+	}
+
+	now := time.Now().UTC()
+	user.CreatedAt = now
+	user.UpdatedAt = now
+	user.Version = 1
+
+	r.users[user.ID] = cloneUser(user)
+	return nil
+}
+
+// UpdateUser updates mutable fields if the stored version still equals user.Version.
+func (r *MemoryUserRepository) UpdateUser(_ context.Context, user *model.User) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, err := r.guarded(user.ID, user.Version)
+	if err != nil {
+		return err
+	}
+	if user.Email != "" && user.Email != stored.Email {
+		if other := r.findByEmail(user.Email); other != nil && other.ID != user.ID {
+			return &service.ConflictError{Field: "email"}
+		}
+	}
+
+	updatedAt := time.Now().UTC()
+	stored.Firstname = user.Firstname
+	stored.Lastname = user.Lastname
+	stored.Email = user.Email
+	stored.About = user.About
+	stored.DateOfBirth = cloneTime(user.DateOfBirth)
+	stored.AvatarURL = user.AvatarURL
+	stored.Gender = user.Gender
+	stored.Location = user.Location
+	stored.Socials = slices.Clone(user.Socials)
+	stored.NeedsCompletion = user.NeedsCompletion
+	stored.CompletedAt = cloneTime(user.CompletedAt)
+	stored.UpdatedAt = updatedAt
+	stored.Version++
+
+	user.UpdatedAt = updatedAt
+	user.Version++
+	return nil
+}
+
+// PatchUser sets or unsets only the fields named in patch, guarded by version.
+func (r *MemoryUserRepository) PatchUser(_ context.Context, id uuid.UUID, version int64, patch service.UserPatch) (*model.User, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, err := r.guarded(id, version)
+	if err != nil {
+		return nil, err
+	}
+	if patch.Handle != "" && slices.Contains(patch.Fields, service.FieldHandle) {
+		if other := r.findByHandle(patch.Handle); other != nil && other.ID != id {
+			return nil, &service.ConflictError{Field: "handle"}
+		}
+	}
+
+	service.ApplyPatch(stored, patch)
+	stored.UpdatedAt = time.Now().UTC()
+	stored.Version++
+
+	return cloneUser(stored), nil
+}
+
+// guarded returns the live user id if it is at version, or the error a
+// guarded database update would have produced.
+func (r *MemoryUserRepository) guarded(id uuid.UUID, version int64) (*model.User, error) {
+	stored, err := r.live(id)
+	if err != nil {
+		return nil, err
+	}
+	if stored.Version != version {
+		return nil, service.ErrVersionConflict
+	}
+	return stored, nil
+}
+
+// live returns the stored user id unless it is missing or soft-deleted.
+func (r *MemoryUserRepository) live(id uuid.UUID) (*model.User, error) {
+	stored, ok := r.users[id]
+	switch {
+	case !ok:
+		return nil, service.ErrUserNotFound
+	case stored.DeletedAt != nil:
+		return nil, service.ErrUserDeleted
+	}
+	return stored, nil
+}
+
+// DeleteUserById soft-deletes a user by setting DeletedAt.
+func (r *MemoryUserRepository) DeleteUserById(_ context.Context, userId uuid.UUID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, err := r.live(userId)
+	if err != nil {
+		return err
+	}
+	now := time.Now().UTC()
+	stored.DeletedAt = &now
+	return nil
+}
+
+// RestoreUser clears DeletedAt of a user deleted at or after deletedSince.
+func (r *MemoryUserRepository) RestoreUser(_ context.Context, id uuid.UUID, deletedSince time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, ok := r.users[id]
+	switch {
+	case !ok:
+		return service.ErrUserNotFound
+	case stored.DeletedAt == nil:
+		return service.ErrNotDeleted
+	case stored.DeletedAt.Before(deletedSince):
+		return service.ErrRestoreExpired
+	}
+	if stored.Email != "" && r.findByEmail(stored.Email) != nil {
+		return &service.ConflictError{Field: "email"}
+	}
+
+	stored.DeletedAt = nil
+	stored.UpdatedAt = time.Now().UTC()
+	stored.Version++
+	return nil
+}
+
+// ListDeletedBefore returns up to limit users soft-deleted before cutoff, oldest first.
+func (r *MemoryUserRepository) ListDeletedBefore(_ context.Context, cutoff time.Time, limit int) ([]model.User, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	users := r.collect(func(u *model.User) bool {
+		return u.DeletedAt != nil && u.DeletedAt.Before(cutoff)
+	})
+	sort.Slice(users, func(i, j int) bool { return users[i].DeletedAt.Before(*users[j].DeletedAt) })
+	return head(users, limit), nil
+}
+
+// PurgeUser hard-deletes a user that is still soft-deleted before cutoff.
+func (r *MemoryUserRepository) PurgeUser(_ context.Context, id uuid.UUID, cutoff time.Time) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, ok := r.users[id]
+	if !ok || stored.DeletedAt == nil || !stored.DeletedAt.Before(cutoff) {
+		return false, nil
+	}
+	delete(r.users, id)
+	return true, nil
+}
+
+// ListModerationExpiredBefore returns up to limit live users whose
+// restriction expires before cutoff, soonest first.
+func (r *MemoryUserRepository) ListModerationExpiredBefore(_ context.Context, cutoff time.Time, limit int) ([]model.User, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	users := r.collect(func(u *model.User) bool {
+		return isLive(u) && u.Moderation != nil && u.Moderation.ExpiresAt != nil && u.Moderation.ExpiresAt.Before(cutoff)
+	})
+	sort.Slice(users, func(i, j int) bool {
+		return users[i].Moderation.ExpiresAt.Before(*users[j].Moderation.ExpiresAt)
+	})
+	return head(users, limit), nil
+}
+
+// ListUsers returns up to q.Limit non-deleted users ordered by q.SortField, starting after q.After.
+func (r *MemoryUserRepository) ListUsers(_ context.Context, q service.ListQuery) ([]model.User, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	// cmp orders by the sort field, ties broken by ID, honoring direction
+	cmp := func(t time.Time, id uuid.UUID, u *model.User) int {
+		c := sortValue(u, q.SortField).Compare(t)
+		if c == 0 {
+			c = bytes.Compare(u.ID[:], id[:])
+		}
+		if q.Desc {
+			c = -c
+		}
+		return c
+	}
+
+	users := r.collect(func(u *model.User) bool {
+		if !isLive(u) || !matchesFilter(u, q.Filter) {
+			return false
+		}
+		return q.After == nil || cmp(q.After.Value, q.After.ID, u) > 0
+	})
+	sort.Slice(users, func(i, j int) bool {
+		return cmp(sortValue(&users[j], q.SortField), users[j].ID, &users[i]) < 0
+	})
+	return head(users, q.Limit), nil
+}
+
+// SearchUsers returns non-deleted users containing any word of q.Text,
+// ranked like the weighted text index.
+func (r *MemoryUserRepository) SearchUsers(_ context.Context, q service.SearchQuery) ([]model.User, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	terms := words(q.Text)
+	scores := map[uuid.UUID]int{}
+	users := r.collect(func(u *model.User) bool {
+		if !isLive(u) || (u.Moderation != nil && !q.IncludeModerated) || slices.Contains(q.ExcludeIDs, u.ID) {
+			return false
+		}
+		score := 0
+		for field, text := range map[string]string{
+			"firstname": u.Firstname, "lastname": u.Lastname, "location": u.Location, "about": u.About,
+		} {
+			for _, w := range words(text) {
+				if slices.Contains(terms, w) {
+					score += searchWeights[field]
+				}
+			}
+		}
+		scores[u.ID] = score
+		return score > 0
+	})
+	sort.Slice(users, func(i, j int) bool {
+		si, sj := scores[users[i].ID], scores[users[j].ID]
+		if si != sj {
+			return si > sj
+		}
+		return bytes.Compare(users[i].ID[:], users[j].ID[:]) < 0
+	})
+
+	if q.Offset >= len(users) {
+		return []model.User{}, nil
+	}
+	return head(users[q.Offset:], q.Limit), nil
+}
+
+// GetUserById finds a user by ID, soft-deleted ones included.
+func (r *MemoryUserRepository) GetUserById(_ context.Context, id uuid.UUID) (*model.User, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if stored, ok := r.users[id]; ok {
+		return cloneUser(stored), nil
+	}
+	return nil, nil
+}
+
+// GetUserByEmail finds a user by normalized email, soft-deleted ones included.
+func (r *MemoryUserRepository) GetUserByEmail(_ context.Context, email string) (*model.User, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if stored := r.findByEmail(email); stored != nil {
+		return cloneUser(stored), nil
+	}
+	for _, u := range r.users {
+		if u.Email == email {
+			return cloneUser(u), nil
+		}
+	}
+	return nil, nil
+}
+
+// AdjustFollowCounts adds delta to the following count of followerID and the
+// followers count of followeeID without bumping versions.
+func (r *MemoryUserRepository) AdjustFollowCounts(_ context.Context, followerID, followeeID uuid.UUID, delta int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if u, ok := r.users[followerID]; ok {
+		u.FollowingCount += delta
+	}
+	if u, ok := r.users[followeeID]; ok {
+		u.FollowersCount += delta
+	}
+	return nil
+}
+
+// GetUserByHandle finds a user by current handle, soft-deleted ones included.
+func (r *MemoryUserRepository) GetUserByHandle(_ context.Context, handle string) (*model.User, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if stored := r.findByHandle(handle); stored != nil {
+		return cloneUser(stored), nil
+	}
+	return nil, nil
+}
+
+// GetUserByFormerHandle finds the live user that gave up handle and whose
+// redirect is still in effect at now.
+func (r *MemoryUserRepository) GetUserByFormerHandle(_ context.Context, handle string, now time.Time) (*model.User, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, u := range r.users {
+		if !isLive(u) {
+			continue
+		}
+		for _, former := range u.FormerHandles {
+			if former.Handle == handle && former.Until.After(now) {
+				return cloneUser(u), nil
+			}
+		}
+	}
+	return nil, nil
+}
+
+// GetUsersByIds returns the non-deleted users among ids.
+func (r *MemoryUserRepository) GetUsersByIds(_ context.Context, ids []uuid.UUID) ([]model.User, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	users := make([]model.User, 0, len(ids))
+	for _, id := range ids {
+		if stored, ok := r.users[id]; ok && isLive(stored) {
+			users = append(users, *cloneUser(stored))
+		}
+	}
+	return users, nil
+}
+
+// findByEmail returns the live user holding email; only live profiles
+// reserve their email.
+func (r *MemoryUserRepository) findByEmail(email string) *model.User {
+	for _, u := range r.users {
+		if u.Email == email && u.DeletedAt == nil {
+			return u
+		}
+	}
+	return nil
+}
+
+func (r *MemoryUserRepository) findByHandle(handle string) *model.User {
+	for _, u := range r.users {
+		if u.Handle == handle {
+			return u
+		}
+	}
+	return nil
+}
+
+// collect returns copies of the users keep accepts.
+func (r *MemoryUserRepository) collect(keep func(*model.User) bool) []model.User {
+	users := make([]model.User, 0)
+	for _, u := range r.users {
+		if keep(u) {
+			users = append(users, *cloneUser(u))
+		}
+	}
+	return users
+}
+
+func isLive(u *model.User) bool {
+	return u.DeletedAt == nil
+}
+
+func matchesFilter(u *model.User, f service.UserFilter) bool {
+	switch {
+	case f.Gender != "" && u.Gender != f.Gender:
+		return false
+	case f.Location != "" && !strings.EqualFold(u.Location, f.Location):
+		return false
+	case f.NeedsCompletion != nil && u.NeedsCompletion != *f.NeedsCompletion:
+		return false
+	case f.HasAvatar != nil && (u.AvatarURL != "") != *f.HasAvatar:
+		return false
+	case f.Moderated != nil && (u.Moderation != nil) != *f.Moderated:
+		return false
+	case slices.Contains(f.ExcludeIDs, u.ID):
+		return false
+	}
+	return inRange(u.CreatedAt, f.CreatedFrom, f.CreatedTo) && inRange(u.UpdatedAt, f.UpdatedFrom, f.UpdatedTo)
+}
+
+func inRange(t time.Time, from, to *time.Time) bool {
+	return (from == nil || !t.Before(*from)) && (to == nil || !t.After(*to))
+}
+
+func sortValue(u *model.User, field string) time.Time {
+	if field == service.SortUpdatedAt {
+		return u.UpdatedAt
+	}
+	return u.CreatedAt
+}
+
+// words lower-cases text and splits it on anything but letters and digits.
+func words(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+func head(users []model.User, limit int) []model.User {
+	if limit > 0 && len(users) > limit {
+		return users[:limit]
+	}
+	return users
+}
+
+func cloneUser(u *model.User) *model.User {
+	c := *u
+	c.DeletedAt = cloneTime(u.DeletedAt)
+	c.DateOfBirth = cloneTime(u.DateOfBirth)
+	c.CompletedAt = cloneTime(u.CompletedAt)
+	if u.Moderation != nil {
+		m := *u.Moderation
+		m.ExpiresAt = cloneTime(u.Moderation.ExpiresAt)
+		c.Moderation = &m
+	}
+	c.Socials = slices.Clone(u.Socials)
+	c.Roles = slices.Clone(u.Roles)
+	c.Privacy = maps.Clone(u.Privacy)
+	c.HandleChangedAt = cloneTime(u.HandleChangedAt)
+	c.FormerHandles = slices.Clone(u.FormerHandles)
+	return &c
+}
+
+func cloneTime(t *time.Time) *time.Time {
+	if t == nil {
+		return nil
+	}
+	c := *t
+	return &c
+}
@@ -0,0 +1,15 @@
+package repository_test
+
+import (
+	"testing"
+
+	"userService/internal/repository"
+	"userService/internal/repository/repotest"
+	"userService/internal/service"
+)
+
+func TestMemoryUserRepository_Conformance(t *testing.T) {
+	repotest.RunUserRepository(t, func(t *testing.T) service.UserRepository {
+		return repository.NewMemoryUserRepository()
+	})
+}
@@ -0,0 +1,433 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"userService/internal/migrations"
+	"userService/internal/model"
+	"userService/internal/service"
+)
+
+// pgUniqueViolation is the SQLSTATE of a unique constraint violation.
+const pgUniqueViolation = "23505"
+
+const userColumns = `id, created_at, updated_at, deleted_at, version, email, firstname, lastname,
+	about, date_of_birth, avatar_url, gender, location, socials, needs_completion, completed_at, moderation, roles, privacy,
+	handle, handle_changed_at, former_handles, followers_count, following_count`
+
+// PostgresUserRepository stores users in the users table created by the SQL
+// migrations. Soft deletes set deleted_at, exactly like the Mongo repository.
+type PostgresUserRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewPostgresUserRepository(pool *pgxpool.Pool) *PostgresUserRepository {
+	return &PostgresUserRepository{pool: pool}
+}
+
+// CreateUser inserts a new user, setting CreatedAt/UpdatedAt and Version.
+func (r *PostgresUserRepository) CreateUser(ctx context.Context, user *model.User) error {
+	if user.ID == uuid.Nil {
+		user.ID = uuid.New()
+	}
+	now := time.Now().UTC()
+	user.CreatedAt = now
+	user.UpdatedAt = now
+	user.Version = 1
+
+	_, err := pgConn(ctx, r.pool).Exec(ctx, `INSERT INTO users (`+userColumns+`)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21, $22, $23, $24)`,
+		user.ID, user.CreatedAt, user.UpdatedAt, user.DeletedAt, user.Version,
+		user.Email, user.Firstname, user.Lastname, user.About, user.DateOfBirth,
+		user.AvatarURL, user.Gender, user.Location, orEmpty(user.Socials), user.NeedsCompletion,
+		user.CompletedAt, user.Moderation, orEmpty(user.Roles), user.Privacy,
+		user.Handle, user.HandleChangedAt, user.FormerHandles, user.FollowersCount, user.FollowingCount,
+	)
+	return uniqueViolationConflict(err)
+}
+
+// uniqueViolationConflict tells which unique index rejected a write; other errors pass through.
+func uniqueViolationConflict(err error) error {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == pgUniqueViolation {
+		switch pgErr.ConstraintName {
+		case migrations.UsersEmailIndex:
+			return &service.ConflictError{Field: "email"}
+		case migrations.UsersHandleIndex:
+			return &service.ConflictError{Field: "handle"}
+		}
+		return &service.ConflictError{Field: "id"}
+	}
+	return err
+}
+
+// UpdateUser updates mutable fields and sets UpdatedAt timestamp.
+// The write only succeeds if the stored version still equals user.Version,
+// which is then incremented.
+func (r *PostgresUserRepository) UpdateUser(ctx context.Context, user *model.User) error {
+	updatedAt := time.Now().UTC()
+
+	tag, err := pgConn(ctx, r.pool).Exec(ctx, `UPDATE users SET
+			firstname = $3, lastname = $4, email = $5, about = $6, date_of_birth = $7,
+			avatar_url = $8, gender = $9, location = $10, socials = $11,
+			needs_completion = $12, completed_at = $13,
+			updated_at = $14, version = version + 1
+		WHERE id = $1 AND deleted_at IS NULL AND version = $2`,
+		user.ID, user.Version,
+		user.Firstname, user.Lastname, user.Email, user.About, user.DateOfBirth,
+		user.AvatarURL, user.Gender, user.Location, orEmpty(user.Socials),
+		user.NeedsCompletion, user.CompletedAt,
+		updatedAt,
+	)
+	if err != nil {
+		return uniqueViolationConflict(err)
+	}
+	if tag.RowsAffected() == 0 {
+		return r.missedUpdateError(ctx, user.ID)
+	}
+
+	user.UpdatedAt = updatedAt
+	user.Version++
+	return nil
+}
+
+// PatchUser sets or clears only the fields named in patch, guarded by version
+// like UpdateUser, and returns the updated row.
+func (r *PostgresUserRepository) PatchUser(ctx context.Context, id uuid.UUID, version int64, patch service.UserPatch) (*model.User, error) {
+	sets := []string{"updated_at = $3", "version = version + 1"}
+	args := []any{id, version, time.Now().UTC()}
+	for _, field := range patch.Fields {
+		value, ok := patch.Value(field)
+		if !ok {
+			value = clearedValue(field)
+		} else if field == service.FieldSocials || field == service.FieldRoles {
+			value = orEmpty(value.([]string))
+		}
+		args = append(args, value)
+		sets = append(sets, fmt.Sprintf("%s = $%d", field, len(args)))
+	}
+
+	row := pgConn(ctx, r.pool).QueryRow(ctx, `UPDATE users SET `+strings.Join(sets, ", ")+`
+		WHERE id = $1 AND deleted_at IS NULL AND version = $2
+		RETURNING `+userColumns, args...)
+
+	user, err := scanUser(row)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, r.missedUpdateError(ctx, id)
+	}
+	if err != nil {
+		return nil, uniqueViolationConflict(err)
+	}
+	return user, nil
+}
+
+// clearedValue is what an unset patch field is stored as; columns are NOT NULL
+// with empty defaults except date_of_birth, completed_at, moderation, privacy
+// and the handle history.
+func clearedValue(field string) any {
+	switch field {
+	case service.FieldDateOfBirth, service.FieldCompletedAt, service.FieldModeration, service.FieldPrivacy,
+		service.FieldHandleChangedAt, service.FieldFormerHandles:
+		return nil
+	case service.FieldSocials, service.FieldRoles:
+		return []string{}
+	}
+	return ""
+}
+
+// missedUpdateError explains why a guarded write matched nothing: the row is
+// missing, soft-deleted, or lost a version race.
+func (r *PostgresUserRepository) missedUpdateError(ctx context.Context, id uuid.UUID) error {
+	var deletedAt *time.Time
+	err := pgConn(ctx, r.pool).QueryRow(ctx, `SELECT deleted_at FROM users WHERE id = $1`, id).Scan(&deletedAt)
+	switch {
+	case errors.Is(err, pgx.ErrNoRows):
+		return service.ErrUserNotFound
+	case err != nil:
+		return err
+	case deletedAt != nil:
+		return service.ErrUserDeleted
+	default:
+		return service.ErrVersionConflict
+	}
+}
+
+// DeleteUserById soft-deletes a user by setting DeletedAt.
+func (r *PostgresUserRepository) DeleteUserById(ctx context.Context, userId uuid.UUID) error {
+	tag, err := pgConn(ctx, r.pool).Exec(ctx,
+		`UPDATE users SET deleted_at = $2 WHERE id = $1 AND deleted_at IS NULL`,
+		userId, time.Now().UTC(),
+	)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return r.missedUpdateError(ctx, userId)
+	}
+	return nil
+}
+
+// RestoreUser clears DeletedAt of a user deleted at or after deletedSince.
+func (r *PostgresUserRepository) RestoreUser(ctx context.Context, id uuid.UUID, deletedSince time.Time) error {
+	tag, err := pgConn(ctx, r.pool).Exec(ctx, `UPDATE users
+		SET deleted_at = NULL, updated_at = $3, version = version + 1
+		WHERE id = $1 AND deleted_at >= $2`,
+		id, deletedSince, time.Now().UTC(),
+	)
+	if err != nil {
+		// A live profile may have taken the email meanwhile
+		return uniqueViolationConflict(err)
+	}
+	if tag.RowsAffected() > 0 {
+		return nil
+	}
+
+	// Explain why nothing matched
+	var deletedAt *time.Time
+	err = pgConn(ctx, r.pool).QueryRow(ctx, `SELECT deleted_at FROM users WHERE id = $1`, id).Scan(&deletedAt)
+	switch {
+	case errors.Is(err, pgx.ErrNoRows):
+		return service.ErrUserNotFound
+	case err != nil:
+		return err
+	case deletedAt == nil:
+		return service.ErrNotDeleted
+	default:
+		return service.ErrRestoreExpired
+	}
+}
+
+// ListDeletedBefore returns up to limit users soft-deleted before cutoff, oldest first.
+func (r *PostgresUserRepository) ListDeletedBefore(ctx context.Context, cutoff time.Time, limit int) ([]model.User, error) {
+	return r.queryUsers(ctx, `SELECT `+userColumns+` FROM users
+		WHERE deleted_at < $1 ORDER BY deleted_at LIMIT $2`, cutoff, limit)
+}
+
+// PurgeUser hard-deletes a user that is still soft-deleted before cutoff and
+// reports whether a row was removed.
+func (r *PostgresUserRepository) PurgeUser(ctx context.Context, id uuid.UUID, cutoff time.Time) (bool, error) {
+	tag, err := pgConn(ctx, r.pool).Exec(ctx, `DELETE FROM users WHERE id = $1 AND deleted_at < $2`, id, cutoff)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+// ListModerationExpiredBefore returns up to limit live users whose
+// restriction expires before cutoff, soonest first.
+func (r *PostgresUserRepository) ListModerationExpiredBefore(ctx context.Context, cutoff time.Time, limit int) ([]model.User, error) {
+	return r.queryUsers(ctx, `SELECT `+userColumns+` FROM users
+		WHERE moderation IS NOT NULL AND deleted_at IS NULL
+			AND (moderation->>'expires_at')::timestamptz < $1
+		ORDER BY (moderation->>'expires_at')::timestamptz LIMIT $2`, cutoff, limit)
+}
+
+// ListUsers returns up to q.Limit non-deleted users ordered by q.SortField, starting after q.After.
+func (r *PostgresUserRepository) ListUsers(ctx context.Context, q service.ListQuery) ([]model.User, error) {
+	where, args := userFilterToSQL(q.Filter)
+
+	// Sort fields come from service.ParseSort, never from raw input
+	dir, op := "ASC", ">"
+	if q.Desc {
+		dir, op = "DESC", "<"
+	}
+
+	// Keyset pagination: ties on the sort field are broken by id
+	if q.After != nil {
+		args = append(args, q.After.Value, q.After.ID)
+		where = append(where, fmt.Sprintf("(%s, id) %s ($%d, $%d)", q.SortField, op, len(args)-1, len(args)))
+	}
+	args = append(args, q.Limit)
+
+	return r.queryUsers(ctx, fmt.Sprintf(`SELECT %s FROM users WHERE %s ORDER BY %s %s, id %s LIMIT $%d`,
+		userColumns, strings.Join(where, " AND "), q.SortField, dir, dir, len(args)), args...)
+}
+
+// SearchUsers returns non-deleted users matching q.Text ordered by rank.
+func (r *PostgresUserRepository) SearchUsers(ctx context.Context, q service.SearchQuery) ([]model.User, error) {
+	return r.queryUsers(ctx, `SELECT `+userColumns+` FROM users, websearch_to_tsquery('simple', $1) query
+		WHERE deleted_at IS NULL AND search @@ query AND (moderation IS NULL OR $4) AND id <> ALL($5::uuid[])
+		ORDER BY ts_rank(search, query) DESC, id
+		OFFSET $2 LIMIT $3`, q.Text, q.Offset, q.Limit, q.IncludeModerated, uuidStrings(q.ExcludeIDs))
+}
+
+// userFilterToSQL translates a service filter into conditions on non-deleted users.
+func userFilterToSQL(f service.UserFilter) ([]string, []any) {
+	where := []string{"deleted_at IS NULL"}
+	var args []any
+	add := func(cond string, v any) {
+		args = append(args, v)
+		where = append(where, fmt.Sprintf(cond, len(args)))
+	}
+
+	if f.Gender != "" {
+		add("gender = $%d", f.Gender)
+	}
+	if f.Location != "" {
+		add("lower(location) = lower($%d)", f.Location)
+	}
+	if f.NeedsCompletion != nil {
+		add("needs_completion = $%d", *f.NeedsCompletion)
+	}
+	if f.HasAvatar != nil {
+		if *f.HasAvatar {
+			where = append(where, "avatar_url <> ''")
+		} else {
+			where = append(where, "avatar_url = ''")
+		}
+	}
+	if f.CreatedFrom != nil {
+		add("created_at >= $%d", *f.CreatedFrom)
+	}
+	if f.CreatedTo != nil {
+		add("created_at <= $%d", *f.CreatedTo)
+	}
+	if f.UpdatedFrom != nil {
+		add("updated_at >= $%d", *f.UpdatedFrom)
+	}
+	if f.UpdatedTo != nil {
+		add("updated_at <= $%d", *f.UpdatedTo)
+	}
+	if f.Moderated != nil {
+		if *f.Moderated {
+			where = append(where, "moderation IS NOT NULL")
+		} else {
+			where = append(where, "moderation IS NULL")
+		}
+	}
+	if len(f.ExcludeIDs) > 0 {
+		add("id <> ALL($%d::uuid[])", uuidStrings(f.ExcludeIDs))
+	}
+	return where, args
+}
+
+// GetUserById finds a user by ID, soft-deleted ones included; callers decide how to treat DeletedAt.
+func (r *PostgresUserRepository) GetUserById(ctx context.Context, id uuid.UUID) (*model.User, error) {
+	user, err := scanUser(pgConn(ctx, r.pool).QueryRow(ctx, `SELECT `+userColumns+` FROM users WHERE id = $1`, id))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	return user, err
+}
+
+// GetUserByEmail finds a user by normalized email, soft-deleted ones included.
+// The live profile holding the email comes before deleted ones.
+func (r *PostgresUserRepository) GetUserByEmail(ctx context.Context, email string) (*model.User, error) {
+	user, err := scanUser(pgConn(ctx, r.pool).QueryRow(ctx, `SELECT `+userColumns+` FROM users
+		WHERE email = $1
+		ORDER BY deleted_at DESC NULLS FIRST
+		LIMIT 1`, email))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	return user, err
+}
+
+// AdjustFollowCounts adds delta to the following count of followerID and the
+// followers count of followeeID. Versions are left alone: counts are not
+// profile edits.
+func (r *PostgresUserRepository) AdjustFollowCounts(ctx context.Context, followerID, followeeID uuid.UUID, delta int64) error {
+	_, err := pgConn(ctx, r.pool).Exec(ctx, `UPDATE users SET
+			following_count = following_count + CASE WHEN id = $1 THEN $3 ELSE 0 END,
+			followers_count = followers_count + CASE WHEN id = $2 THEN $3 ELSE 0 END
+		WHERE id IN ($1, $2)`, followerID, followeeID, delta)
+	return err
+}
+
+// GetUserByHandle finds a user by current handle, soft-deleted ones included.
+func (r *PostgresUserRepository) GetUserByHandle(ctx context.Context, handle string) (*model.User, error) {
+	user, err := scanUser(pgConn(ctx, r.pool).QueryRow(ctx, `SELECT `+userColumns+` FROM users WHERE handle = $1`, handle))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	return user, err
+}
+
+// GetUserByFormerHandle finds the live user that gave up handle and whose
+// redirect is still in effect at now.
+func (r *PostgresUserRepository) GetUserByFormerHandle(ctx context.Context, handle string, now time.Time) (*model.User, error) {
+	user, err := scanUser(pgConn(ctx, r.pool).QueryRow(ctx, `SELECT `+userColumns+` FROM users
+		WHERE deleted_at IS NULL
+			AND former_handles @> jsonb_build_array(jsonb_build_object('handle', $1::text))
+			AND EXISTS (
+				SELECT 1 FROM jsonb_array_elements(former_handles) f
+				WHERE f->>'handle' = $1 AND (f->>'until')::timestamptz > $2
+			)
+		LIMIT 1`, handle, now))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	return user, err
+}
+
+// GetUsersByIds finds the non-deleted users among ids with a single query.
+func (r *PostgresUserRepository) GetUsersByIds(ctx context.Context, ids []uuid.UUID) ([]model.User, error) {
+	return r.queryUsers(ctx, `SELECT `+userColumns+` FROM users
+		WHERE id = ANY($1::uuid[]) AND deleted_at IS NULL`, uuidStrings(ids))
+}
+
+// uuidStrings encodes ids for a uuid[] parameter; nil becomes an empty array.
+func uuidStrings(ids []uuid.UUID) []string {
+	out := make([]string, len(ids))
+	for i, id := range ids {
+		out[i] = id.String()
+	}
+	return out
+}
+
+func (r *PostgresUserRepository) queryUsers(ctx context.Context, sql string, args ...any) ([]model.User, error) {
+	rows, err := pgConn(ctx, r.pool).Query(ctx, sql, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	users := make([]model.User, 0)
+	for rows.Next() {
+		user, err := scanUser(rows)
+		if err != nil {
+			return nil, err
+		}
+		users = append(users, *user)
+	}
+	return users, rows.Err()
+}
+
+// scanUser reads a row selected with userColumns.
+func scanUser(row pgx.Row) (*model.User, error) {
+	var u model.User
+	err := row.Scan(
+		&u.ID, &u.CreatedAt, &u.UpdatedAt, &u.DeletedAt, &u.Version,
+		&u.Email, &u.Firstname, &u.Lastname, &u.About, &u.DateOfBirth,
+		&u.AvatarURL, &u.Gender, &u.Location, &u.Socials, &u.NeedsCompletion,
+		&u.CompletedAt, &u.Moderation, &u.Roles, &u.Privacy,
+		&u.Handle, &u.HandleChangedAt, &u.FormerHandles, &u.FollowersCount, &u.FollowingCount,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if len(u.Socials) == 0 {
+		u.Socials = nil
+	}
+	if len(u.Roles) == 0 {
+		u.Roles = nil
+	}
+	return &u, nil
+}
+
+// orEmpty maps a nil slice to an empty array for NOT NULL array columns.
+func orEmpty(values []string) []string {
+	if values == nil {
+		return []string{}
+	}
+	return values
+}
@@ -1,25 +1,65 @@
 package routes
 
 import (
-	"github.com/Sayan80bayev/go-project/pkg/middleware"
 	"github.com/gin-gonic/gin"
+	"userService/internal/auth"
 	"userService/internal/bootstrap"
 	"userService/internal/delivery"
 )
 
+// SetupUserRoutes registers the user API. Who may call a route is declared
+// here with auth.Require; handlers assume the caller is already authorized.
 func SetupUserRoutes(r *gin.Engine, c *bootstrap.Container) {
 	h := delivery.NewUserHandler(c.UserService)
+	lh := delivery.NewLifecycleHandler(c.Lifecycle)
+	mh := delivery.NewModerationHandler(c.Moderation)
+	rh := delivery.NewRolesHandler(c.Roles)
 
-	routes := r.Group("api/v1/users")
+	authenticated := auth.Require(auth.Authenticated())
+	admin := auth.Require(auth.HasRole(auth.RoleAdmin))
+	ownerOrAdmin := auth.Require(auth.OwnerOrAdmin("id"))
+
+	routes := r.Group("api/v1/users", c.Verifier.Identify(), delivery.TrackOrigin())
 	{
-		routes.GET("", h.GetAllUsers)
-		routes.GET("/:id", h.GetUserById)
+		routes.GET("", auth.Require(auth.WhenQuery("moderated", auth.HasRole(auth.RoleAdmin))), h.GetAllUsers)
+		routes.GET("/search", h.SearchUsers)
+		routes.GET("/@:handle", h.GetUserByHandle)
+		routes.GET("/:id/followers", h.ListFollowers)
+		routes.GET("/:id/following", h.ListFollowing)
+		routes.GET("/:id", auth.Require(auth.WhenQuery("include_deleted", auth.HasRole(auth.RoleAdmin))), h.GetUserById)
 		// routes.GET("/", h.GetUserByUsername)
 	}
 
-	authRoutes := r.Group("api/v1/users", middleware.AuthMiddleware(c.JWKSUrl))
+	authRoutes := r.Group("api/v1/users", c.Verifier.Identify(), authenticated, delivery.TrackOrigin())
 	{
-		authRoutes.DELETE("/:id", h.DeleteUser)
-		authRoutes.PUT("/:id", h.UpdateUser)
+		authRoutes.GET("/by-email", admin, h.GetUserByEmail)
+
+		// The caller's own profile, bound to the token subject
+		authRoutes.GET("/me", h.GetMe)
+		authRoutes.PUT("/me", h.UpdateUser)
+		authRoutes.PATCH("/me", h.PatchUser)
+		authRoutes.DELETE("/me", h.DeleteUser)
+		authRoutes.PATCH("/me/privacy", h.UpdatePrivacy)
+		authRoutes.PUT("/me/handle", h.ChangeHandle)
+
+		authRoutes.DELETE("/:id", ownerOrAdmin, h.DeleteUser)
+		authRoutes.PUT("/:id", ownerOrAdmin, h.UpdateUser)
+		authRoutes.PATCH("/:id", ownerOrAdmin, h.PatchUser)
+		authRoutes.PATCH("/:id/privacy", ownerOrAdmin, h.UpdatePrivacy)
+		authRoutes.PUT("/:id/handle", ownerOrAdmin, h.ChangeHandle)
+		authRoutes.POST("/:id/restore", ownerOrAdmin, lh.RestoreUser)
+		authRoutes.PUT("/:id/follow", h.Follow)
+		authRoutes.DELETE("/:id/follow", h.Unfollow)
+		authRoutes.PUT("/:id/block", h.Block)
+		authRoutes.DELETE("/:id/block", h.Unblock)
+		authRoutes.GET("/:id/history", ownerOrAdmin, h.GetUserHistory)
+		authRoutes.GET("/:id/history/:revision", ownerOrAdmin, h.GetUserAtRevision)
+
+		authRoutes.POST("/:id/ban", admin, mh.BanUser)
+		authRoutes.POST("/:id/suspend", admin, mh.SuspendUser)
+		authRoutes.POST("/:id/unban", admin, mh.UnbanUser)
+
+		authRoutes.POST("/:id/roles", admin, rh.GrantRoles)
+		authRoutes.DELETE("/:id/roles/:role", admin, rh.RevokeRole)
 	}
 }
@@ -0,0 +1,120 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Kinds of failure that delivery maps to status codes. Specific errors below
+// match their kind with errors.Is, so callers may test either.
+var (
+	ErrNotFound   = errors.New("not found")
+	ErrConflict   = errors.New("conflict")
+	ErrValidation = errors.New("validation failed")
+	ErrForbidden  = errors.New("forbidden")
+	// ErrDeleted means the resource existed but is gone for good or soft-deleted.
+	ErrDeleted = errors.New("deleted")
+)
+
+var (
+	ErrInvalidCursor = errors.New("invalid cursor")
+	ErrInvalidSort   = errors.New("invalid sort key")
+	ErrInvalidLimit  = errors.New("invalid limit")
+	ErrInvalidFilter = errors.New("invalid filter")
+	ErrInvalidSearch = errors.New("invalid search query")
+	ErrInvalidBatch  = errors.New("invalid batch")
+
+	// ErrPreconditionFailed means the caller edited a version that is no longer current.
+	ErrPreconditionFailed = errors.New("user version does not match")
+	// ErrVersionConflict means a concurrent write won the race between read and write.
+	ErrVersionConflict = kindError(ErrConflict, "user was modified concurrently")
+
+	ErrUserNotFound = kindError(ErrNotFound, "user not found")
+	// ErrUserDeleted means the profile exists but was soft-deleted.
+	ErrUserDeleted = kindError(ErrDeleted, "user has been deleted")
+
+	ErrNotDeleted     = kindError(ErrConflict, "user is not deleted")
+	ErrRestoreExpired = kindError(ErrDeleted, "restore window has expired")
+
+	ErrRevisionNotFound = kindError(ErrNotFound, "revision not found")
+
+	// ErrAccountRestricted means the owner of a banned or suspended profile tried to edit it.
+	ErrAccountRestricted = kindError(ErrForbidden, "account is banned or suspended")
+	ErrNotModerated      = kindError(ErrConflict, "user is not banned or suspended")
+
+	// ErrRoleSync means the identity provider rejected or did not answer a
+	// role change; the profile is left unchanged.
+	ErrRoleSync = errors.New("could not sync roles with the identity provider")
+)
+
+// domainError is a sentinel that also matches its kind.
+type domainError struct {
+	kind error
+	msg  string
+}
+
+func kindError(kind error, msg string) error {
+	return &domainError{kind: kind, msg: msg}
+}
+
+func (e *domainError) Error() string { return e.msg }
+
+func (e *domainError) Is(target error) bool { return target == e.kind }
+
+// ConflictError reports a write rejected because a unique field is already taken.
+// It matches ErrConflict.
+type ConflictError struct {
+	Field string
+}
+
+func (e *ConflictError) Error() string {
+	return fmt.Sprintf("%s is already taken", e.Field)
+}
+
+func (e *ConflictError) Is(target error) bool {
+	return target == ErrConflict
+}
+
+// FieldError describes why a single field was rejected.
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// ValidationError carries every rejected field of a request. It matches ErrValidation.
+type ValidationError struct {
+	Fields []FieldError
+}
+
+func (e *ValidationError) Error() string {
+	msgs := make([]string, 0, len(e.Fields))
+	for _, f := range e.Fields {
+		msgs = append(msgs, f.Field+": "+f.Message)
+	}
+	return "validation failed: " + strings.Join(msgs, "; ")
+}
+
+func (e *ValidationError) Is(target error) bool { return target == ErrValidation }
+
+func (e *ValidationError) add(field, message string) {
+	e.Fields = append(e.Fields, FieldError{Field: field, Message: message})
+}
+
+// orNil returns e only if it holds at least one field error.
+func (e *ValidationError) orNil() error {
+	if len(e.Fields) == 0 {
+		return nil
+	}
+	return e
+}
+
+// IsQueryError reports whether err was caused by malformed listing parameters.
+func IsQueryError(err error) bool {
+	return errors.Is(err, ErrInvalidCursor) ||
+		errors.Is(err, ErrInvalidSort) ||
+		errors.Is(err, ErrInvalidLimit) ||
+		errors.Is(err, ErrInvalidFilter) ||
+		errors.Is(err, ErrInvalidSearch) ||
+		errors.Is(err, ErrInvalidBatch)
+}
@@ -0,0 +1,86 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/Sayan80bayev/go-project/pkg/logging"
+	"github.com/Sayan80bayev/go-project/pkg/messaging"
+	"github.com/google/uuid"
+	"userService/internal/model"
+)
+
+// Transactor runs fn in a database transaction. Repositories called with the
+// context handed to fn take part in it; fn's error rolls everything back.
+type Transactor interface {
+	WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+// OutboxRepository stores events until OutboxRelay has published them.
+type OutboxRepository interface {
+	Enqueue(ctx context.Context, msg *model.OutboxMessage) error
+	// ClaimPending hides up to limit due messages, oldest first, from other
+	// relays until now+lease and returns them.
+	ClaimPending(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]model.OutboxMessage, error)
+	MarkSent(ctx context.Context, id uuid.UUID, sentAt time.Time) error
+	// MarkFailed records a failed attempt. The message is retried at nextAttempt,
+	// or never again when dead is set.
+	MarkFailed(ctx context.Context, id uuid.UUID, attempts int, nextAttempt time.Time, lastErr string, dead bool) error
+	// DeleteSentBefore removes messages published before cutoff and returns how many.
+	DeleteSentBefore(ctx context.Context, cutoff time.Time) (int64, error)
+	Stats(ctx context.Context) (OutboxStats, error)
+}
+
+// OutboxStats describes the outbox backlog.
+type OutboxStats struct {
+	Pending int64
+	// OldestPending is the creation time of the oldest pending message, zero if none
+	OldestPending time.Time
+	Dead          int64
+}
+
+// publisher emits the events of profile writes. With an outbox, events are
+// stored in the transaction of the write and relayed later, so a write and
+// its event either both happen or neither does; without a transactor they are
+// stored right after the write. Without an outbox they go straight to the
+// producer and failures are only logged.
+type publisher struct {
+	producer messaging.Producer
+	tx       Transactor
+	outbox   OutboxRepository
+}
+
+// atomically runs fn in a transaction when a transactor is configured.
+func (p *publisher) atomically(ctx context.Context, fn func(ctx context.Context) error) error {
+	if p.tx == nil {
+		return fn(ctx)
+	}
+	return p.tx.WithinTransaction(ctx, fn)
+}
+
+// publish emits an event as part of the write running in ctx.
+func (p *publisher) publish(ctx context.Context, eventType string, payload any) error {
+	if p.outbox == nil {
+		// Non-blocking for the write
+		if err := p.producer.Produce(ctx, eventType, payload); err != nil {
+			logging.Instance.Errorf("failed to publish %s event: %v", eventType, err)
+		}
+		return nil
+	}
+
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to encode %s event: %w", eventType, err)
+	}
+	now := time.Now().UTC()
+	return p.outbox.Enqueue(ctx, &model.OutboxMessage{
+		ID:            uuid.Must(uuid.NewV7()),
+		EventType:     eventType,
+		Payload:       data,
+		Status:        model.OutboxPending,
+		NextAttemptAt: now,
+		CreatedAt:     now,
+	})
+}
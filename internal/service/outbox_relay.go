@@ -0,0 +1,163 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/Sayan80bayev/go-project/pkg/logging"
+	"github.com/Sayan80bayev/go-project/pkg/messaging"
+	"userService/internal/metrics"
+)
+
+// RelayPolicy controls how OutboxRelay polls and retries.
+type RelayPolicy struct {
+	// Interval is how often the outbox is polled
+	Interval time.Duration
+	// BatchSize bounds the number of messages claimed per pass
+	BatchSize int
+	// Lease is how long a claimed message stays hidden from other relays
+	Lease time.Duration
+	// MaxAttempts is how often a message is tried before it is marked dead
+	MaxAttempts int
+	// MaxBackoff caps the exponential delay between attempts
+	MaxBackoff time.Duration
+	// SentRetention is how long published messages are kept for inspection
+	SentRetention time.Duration
+}
+
+// OutboxRelay publishes outbox messages to Kafka. Delivery is at least once:
+// a crash between publishing and marking a message sent publishes it again.
+// Order is not guaranteed: a failed message is retried after a backoff, and
+// later messages, including those about the same user, are relayed meanwhile.
+type OutboxRelay struct {
+	outbox   OutboxRepository
+	producer messaging.Producer
+	policy   RelayPolicy
+	now      func() time.Time
+}
+
+func NewOutboxRelay(outbox OutboxRepository, producer messaging.Producer, policy RelayPolicy) *OutboxRelay {
+	if policy.Interval <= 0 {
+		policy.Interval = time.Second
+	}
+	if policy.BatchSize <= 0 {
+		policy.BatchSize = 100
+	}
+	if policy.Lease <= 0 {
+		policy.Lease = 30 * time.Second
+	}
+	if policy.MaxAttempts <= 0 {
+		policy.MaxAttempts = 20
+	}
+	if policy.MaxBackoff <= 0 {
+		policy.MaxBackoff = 5 * time.Minute
+	}
+	if policy.SentRetention <= 0 {
+		policy.SentRetention = 7 * 24 * time.Hour
+	}
+	return &OutboxRelay{
+		outbox:   outbox,
+		producer: producer,
+		policy:   policy,
+		now:      time.Now,
+	}
+}
+
+// RelayPending publishes one batch of due messages, oldest first, and returns
+// how many were sent. It stops at the first failure and leaves the rest of the
+// batch to be claimed again once its lease expires.
+func (r *OutboxRelay) RelayPending(ctx context.Context) (int, error) {
+	now := r.now().UTC()
+	messages, err := r.outbox.ClaimPending(ctx, now, r.policy.Lease, r.policy.BatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	sent := 0
+	for _, msg := range messages {
+		if err := r.producer.Produce(ctx, msg.EventType, json.RawMessage(msg.Payload)); err != nil {
+			metrics.OutboxFailedAttempts.Add(1)
+			attempts := msg.Attempts + 1
+			dead := attempts >= r.policy.MaxAttempts
+			if dead {
+				logging.Instance.Errorf("giving up on outbox message %s (%s) after %d attempts: %v", msg.ID, msg.EventType, attempts, err)
+			}
+			if markErr := r.outbox.MarkFailed(ctx, msg.ID, attempts, now.Add(r.backoff(attempts)), err.Error(), dead); markErr != nil {
+				return sent, markErr
+			}
+			return sent, err
+		}
+
+		if err := r.outbox.MarkSent(ctx, msg.ID, r.now().UTC()); err != nil {
+			return sent, err
+		}
+		metrics.OutboxPublished.Add(1)
+		sent++
+	}
+	return sent, nil
+}
+
+// backoff doubles the retry delay with every attempt, up to MaxBackoff.
+func (r *OutboxRelay) backoff(attempts int) time.Duration {
+	d := time.Second
+	for i := 1; i < attempts && d < r.policy.MaxBackoff; i++ {
+		d *= 2
+	}
+	return min(d, r.policy.MaxBackoff)
+}
+
+// cleanup drops published messages older than SentRetention.
+func (r *OutboxRelay) cleanup(ctx context.Context) {
+	n, err := r.outbox.DeleteSentBefore(ctx, r.now().UTC().Add(-r.policy.SentRetention))
+	if err != nil {
+		logging.Instance.Warnf("failed to clean up outbox: %v", err)
+		return
+	}
+	if n > 0 {
+		logging.Instance.Infof("deleted %d sent outbox messages", n)
+	}
+}
+
+// refreshMetrics publishes the current backlog.
+func (r *OutboxRelay) refreshMetrics(ctx context.Context) {
+	stats, err := r.outbox.Stats(ctx)
+	if err != nil {
+		logging.Instance.Warnf("failed to read outbox stats: %v", err)
+		return
+	}
+	metrics.OutboxPending.Set(stats.Pending)
+	metrics.OutboxDead.Set(stats.Dead)
+	age := 0.0
+	if !stats.OldestPending.IsZero() {
+		age = r.now().Sub(stats.OldestPending).Seconds()
+	}
+	metrics.OutboxOldestPendingSeconds.Set(age)
+}
+
+// Run relays messages every interval until ctx is cancelled.
+func (r *OutboxRelay) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.policy.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			// Drain full batches before waiting for the next tick
+			for {
+				n, err := r.RelayPending(ctx)
+				if err != nil {
+					logging.Instance.Errorf("outbox relay failed: %v", err)
+					break
+				}
+				if n < r.policy.BatchSize {
+					break
+				}
+			}
+			r.cleanup(ctx)
+			r.refreshMetrics(ctx)
+		}
+	}
+}
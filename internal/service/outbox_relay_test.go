@@ -0,0 +1,167 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"userService/internal/events"
+	"userService/internal/model"
+)
+
+type MockOutboxRepository struct {
+	mock.Mock
+}
+
+func (m *MockOutboxRepository) Enqueue(ctx context.Context, msg *model.OutboxMessage) error {
+	args := m.Called(ctx, msg)
+	return args.Error(0)
+}
+
+func (m *MockOutboxRepository) ClaimPending(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]model.OutboxMessage, error) {
+	args := m.Called(ctx, now, lease, limit)
+	return args.Get(0).([]model.OutboxMessage), args.Error(1)
+}
+
+func (m *MockOutboxRepository) MarkSent(ctx context.Context, id uuid.UUID, sentAt time.Time) error {
+	args := m.Called(ctx, id, sentAt)
+	return args.Error(0)
+}
+
+func (m *MockOutboxRepository) MarkFailed(ctx context.Context, id uuid.UUID, attempts int, nextAttempt time.Time, lastErr string, dead bool) error {
+	args := m.Called(ctx, id, attempts, nextAttempt, lastErr, dead)
+	return args.Error(0)
+}
+
+func (m *MockOutboxRepository) DeleteSentBefore(ctx context.Context, cutoff time.Time) (int64, error) {
+	args := m.Called(ctx, cutoff)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockOutboxRepository) Stats(ctx context.Context) (OutboxStats, error) {
+	args := m.Called(ctx)
+	return args.Get(0).(OutboxStats), args.Error(1)
+}
+
+// MockTransactor runs fn inline and records whether it was used.
+type MockTransactor struct {
+	calls int
+}
+
+func (m *MockTransactor) WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	m.calls++
+	return fn(ctx)
+}
+
+func newTestRelay(outbox *MockOutboxRepository, p *MockProducer, now time.Time) *OutboxRelay {
+	r := NewOutboxRelay(outbox, p, RelayPolicy{BatchSize: 10, MaxAttempts: 3, MaxBackoff: 4 * time.Second})
+	r.now = func() time.Time { return now }
+	return r
+}
+
+func TestOutboxRelay_RelayPending(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	first := model.OutboxMessage{ID: uuid.New(), EventType: events.UserUpdated, Payload: []byte(`{"user_id":"a"}`)}
+	second := model.OutboxMessage{ID: uuid.New(), EventType: events.UserDeleted, Payload: []byte(`{"user_id":"b"}`)}
+
+	outbox := new(MockOutboxRepository)
+	p := new(MockProducer)
+	outbox.On("ClaimPending", mock.Anything, now, 30*time.Second, 10).Return([]model.OutboxMessage{first, second}, nil)
+	p.On("Produce", mock.Anything, events.UserUpdated, json.RawMessage(first.Payload)).Return(nil)
+	p.On("Produce", mock.Anything, events.UserDeleted, json.RawMessage(second.Payload)).Return(nil)
+	outbox.On("MarkSent", mock.Anything, first.ID, now).Return(nil)
+	outbox.On("MarkSent", mock.Anything, second.ID, now).Return(nil)
+
+	n, err := newTestRelay(outbox, p, now).RelayPending(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 2, n)
+	outbox.AssertExpectations(t)
+	p.AssertExpectations(t)
+}
+
+func TestOutboxRelay_RelayPending_Failure(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	kafkaErr := errors.New("broker unavailable")
+
+	tests := []struct {
+		name        string
+		attempts    int
+		wantBackoff time.Duration
+		wantDead    bool
+	}{
+		{name: "first failure retries after a second", attempts: 0, wantBackoff: time.Second},
+		{name: "backoff doubles", attempts: 1, wantBackoff: 2 * time.Second},
+		{name: "last attempt gives up", attempts: 2, wantBackoff: 4 * time.Second, wantDead: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			failing := model.OutboxMessage{ID: uuid.New(), EventType: events.UserUpdated, Payload: []byte(`{}`), Attempts: tt.attempts}
+			later := model.OutboxMessage{ID: uuid.New(), EventType: events.UserUpdated, Payload: []byte(`{}`)}
+
+			outbox := new(MockOutboxRepository)
+			p := new(MockProducer)
+			outbox.On("ClaimPending", mock.Anything, now, mock.Anything, mock.Anything).Return([]model.OutboxMessage{failing, later}, nil)
+			p.On("Produce", mock.Anything, events.UserUpdated, mock.Anything).Return(kafkaErr).Once()
+			outbox.On("MarkFailed", mock.Anything, failing.ID, tt.attempts+1, now.Add(tt.wantBackoff), kafkaErr.Error(), tt.wantDead).Return(nil)
+
+			n, err := newTestRelay(outbox, p, now).RelayPending(context.Background())
+			assert.ErrorIs(t, err, kafkaErr)
+			assert.Zero(t, n)
+			// Later messages wait so they do not overtake the failed one
+			p.AssertNumberOfCalls(t, "Produce", 1)
+			outbox.AssertNotCalled(t, "MarkSent", mock.Anything, mock.Anything, mock.Anything)
+			outbox.AssertExpectations(t)
+		})
+	}
+}
+
+func TestUserService_DeleteUserById_WithOutbox(t *testing.T) {
+	userID := uuid.New()
+	repo := new(MockUserRepository)
+	p := new(MockProducer)
+	cache := new(MockCacheService)
+	outbox := new(MockOutboxRepository)
+	tx := new(MockTransactor)
+
+	repo.On("DeleteUserById", mock.Anything, userID).Return(nil)
+	cache.On("Delete", mock.Anything, mock.Anything).Return(nil)
+	outbox.On("Enqueue", mock.Anything, mock.MatchedBy(func(msg *model.OutboxMessage) bool {
+		var payload events.UserDeletedPayload
+		return msg.EventType == events.UserDeleted &&
+			msg.Status == model.OutboxPending &&
+			json.Unmarshal(msg.Payload, &payload) == nil &&
+			payload.UserID == userID
+	})).Return(nil)
+
+	svc := NewUserService(repo, nil, p, cache).WithOutbox(tx, outbox)
+	require.NoError(t, svc.DeleteUserById(context.Background(), userID))
+
+	assert.Equal(t, 1, tx.calls)
+	outbox.AssertExpectations(t)
+	// Events go through the relay, never straight to Kafka
+	p.AssertNotCalled(t, "Produce", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestUserService_DeleteUserById_OutboxFailureAbortsWrite(t *testing.T) {
+	userID := uuid.New()
+	repo := new(MockUserRepository)
+	cache := new(MockCacheService)
+	outbox := new(MockOutboxRepository)
+	outboxErr := errors.New("outbox unavailable")
+
+	repo.On("DeleteUserById", mock.Anything, userID).Return(nil)
+	outbox.On("Enqueue", mock.Anything, mock.Anything).Return(outboxErr)
+
+	svc := NewUserService(repo, nil, new(MockProducer), cache).WithOutbox(new(MockTransactor), outbox)
+	err := svc.DeleteUserById(context.Background(), userID)
+
+	assert.ErrorIs(t, err, outboxErr)
+	cache.AssertNotCalled(t, "Delete", mock.Anything, mock.Anything)
+}
@@ -0,0 +1,148 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/Sayan80bayev/go-project/pkg/logging"
+	"github.com/google/uuid"
+	"userService/internal/transport/response"
+)
+
+// MaxBatchSize bounds the number of IDs accepted by GetUsersByIds.
+const MaxBatchSize = 100
+
+// MultiGetter reads several cache keys in one round trip. The result has one
+// entry per key, empty for keys that are not cached.
+type MultiGetter interface {
+	MGet(ctx context.Context, keys ...string) ([]string, error)
+}
+
+// WithMultiGetter lets GetUsersByIds read the cache in a single round trip
+// instead of one Get per ID.
+func (s *UserService) WithMultiGetter(mg MultiGetter) *UserService {
+	s.multiCache = mg
+	return s
+}
+
+// GetUsersByIds resolves a batch of users, serving what it can from the cache
+// and loading the rest with a single repository query. Users come back in the
+// order of ids (duplicates collapsed); unknown, deleted and hidden IDs, and
+// users that blocked the caller, are reported in Missing.
+func (s *UserService) GetUsersByIds(ctx context.Context, ids []uuid.UUID) (*response.UserBatchResponse, error) {
+	ids = uniqueIDs(ids)
+	if len(ids) == 0 {
+		return nil, fmt.Errorf("%w: at least one id is required", ErrInvalidBatch)
+	}
+	if len(ids) > MaxBatchSize {
+		return nil, fmt.Errorf("%w: at most %d ids are allowed", ErrInvalidBatch, MaxBatchSize)
+	}
+
+	found := s.cachedUsers(ctx, ids)
+
+	misses := make([]uuid.UUID, 0, len(ids)-len(found))
+	for _, id := range ids {
+		if _, ok := found[id]; !ok {
+			misses = append(misses, id)
+		}
+	}
+
+	if len(misses) > 0 {
+		users, err := s.userRepo.GetUsersByIds(ctx, misses)
+		if err != nil {
+			return nil, err
+		}
+		for _, u := range users {
+			if u.DeletedAt != nil {
+				continue
+			}
+			ur := s.toResponse(u)
+			found[u.ID] = ur
+			s.cacheUser(ctx, ur)
+		}
+	}
+
+	hidden, err := s.hiddenByBlocks(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &response.UserBatchResponse{
+		Users:   make([]response.UserResponse, 0, len(found)),
+		Missing: []uuid.UUID{},
+	}
+	for _, id := range ids {
+		if ur, ok := found[id]; ok && canSee(ctx, id, ur.Moderation != nil) && !slices.Contains(hidden, id) {
+			resp.Users = append(resp.Users, present(ctx, ur))
+		} else {
+			resp.Missing = append(resp.Missing, id)
+		}
+	}
+	return resp, nil
+}
+
+// cachedUsers returns the live users among ids that are present in the cache.
+// Cache failures only cost a trip to the database, so they are logged and ignored.
+func (s *UserService) cachedUsers(ctx context.Context, ids []uuid.UUID) map[uuid.UUID]response.UserResponse {
+	keys := make([]string, len(ids))
+	for i, id := range ids {
+		keys[i] = fmt.Sprintf("user:%s", id)
+	}
+
+	var values []string
+	if s.multiCache != nil {
+		var err error
+		if values, err = s.multiCache.MGet(ctx, keys...); err != nil {
+			logging.Instance.Warnf("cache multi-get failed for %d users: %v", len(keys), err)
+			values = nil
+		}
+	} else {
+		values = make([]string, len(keys))
+		for i, key := range keys {
+			values[i], _ = s.cache.Get(ctx, key)
+		}
+	}
+
+	found := make(map[uuid.UUID]response.UserResponse, len(ids))
+	for i, v := range values {
+		if v == "" || i >= len(ids) {
+			continue
+		}
+		var ur response.UserResponse
+		if err := json.Unmarshal([]byte(v), &ur); err != nil {
+			logging.Instance.Warnf("failed to unmarshal cached user %s: %v", ids[i], err)
+			continue
+		}
+		if ur.DeletedAt == nil {
+			found[ids[i]] = ur
+		}
+	}
+	return found
+}
+
+func (s *UserService) cacheUser(ctx context.Context, ur response.UserResponse) {
+	data, err := json.Marshal(ur)
+	if err != nil {
+		logging.Instance.Warnf("failed to marshal user %s for cache: %v", ur.ID, err)
+		return
+	}
+	if err := s.cache.Set(ctx, fmt.Sprintf("user:%s", ur.ID), data, 10*time.Minute); err != nil {
+		logging.Instance.Warnf("failed to set cache for user %s: %v", ur.ID, err)
+	}
+}
+
+func uniqueIDs(ids []uuid.UUID) []uuid.UUID {
+	seen := make(map[uuid.UUID]struct{}, len(ids))
+	out := make([]uuid.UUID, 0, len(ids))
+	for _, id := range ids {
+		if _, ok := seen[id]; ok {
+			continue
+		}
+		seen[id] = struct{}{}
+		out = append(out, id)
+	}
+	return out
+}
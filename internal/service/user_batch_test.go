@@ -0,0 +1,70 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"userService/internal/model"
+	"userService/internal/transport/response"
+)
+
+type MockMultiGetter struct {
+	mock.Mock
+}
+
+func (m *MockMultiGetter) MGet(ctx context.Context, keys ...string) ([]string, error) {
+	args := m.Called(ctx, keys)
+	return args.Get(0).([]string), args.Error(1)
+}
+
+func TestUserService_GetUsersByIds(t *testing.T) {
+	cachedID, storedID, missingID := uuid.New(), uuid.New(), uuid.New()
+	keys := []string{
+		fmt.Sprintf("user:%s", storedID),
+		fmt.Sprintf("user:%s", cachedID),
+		fmt.Sprintf("user:%s", missingID),
+	}
+	cached, _ := json.Marshal(response.UserResponse{ID: cachedID, Firstname: "cached"})
+
+	repo := new(MockUserRepository)
+	cache := new(MockCacheService)
+	mg := new(MockMultiGetter)
+
+	mg.On("MGet", mock.Anything, keys).Return([]string{"", string(cached), ""}, nil)
+	// Only cache misses reach the database
+	repo.On("GetUsersByIds", mock.Anything, []uuid.UUID{storedID, missingID}).
+		Return([]model.User{{ID: storedID, Firstname: "stored"}}, nil)
+	cache.On("Set", mock.Anything, keys[0], mock.Anything, mock.Anything).Return(nil)
+
+	svc := NewUserService(repo, nil, nil, cache).WithMultiGetter(mg)
+	resp, err := svc.GetUsersByIds(context.Background(), []uuid.UUID{storedID, cachedID, missingID, storedID})
+
+	assert.NoError(t, err)
+	assert.Len(t, resp.Users, 2)
+	assert.Equal(t, storedID, resp.Users[0].ID, "input order is kept")
+	assert.Equal(t, "stored", resp.Users[0].Firstname)
+	assert.Equal(t, cachedID, resp.Users[1].ID)
+	assert.Equal(t, "cached", resp.Users[1].Firstname)
+	assert.Equal(t, []uuid.UUID{missingID}, resp.Missing)
+	repo.AssertExpectations(t)
+	cache.AssertExpectations(t)
+}
+
+func TestUserService_GetUsersByIds_InvalidBatch(t *testing.T) {
+	svc := NewUserService(nil, nil, nil, nil)
+
+	_, err := svc.GetUsersByIds(context.Background(), nil)
+	assert.ErrorIs(t, err, ErrInvalidBatch)
+
+	ids := make([]uuid.UUID, MaxBatchSize+1)
+	for i := range ids {
+		ids[i] = uuid.New()
+	}
+	_, err = svc.GetUsersByIds(context.Background(), ids)
+	assert.ErrorIs(t, err, ErrInvalidBatch)
+}
@@ -0,0 +1,276 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/Sayan80bayev/go-project/pkg/logging"
+	"github.com/google/uuid"
+	"userService/internal/events"
+	"userService/internal/model"
+)
+
+// blockSetTTL bounds how long a cached blocker set outlives a lost invalidation.
+const blockSetTTL = 10 * time.Minute
+
+// ErrSelfBlock means a user tried to block or unblock themselves.
+var ErrSelfBlock = kindError(ErrValidation, "users cannot block themselves")
+
+// BlockRepository stores who blocked whom.
+type BlockRepository interface {
+	// AddBlock stores b unless it exists and reports whether it was new.
+	AddBlock(ctx context.Context, b *model.Block) (bool, error)
+	// RemoveBlock deletes the block and reports whether it existed.
+	RemoveBlock(ctx context.Context, blockerID, blockedID uuid.UUID) (bool, error)
+	// ListBlockers returns everyone that blocked blockedID.
+	ListBlockers(ctx context.Context, blockedID uuid.UUID) ([]uuid.UUID, error)
+	// ListBlocked returns everyone blockerID blocked.
+	ListBlocked(ctx context.Context, blockerID uuid.UUID) ([]uuid.UUID, error)
+	// RemoveBlocksOf deletes every block by or of userID and returns them.
+	RemoveBlocksOf(ctx context.Context, userID uuid.UUID) ([]model.Block, error)
+}
+
+// BlockSet caches, per user, everyone that blocked them, so visibility checks
+// do not query the database.
+type BlockSet interface {
+	// Blockers returns the cached blockers of blockedID; ok is false when they are not cached.
+	Blockers(ctx context.Context, blockedID uuid.UUID) (blockers []uuid.UUID, ok bool, err error)
+	// IsBlockedBy reports whether blockerID blocked blockedID; ok is false when the set is not cached.
+	IsBlockedBy(ctx context.Context, blockedID, blockerID uuid.UUID) (blocked, ok bool, err error)
+	// BlockedByAny reports, per blocker ID, whether it blocked blockedID; ok is false when the set is not cached.
+	BlockedByAny(ctx context.Context, blockedID uuid.UUID, blockerIDs []uuid.UUID) (blocked []bool, ok bool, err error)
+	// Generation changes with every invalidation of the blockers of blockedID.
+	Generation(ctx context.Context, blockedID uuid.UUID) (int64, error)
+	// StoreBlockers caches blockers for ttl unless they were invalidated since gen was read.
+	StoreBlockers(ctx context.Context, blockedID uuid.UUID, blockers []uuid.UUID, gen int64, ttl time.Duration) error
+	// InvalidateBlockers drops the cached blockers and moves the generation on.
+	InvalidateBlockers(ctx context.Context, blockedID uuid.UUID) error
+}
+
+// WithBlocks enables blocking. set may be nil, in which case every check
+// reads the repository.
+func (s *UserService) WithBlocks(blocks BlockRepository, set BlockSet) *UserService {
+	s.blocks, s.blockSet = blocks, set
+	return s
+}
+
+// Block hides the profiles of blockerID and blockedID from each other and ends
+// follows in both directions. Blocking twice is a no-op and publishes nothing.
+func (s *UserService) Block(ctx context.Context, blockerID, blockedID uuid.UUID) error {
+	if blockerID == blockedID {
+		return ErrSelfBlock
+	}
+
+	blocked, err := s.userRepo.GetUserById(ctx, blockedID)
+	if err != nil {
+		return err
+	}
+	if blocked == nil || !canSee(ctx, blocked.ID, blocked.Moderation != nil) {
+		return fmt.Errorf("%w: %s", ErrUserNotFound, blockedID)
+	}
+	if blocked.DeletedAt != nil {
+		return ErrUserDeleted
+	}
+
+	var created, unfollowed bool
+	err = s.events.atomically(ctx, func(ctx context.Context) error {
+		var err error
+		b := &model.Block{BlockerID: blockerID, BlockedID: blockedID, CreatedAt: time.Now().UTC()}
+		if created, err = s.blocks.AddBlock(ctx, b); err != nil || !created {
+			return err
+		}
+		if s.follows != nil {
+			for _, edge := range [][2]uuid.UUID{{blockerID, blockedID}, {blockedID, blockerID}} {
+				removed, err := s.removeFollow(ctx, edge[0], edge[1])
+				if err != nil {
+					return err
+				}
+				unfollowed = unfollowed || removed
+			}
+		}
+		return s.events.publish(ctx, events.UserBlocked, events.BlockPayload{
+			BlockerID: blockerID,
+			BlockedID: blockedID,
+		})
+	})
+	if err != nil {
+		logging.Instance.Errorf("failed to make user %s block %s: %v", blockerID, blockedID, err)
+		return err
+	}
+	if created {
+		s.invalidateBlockers(ctx, blockedID)
+	}
+	if unfollowed {
+		s.invalidateFollowCounts(ctx, blockerID, blockedID)
+	}
+	return nil
+}
+
+// Unblock lifts the block of blockedID by blockerID. Follows ended by the
+// block are not restored. Unblocking a user that is not blocked is a no-op.
+func (s *UserService) Unblock(ctx context.Context, blockerID, blockedID uuid.UUID) error {
+	if blockerID == blockedID {
+		return ErrSelfBlock
+	}
+
+	var removed bool
+	err := s.events.atomically(ctx, func(ctx context.Context) error {
+		var err error
+		if removed, err = s.blocks.RemoveBlock(ctx, blockerID, blockedID); err != nil || !removed {
+			return err
+		}
+		return s.events.publish(ctx, events.UserUnblocked, events.BlockPayload{
+			BlockerID: blockerID,
+			BlockedID: blockedID,
+		})
+	})
+	if err != nil {
+		logging.Instance.Errorf("failed to make user %s unblock %s: %v", blockerID, blockedID, err)
+		return err
+	}
+	if removed {
+		s.invalidateBlockers(ctx, blockedID)
+	}
+	return nil
+}
+
+// IsBlocked reports whether blockerID blocked blockedID.
+func (s *UserService) IsBlocked(ctx context.Context, blockerID, blockedID uuid.UUID) (bool, error) {
+	if s.blockSet != nil {
+		blocked, ok, err := s.blockSet.IsBlockedBy(ctx, blockedID, blockerID)
+		if err != nil {
+			logging.Instance.Warnf("block set lookup failed for user %s: %v", blockedID, err)
+		} else if ok {
+			return blocked, nil
+		}
+	}
+
+	blockers, err := s.blockersOf(ctx, blockedID)
+	if err != nil {
+		return false, err
+	}
+	return slices.Contains(blockers, blockerID), nil
+}
+
+// BlockedBy returns those of blockerIDs that blocked blockedID, in the order
+// given and without duplicates.
+func (s *UserService) BlockedBy(ctx context.Context, blockedID uuid.UUID, blockerIDs []uuid.UUID) ([]uuid.UUID, error) {
+	blockerIDs = uniqueIDs(blockerIDs)
+	if len(blockerIDs) == 0 {
+		return nil, fmt.Errorf("%w: at least one id is required", ErrInvalidBatch)
+	}
+	if len(blockerIDs) > MaxBatchSize {
+		return nil, fmt.Errorf("%w: at most %d ids are allowed", ErrInvalidBatch, MaxBatchSize)
+	}
+
+	found := []uuid.UUID{}
+	if s.blockSet != nil {
+		blocked, ok, err := s.blockSet.BlockedByAny(ctx, blockedID, blockerIDs)
+		if err != nil {
+			logging.Instance.Warnf("block set lookup failed for user %s: %v", blockedID, err)
+		} else if ok {
+			for i, id := range blockerIDs {
+				if blocked[i] {
+					found = append(found, id)
+				}
+			}
+			return found, nil
+		}
+	}
+
+	blockers, err := s.blockersOf(ctx, blockedID)
+	if err != nil {
+		return nil, err
+	}
+	for _, id := range blockerIDs {
+		if slices.Contains(blockers, id) {
+			found = append(found, id)
+		}
+	}
+	return found, nil
+}
+
+// blockersOf returns everyone that blocked blockedID, from the block set when
+// it is cached. Cache failures only cost a trip to the database. A set loaded
+// while a block or unblock commits is not cached: its generation, read before
+// loading, no longer matches once the change invalidates the set.
+func (s *UserService) blockersOf(ctx context.Context, blockedID uuid.UUID) ([]uuid.UUID, error) {
+	cacheable := false
+	var gen int64
+	if s.blockSet != nil {
+		blockers, ok, err := s.blockSet.Blockers(ctx, blockedID)
+		if err != nil {
+			logging.Instance.Warnf("block set get failed for user %s: %v", blockedID, err)
+		} else if ok {
+			return blockers, nil
+		}
+		if gen, err = s.blockSet.Generation(ctx, blockedID); err != nil {
+			logging.Instance.Warnf("block set generation failed for user %s: %v", blockedID, err)
+		} else {
+			cacheable = true
+		}
+	}
+
+	blockers, err := s.blocks.ListBlockers(ctx, blockedID)
+	if err != nil {
+		return nil, err
+	}
+	if cacheable {
+		if err := s.blockSet.StoreBlockers(ctx, blockedID, blockers, gen, blockSetTTL); err != nil {
+			logging.Instance.Warnf("failed to set block set for user %s: %v", blockedID, err)
+		}
+	}
+	return blockers, nil
+}
+
+// hiddenByBlocks returns the users whose profiles are hidden from the caller
+// because of a block in either direction: those that blocked the caller and
+// those the caller blocked. The latter are few and read from the repository.
+// Anonymous callers and admins see everyone.
+func (s *UserService) hiddenByBlocks(ctx context.Context) ([]uuid.UUID, error) {
+	o := OriginFrom(ctx)
+	if s.blocks == nil || o.Actor == nil || o.Admin {
+		return nil, nil
+	}
+	blockers, err := s.blockersOf(ctx, *o.Actor)
+	if err != nil {
+		return nil, err
+	}
+	blocked, err := s.blocks.ListBlocked(ctx, *o.Actor)
+	if err != nil {
+		return nil, err
+	}
+	return uniqueIDs(slices.Concat(blockers, blocked)), nil
+}
+
+// requireUnblocked hides the profile of id from a caller it blocked or that
+// blocked it.
+func (s *UserService) requireUnblocked(ctx context.Context, id uuid.UUID) error {
+	o := OriginFrom(ctx)
+	if s.blocks == nil || o.Actor == nil || o.Admin {
+		return nil
+	}
+	for _, pair := range [][2]uuid.UUID{{id, *o.Actor}, {*o.Actor, id}} {
+		blocked, err := s.IsBlocked(ctx, pair[0], pair[1])
+		if err != nil {
+			return err
+		}
+		if blocked {
+			return ErrUserNotFound
+		}
+	}
+	return nil
+}
+
+// invalidateBlockers drops the cached blockers of blockedID after a change.
+// Until it expires, a set that could not be dropped serves the old blocks.
+func (s *UserService) invalidateBlockers(ctx context.Context, blockedID uuid.UUID) {
+	if s.blockSet == nil {
+		return
+	}
+	if err := s.blockSet.InvalidateBlockers(ctx, blockedID); err != nil {
+		logging.Instance.Warnf("failed to invalidate block set for user %s: %v", blockedID, err)
+	}
+}
@@ -0,0 +1,302 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"userService/internal/events"
+	"userService/internal/model"
+	"userService/internal/transport/response"
+)
+
+type MockBlockRepository struct {
+	mock.Mock
+}
+
+func (m *MockBlockRepository) AddBlock(ctx context.Context, b *model.Block) (bool, error) {
+	args := m.Called(ctx, b)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockBlockRepository) RemoveBlock(ctx context.Context, blockerID, blockedID uuid.UUID) (bool, error) {
+	args := m.Called(ctx, blockerID, blockedID)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockBlockRepository) ListBlockers(ctx context.Context, blockedID uuid.UUID) ([]uuid.UUID, error) {
+	args := m.Called(ctx, blockedID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]uuid.UUID), args.Error(1)
+}
+
+func (m *MockBlockRepository) ListBlocked(ctx context.Context, blockerID uuid.UUID) ([]uuid.UUID, error) {
+	args := m.Called(ctx, blockerID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]uuid.UUID), args.Error(1)
+}
+
+func (m *MockBlockRepository) RemoveBlocksOf(ctx context.Context, userID uuid.UUID) ([]model.Block, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]model.Block), args.Error(1)
+}
+
+type MockBlockSet struct {
+	mock.Mock
+}
+
+func (m *MockBlockSet) Blockers(ctx context.Context, blockedID uuid.UUID) ([]uuid.UUID, bool, error) {
+	args := m.Called(ctx, blockedID)
+	if args.Get(0) == nil {
+		return nil, args.Bool(1), args.Error(2)
+	}
+	return args.Get(0).([]uuid.UUID), args.Bool(1), args.Error(2)
+}
+
+func (m *MockBlockSet) IsBlockedBy(ctx context.Context, blockedID, blockerID uuid.UUID) (bool, bool, error) {
+	args := m.Called(ctx, blockedID, blockerID)
+	return args.Bool(0), args.Bool(1), args.Error(2)
+}
+
+func (m *MockBlockSet) BlockedByAny(ctx context.Context, blockedID uuid.UUID, blockerIDs []uuid.UUID) ([]bool, bool, error) {
+	args := m.Called(ctx, blockedID, blockerIDs)
+	if args.Get(0) == nil {
+		return nil, args.Bool(1), args.Error(2)
+	}
+	return args.Get(0).([]bool), args.Bool(1), args.Error(2)
+}
+
+func (m *MockBlockSet) Generation(ctx context.Context, blockedID uuid.UUID) (int64, error) {
+	args := m.Called(ctx, blockedID)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockBlockSet) StoreBlockers(ctx context.Context, blockedID uuid.UUID, blockers []uuid.UUID, gen int64, ttl time.Duration) error {
+	args := m.Called(ctx, blockedID, blockers, gen, ttl)
+	return args.Error(0)
+}
+
+func (m *MockBlockSet) InvalidateBlockers(ctx context.Context, blockedID uuid.UUID) error {
+	args := m.Called(ctx, blockedID)
+	return args.Error(0)
+}
+
+func TestUserService_Block(t *testing.T) {
+	blockerID, blockedID := uuid.New(), uuid.New()
+	ctx := WithOrigin(context.Background(), Origin{Source: SourceHTTP, Actor: &blockerID})
+
+	t.Run("new blocks end follows and publish", func(t *testing.T) {
+		repo := new(MockUserRepository)
+		blocks := new(MockBlockRepository)
+		set := new(MockBlockSet)
+		follows := new(MockFollowRepository)
+		p := new(MockProducer)
+		cache := new(MockCacheService)
+		repo.On("GetUserById", mock.Anything, blockedID).Return(&model.User{ID: blockedID}, nil)
+		blocks.On("AddBlock", mock.Anything, mock.MatchedBy(func(b *model.Block) bool {
+			return b.BlockerID == blockerID && b.BlockedID == blockedID
+		})).Return(true, nil)
+		follows.On("RemoveFollow", mock.Anything, blockerID, blockedID).Return(false, nil)
+		follows.On("RemoveFollow", mock.Anything, blockedID, blockerID).Return(true, nil)
+		repo.On("AdjustFollowCounts", mock.Anything, blockedID, blockerID, int64(-1)).Return(nil)
+		p.On("Produce", mock.Anything, events.UserUnfollowed, events.FollowPayload{
+			FollowerID: blockedID, FolloweeID: blockerID,
+		}).Return(nil)
+		p.On("Produce", mock.Anything, events.UserBlocked, events.BlockPayload{
+			BlockerID: blockerID, BlockedID: blockedID,
+		}).Return(nil)
+		set.On("InvalidateBlockers", mock.Anything, blockedID).Return(nil)
+		cache.On("Delete", mock.Anything, mock.Anything).Return(nil)
+
+		svc := NewUserService(repo, nil, p, cache).WithFollows(follows).WithBlocks(blocks, set)
+		err := svc.Block(ctx, blockerID, blockedID)
+
+		assert.NoError(t, err)
+		repo.AssertExpectations(t)
+		follows.AssertExpectations(t)
+		p.AssertExpectations(t)
+		set.AssertExpectations(t)
+		cache.AssertNumberOfCalls(t, "Delete", 2)
+	})
+
+	t.Run("blocking twice changes nothing", func(t *testing.T) {
+		repo := new(MockUserRepository)
+		blocks := new(MockBlockRepository)
+		set := new(MockBlockSet)
+		p := new(MockProducer)
+		repo.On("GetUserById", mock.Anything, blockedID).Return(&model.User{ID: blockedID}, nil)
+		blocks.On("AddBlock", mock.Anything, mock.Anything).Return(false, nil)
+
+		svc := NewUserService(repo, nil, p, nil).WithBlocks(blocks, set)
+		err := svc.Block(ctx, blockerID, blockedID)
+
+		assert.NoError(t, err)
+		p.AssertNotCalled(t, "Produce", mock.Anything, mock.Anything, mock.Anything)
+		set.AssertNotCalled(t, "InvalidateBlockers", mock.Anything, mock.Anything)
+	})
+
+	t.Run("users cannot block themselves", func(t *testing.T) {
+		svc := NewUserService(new(MockUserRepository), nil, nil, nil).WithBlocks(new(MockBlockRepository), nil)
+		err := svc.Block(ctx, blockerID, blockerID)
+
+		assert.ErrorIs(t, err, ErrSelfBlock)
+		assert.ErrorIs(t, err, ErrValidation)
+	})
+}
+
+func TestUserService_Unblock(t *testing.T) {
+	blockerID, blockedID := uuid.New(), uuid.New()
+	blocks := new(MockBlockRepository)
+	set := new(MockBlockSet)
+	p := new(MockProducer)
+	blocks.On("RemoveBlock", mock.Anything, blockerID, blockedID).Return(true, nil)
+	p.On("Produce", mock.Anything, events.UserUnblocked, events.BlockPayload{
+		BlockerID: blockerID, BlockedID: blockedID,
+	}).Return(nil)
+	set.On("InvalidateBlockers", mock.Anything, blockedID).Return(nil)
+
+	svc := NewUserService(new(MockUserRepository), nil, p, nil).WithBlocks(blocks, set)
+	err := svc.Unblock(context.Background(), blockerID, blockedID)
+
+	assert.NoError(t, err)
+	p.AssertExpectations(t)
+	set.AssertExpectations(t)
+}
+
+func TestUserService_IsBlocked(t *testing.T) {
+	blockerID, blockedID := uuid.New(), uuid.New()
+
+	t.Run("cached sets are served without the repository", func(t *testing.T) {
+		blocks := new(MockBlockRepository)
+		set := new(MockBlockSet)
+		set.On("IsBlockedBy", mock.Anything, blockedID, blockerID).Return(true, true, nil)
+
+		svc := NewUserService(new(MockUserRepository), nil, nil, nil).WithBlocks(blocks, set)
+		blocked, err := svc.IsBlocked(context.Background(), blockerID, blockedID)
+
+		assert.NoError(t, err)
+		assert.True(t, blocked)
+		blocks.AssertNotCalled(t, "ListBlockers", mock.Anything, mock.Anything)
+	})
+
+	t.Run("missing sets are loaded and cached", func(t *testing.T) {
+		blocks := new(MockBlockRepository)
+		set := new(MockBlockSet)
+		set.On("IsBlockedBy", mock.Anything, blockedID, blockerID).Return(false, false, nil)
+		set.On("Blockers", mock.Anything, blockedID).Return(nil, false, nil)
+		blocks.On("ListBlockers", mock.Anything, blockedID).Return([]uuid.UUID{}, nil)
+		set.On("Generation", mock.Anything, blockedID).Return(int64(3), nil)
+		set.On("StoreBlockers", mock.Anything, blockedID, []uuid.UUID{}, int64(3), blockSetTTL).Return(nil)
+
+		svc := NewUserService(new(MockUserRepository), nil, nil, nil).WithBlocks(blocks, set)
+		blocked, err := svc.IsBlocked(context.Background(), blockerID, blockedID)
+
+		assert.NoError(t, err)
+		assert.False(t, blocked)
+		set.AssertExpectations(t)
+	})
+}
+
+func TestUserService_BlockedBy(t *testing.T) {
+	blockedID := uuid.New()
+	a, b, c := uuid.New(), uuid.New(), uuid.New()
+
+	t.Run("cached sets answer the batch in one lookup", func(t *testing.T) {
+		set := new(MockBlockSet)
+		set.On("BlockedByAny", mock.Anything, blockedID, []uuid.UUID{a, b, c}).Return([]bool{true, false, true}, true, nil)
+
+		svc := NewUserService(new(MockUserRepository), nil, nil, nil).WithBlocks(new(MockBlockRepository), set)
+		blockers, err := svc.BlockedBy(context.Background(), blockedID, []uuid.UUID{a, b, c, a})
+
+		assert.NoError(t, err)
+		assert.Equal(t, []uuid.UUID{a, c}, blockers)
+		set.AssertNotCalled(t, "Blockers", mock.Anything, mock.Anything)
+
+		_, err = svc.BlockedBy(context.Background(), blockedID, nil)
+		assert.ErrorIs(t, err, ErrInvalidBatch)
+	})
+
+	t.Run("missing sets are loaded and cached", func(t *testing.T) {
+		blocks := new(MockBlockRepository)
+		set := new(MockBlockSet)
+		set.On("BlockedByAny", mock.Anything, blockedID, []uuid.UUID{a, b}).Return(nil, false, nil)
+		set.On("Blockers", mock.Anything, blockedID).Return(nil, false, nil)
+		blocks.On("ListBlockers", mock.Anything, blockedID).Return([]uuid.UUID{b}, nil)
+		set.On("Generation", mock.Anything, blockedID).Return(int64(0), nil)
+		set.On("StoreBlockers", mock.Anything, blockedID, []uuid.UUID{b}, int64(0), blockSetTTL).Return(nil)
+
+		svc := NewUserService(new(MockUserRepository), nil, nil, nil).WithBlocks(blocks, set)
+		blockers, err := svc.BlockedBy(context.Background(), blockedID, []uuid.UUID{a, b})
+
+		assert.NoError(t, err)
+		assert.Equal(t, []uuid.UUID{b}, blockers)
+		set.AssertExpectations(t)
+	})
+}
+
+func TestUserService_Blocks_Visibility(t *testing.T) {
+	blockerID, blockedID := uuid.New(), uuid.New()
+	cached, _ := json.Marshal(response.UserResponse{ID: blockerID, Firstname: "Blocker"})
+	cachedBlocked, _ := json.Marshal(response.UserResponse{ID: blockedID, Firstname: "Blocked"})
+	set := new(MockBlockSet)
+	set.On("Blockers", mock.Anything, blockedID).Return([]uuid.UUID{blockerID}, true, nil)
+	set.On("Blockers", mock.Anything, blockerID).Return([]uuid.UUID{}, true, nil)
+	set.On("IsBlockedBy", mock.Anything, blockedID, blockerID).Return(true, true, nil)
+	set.On("IsBlockedBy", mock.Anything, blockerID, blockedID).Return(false, true, nil)
+
+	t.Run("profiles are hidden in both directions", func(t *testing.T) {
+		cache := new(MockCacheService)
+		cache.On("Get", mock.Anything, fmt.Sprintf("user:%s", blockerID)).Return(string(cached), nil)
+		cache.On("Get", mock.Anything, fmt.Sprintf("user:%s", blockedID)).Return(string(cachedBlocked), nil)
+
+		svc := NewUserService(new(MockUserRepository), nil, nil, cache).WithBlocks(new(MockBlockRepository), set)
+		_, err := svc.GetUserById(WithOrigin(context.Background(), Origin{Source: SourceHTTP, Actor: &blockedID}), blockerID)
+		assert.ErrorIs(t, err, ErrUserNotFound)
+		_, err = svc.GetUserById(WithOrigin(context.Background(), Origin{Source: SourceHTTP, Actor: &blockerID}), blockedID)
+		assert.ErrorIs(t, err, ErrUserNotFound)
+
+		other := uuid.New()
+		set.On("IsBlockedBy", mock.Anything, other, blockerID).Return(false, true, nil)
+		set.On("IsBlockedBy", mock.Anything, blockerID, other).Return(false, true, nil)
+		resp, err := svc.GetUserById(WithOrigin(context.Background(), Origin{Source: SourceHTTP, Actor: &other}), blockerID)
+		assert.NoError(t, err)
+		assert.Equal(t, "Blocker", resp.Firstname)
+
+		resp, err = svc.GetUserById(WithOrigin(context.Background(), Origin{Source: SourceHTTP, Actor: &blockedID, Admin: true}), blockerID)
+		assert.NoError(t, err)
+		assert.Equal(t, blockerID, resp.ID)
+	})
+
+	t.Run("listings exclude blockers and blocked users", func(t *testing.T) {
+		blocks := new(MockBlockRepository)
+		blocks.On("ListBlocked", mock.Anything, blockedID).Return([]uuid.UUID{}, nil)
+		blocks.On("ListBlocked", mock.Anything, blockerID).Return([]uuid.UUID{blockedID}, nil)
+		repo := new(MockUserRepository)
+		repo.On("ListUsers", mock.Anything, mock.MatchedBy(func(q ListQuery) bool {
+			return assert.ObjectsAreEqual([]uuid.UUID{blockerID}, q.Filter.ExcludeIDs)
+		})).Return([]model.User{}, nil).Once()
+		repo.On("ListUsers", mock.Anything, mock.MatchedBy(func(q ListQuery) bool {
+			return assert.ObjectsAreEqual([]uuid.UUID{blockedID}, q.Filter.ExcludeIDs)
+		})).Return([]model.User{}, nil).Once()
+
+		svc := NewUserService(repo, nil, nil, nil).WithBlocks(blocks, set)
+		_, err := svc.ListUsers(WithOrigin(context.Background(), Origin{Source: SourceHTTP, Actor: &blockedID}), ListUsersParams{})
+		assert.NoError(t, err)
+		_, err = svc.ListUsers(WithOrigin(context.Background(), Origin{Source: SourceHTTP, Actor: &blockerID}), ListUsersParams{})
+		assert.NoError(t, err)
+
+		repo.AssertExpectations(t)
+	})
+}
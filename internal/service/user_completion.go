@@ -0,0 +1,141 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"userService/internal/events"
+	"userService/internal/model"
+	"userService/internal/transport/response"
+)
+
+// completionFields are the profile fields a completion policy may name.
+var completionFields = []string{
+	FieldFirstname, FieldLastname, FieldAbout, FieldDateOfBirth,
+	FieldGender, FieldLocation, FieldSocials, "avatar_url",
+}
+
+// CompletionPolicy decides when a profile is complete. A profile is complete
+// once every required field is set; recommended fields only count towards the
+// completeness percentage. Names are always required.
+type CompletionPolicy struct {
+	Required    []string
+	Recommended []string
+}
+
+// DefaultCompletionPolicy requires names and recommends the rest of the profile.
+func DefaultCompletionPolicy() CompletionPolicy {
+	return CompletionPolicy{
+		Required: []string{FieldFirstname, FieldLastname},
+		Recommended: []string{
+			FieldAbout, FieldDateOfBirth, FieldGender, FieldLocation, FieldSocials, "avatar_url",
+		},
+	}
+}
+
+// NewCompletionPolicy builds a policy from configured field names. Names are
+// added to the required fields, and a field both required and recommended
+// counts as required.
+func NewCompletionPolicy(required, recommended []string) (CompletionPolicy, error) {
+	p := CompletionPolicy{Required: []string{FieldFirstname, FieldLastname}}
+	for _, field := range required {
+		if !slices.Contains(completionFields, field) {
+			return CompletionPolicy{}, fmt.Errorf("unknown required profile field %q", field)
+		}
+		if !slices.Contains(p.Required, field) {
+			p.Required = append(p.Required, field)
+		}
+	}
+	for _, field := range recommended {
+		if !slices.Contains(completionFields, field) {
+			return CompletionPolicy{}, fmt.Errorf("unknown recommended profile field %q", field)
+		}
+		if !slices.Contains(p.Required, field) && !slices.Contains(p.Recommended, field) {
+			p.Recommended = append(p.Recommended, field)
+		}
+	}
+	return p, nil
+}
+
+// Completion is how far a profile is from meeting a CompletionPolicy.
+type Completion struct {
+	// Percent of the required and recommended fields that are set, 0 to 100
+	Percent int
+	// Missing lists the unset fields, required ones first
+	Missing []string
+	// Complete is true once every required field is set
+	Complete bool
+}
+
+// Evaluate reports the completion of u under p.
+func (p CompletionPolicy) Evaluate(u *model.User) Completion {
+	c := Completion{Complete: true}
+	for _, field := range p.Required {
+		if fieldValue(u, field) == nil {
+			c.Missing = append(c.Missing, field)
+			c.Complete = false
+		}
+	}
+	for _, field := range p.Recommended {
+		if fieldValue(u, field) == nil {
+			c.Missing = append(c.Missing, field)
+		}
+	}
+
+	total := len(p.Required) + len(p.Recommended)
+	if total == 0 {
+		c.Percent = 100
+	} else {
+		c.Percent = (total - len(c.Missing)) * 100 / total
+	}
+	return c
+}
+
+// track updates the completion state of after, the profile about to replace
+// before, and reports whether it is becoming complete for the first time.
+// Profiles that were never flagged as incomplete get a completion time but
+// no event; new profiles are tracked against an incomplete empty one, so
+// ProfileCompleted also covers profiles created complete.
+func (p CompletionPolicy) track(before, after *model.User, now time.Time) bool {
+	complete := p.Evaluate(after).Complete
+	after.NeedsCompletion = !complete
+	if !complete || before.CompletedAt != nil {
+		return false
+	}
+	completedAt := now.UTC()
+	after.CompletedAt = &completedAt
+	return before.NeedsCompletion
+}
+
+// WithCompletionPolicy replaces DefaultCompletionPolicy.
+func (s *UserService) WithCompletionPolicy(policy CompletionPolicy) *UserService {
+	s.completion = policy
+	return s
+}
+
+// publishCompleted emits ProfileCompleted for u; call it inside the write's transaction.
+func (s *UserService) publishCompleted(ctx context.Context, u *model.User) error {
+	return s.events.publish(ctx, events.ProfileCompleted, events.ProfileCompletedPayload{
+		UserID:      u.ID,
+		CompletedAt: *u.CompletedAt,
+	})
+}
+
+// toResponse maps u together with its completion under the service's policy.
+func (s *UserService) toResponse(u model.User) response.UserResponse {
+	ur := s.mapper.Map(u)
+	c := s.completion.Evaluate(&u)
+	ur.Completeness, ur.MissingFields = c.Percent, c.Missing
+	return ur
+}
+
+// toResponses maps users to the views the caller of ctx may see.
+func (s *UserService) toResponses(ctx context.Context, users []model.User) []response.UserResponse {
+	out := make([]response.UserResponse, 0, len(users))
+	for _, u := range users {
+		out = append(out, present(ctx, s.toResponse(u)))
+	}
+	return out
+}
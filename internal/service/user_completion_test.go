@@ -0,0 +1,74 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"userService/internal/model"
+)
+
+func TestNewCompletionPolicy(t *testing.T) {
+	t.Run("names are always required and required fields win", func(t *testing.T) {
+		p, err := NewCompletionPolicy([]string{FieldLocation}, []string{FieldLocation, FieldAbout, FieldAbout})
+
+		assert.NoError(t, err)
+		assert.Equal(t, []string{FieldFirstname, FieldLastname, FieldLocation}, p.Required)
+		assert.Equal(t, []string{FieldAbout}, p.Recommended)
+	})
+
+	t.Run("unknown fields are rejected", func(t *testing.T) {
+		_, err := NewCompletionPolicy(nil, []string{"email"})
+
+		assert.Error(t, err)
+	})
+}
+
+func TestCompletionPolicy_Evaluate(t *testing.T) {
+	p := CompletionPolicy{
+		Required:    []string{FieldFirstname, FieldLastname},
+		Recommended: []string{FieldAbout, FieldLocation},
+	}
+
+	c := p.Evaluate(&model.User{Firstname: "Test", Location: "Almaty"})
+	assert.False(t, c.Complete)
+	assert.Equal(t, 50, c.Percent)
+	assert.Equal(t, []string{FieldLastname, FieldAbout}, c.Missing)
+
+	c = p.Evaluate(&model.User{Firstname: "Test", Lastname: "User"})
+	assert.True(t, c.Complete)
+	assert.Equal(t, 50, c.Percent)
+	assert.Equal(t, []string{FieldAbout, FieldLocation}, c.Missing)
+}
+
+func TestCompletionPolicy_track(t *testing.T) {
+	p := DefaultCompletionPolicy()
+	now := time.Now()
+
+	t.Run("first completion of a flagged profile is reported once", func(t *testing.T) {
+		before := &model.User{NeedsCompletion: true}
+		after := &model.User{Firstname: "Test", Lastname: "User", NeedsCompletion: true}
+
+		assert.True(t, p.track(before, after, now))
+		assert.False(t, after.NeedsCompletion)
+		assert.NotNil(t, after.CompletedAt)
+
+		// Becoming incomplete and complete again is not a first completion
+		again := *after
+		again.Lastname = ""
+		assert.False(t, p.track(after, &again, now))
+		assert.True(t, again.NeedsCompletion)
+
+		restored := again
+		restored.Lastname = "User"
+		assert.False(t, p.track(&again, &restored, now))
+		assert.Equal(t, after.CompletedAt, restored.CompletedAt)
+	})
+
+	t.Run("profiles never flagged get a completion time silently", func(t *testing.T) {
+		after := &model.User{Firstname: "Test", Lastname: "User"}
+
+		assert.False(t, p.track(&model.User{}, after, now))
+		assert.NotNil(t, after.CompletedAt)
+	})
+}
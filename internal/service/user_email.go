@@ -0,0 +1,38 @@
+package service
+
+import (
+	"context"
+	"strings"
+
+	"userService/internal/transport/response"
+)
+
+// NormalizeEmail is the canonical form emails are stored and looked up in.
+func NormalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
+// GetUserByEmail returns the live profile registered with email.
+// Soft-deleted profiles yield ErrUserDeleted.
+func (s *UserService) GetUserByEmail(ctx context.Context, email string) (*response.UserResponse, error) {
+	email = NormalizeEmail(email)
+	if !validEmail(email) {
+		verr := &ValidationError{}
+		verr.add("email", "must be a valid email address")
+		return nil, verr
+	}
+
+	user, err := s.userRepo.GetUserByEmail(ctx, email)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, ErrUserNotFound
+	}
+	if user.DeletedAt != nil {
+		return nil, ErrUserDeleted
+	}
+
+	ur := present(ctx, s.toResponse(*user))
+	return &ur, nil
+}
@@ -0,0 +1,83 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"userService/internal/events"
+	"userService/internal/model"
+)
+
+func TestUserService_GetUserByEmail(t *testing.T) {
+	t.Run("email is normalized before lookup", func(t *testing.T) {
+		repo := new(MockUserRepository)
+		repo.On("GetUserByEmail", mock.Anything, "jane@example.com").
+			Return(&model.User{ID: uuid.New(), Email: "jane@example.com"}, nil)
+
+		svc := NewUserService(repo, nil, nil, nil)
+		ctx := WithOrigin(context.Background(), Origin{Source: SourceHTTP, Admin: true})
+		resp, err := svc.GetUserByEmail(ctx, "  Jane@Example.COM ")
+
+		assert.NoError(t, err)
+		assert.Equal(t, "jane@example.com", resp.Email)
+	})
+
+	t.Run("malformed email is rejected", func(t *testing.T) {
+		svc := NewUserService(new(MockUserRepository), nil, nil, nil)
+		_, err := svc.GetUserByEmail(context.Background(), "Jane <jane@example.com>")
+
+		assert.ErrorIs(t, err, ErrValidation)
+	})
+
+	t.Run("unknown email", func(t *testing.T) {
+		repo := new(MockUserRepository)
+		repo.On("GetUserByEmail", mock.Anything, "nobody@example.com").Return(nil, nil)
+
+		svc := NewUserService(repo, nil, nil, nil)
+		_, err := svc.GetUserByEmail(context.Background(), "nobody@example.com")
+
+		assert.ErrorIs(t, err, ErrUserNotFound)
+	})
+}
+
+func TestCreateUserHandler_NormalizesEmailAndResolvesConflicts(t *testing.T) {
+	data, _ := json.Marshal(events.UserCreatedPayload{
+		UserID:    uuid.New(),
+		Firstname: "Jane",
+		Lastname:  "Doe",
+		Email:     " JANE@example.com",
+	})
+
+	t.Run("a taken email is left for the owner to enter", func(t *testing.T) {
+		repo := new(MockUserRepository)
+		repo.On("CreateUser", mock.Anything, mock.MatchedBy(func(u *model.User) bool {
+			return u.Email == "jane@example.com"
+		})).Return(&ConflictError{Field: "email"}).Once()
+		repo.On("CreateUser", mock.Anything, mock.MatchedBy(func(u *model.User) bool {
+			return u.Email == "" && u.Firstname == "Jane" && u.NeedsCompletion && u.CompletedAt == nil
+		})).Return(nil).Once()
+
+		assert.NoError(t, NewUserService(repo, nil, nil, nil).CreateUserHandler()(data))
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("a redelivered event is skipped", func(t *testing.T) {
+		repo := new(MockUserRepository)
+		repo.On("CreateUser", mock.Anything, mock.Anything).Return(&ConflictError{Field: "id"}).Once()
+
+		assert.NoError(t, NewUserService(repo, nil, nil, nil).CreateUserHandler()(data))
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("other failures are returned for a retry", func(t *testing.T) {
+		repo := new(MockUserRepository)
+		repo.On("CreateUser", mock.Anything, mock.Anything).Return(errors.New("connection reset")).Once()
+
+		assert.ErrorContains(t, NewUserService(repo, nil, nil, nil).CreateUserHandler()(data), "connection reset")
+	})
+}
@@ -3,7 +3,9 @@ package service
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"time"
 	"userService/internal/model"
 
 	"github.com/Sayan80bayev/go-project/pkg/logging"
@@ -13,30 +15,68 @@ import (
 
 var logger = logging.GetLogger()
 
-func CreateUserHandler(repository UserRepository) func(data json.RawMessage) error {
+// CreateUserHandler creates profiles from registration events. Profiles that
+// already meet the completion policy are created complete and announced with
+// ProfileCompleted in the same transaction. Invalid fields are left empty, and
+// profiles missing any of them or the email are marked as needing completion.
+// An email already held by another live profile is left empty too;
+// redelivered events are skipped and other failures returned for a retry.
+func (s *UserService) CreateUserHandler() func(data json.RawMessage) error {
 	return func(data json.RawMessage) error {
-		ctx := context.WithoutCancel(context.Background())
+		ctx := WithOrigin(context.WithoutCancel(context.Background()), Origin{Source: SourceEvent})
 
 		var e events.UserCreatedPayload
 		if err := json.Unmarshal(data, &e); err != nil {
 			return fmt.Errorf("failed to unmarshal UserCreatedPayload: %w", err)
 		}
 
-		needsCompletion := false
-		if e.Firstname == "" || e.Lastname == "" || e.Firstname == "null" || e.Lastname == "null" {
-			needsCompletion = true
+		// Identity providers send "null" for names the user never entered
+		firstname, lastname := e.Firstname, e.Lastname
+		if firstname == "null" {
+			firstname = ""
+		}
+		if lastname == "null" {
+			lastname = ""
 		}
 
 		user := &model.User{
 			ID:              e.UserID,
-			Firstname:       e.Firstname,
-			Lastname:        e.Lastname,
-			Email:           e.Email,
-			NeedsCompletion: needsCompletion,
+			Firstname:       firstname,
+			Lastname:        lastname,
+			Email:           NormalizeEmail(e.Email),
+			NeedsCompletion: true,
+		}
+		invalid := clearInvalid(user)
+		if invalid != nil {
+			logger.Warnf("creating user %s without invalid fields: %v", e.UserID, invalid)
+		}
+		// Until it exists the profile counts as incomplete, so one created
+		// complete is announced like any other
+		completed := s.completion.track(&model.User{NeedsCompletion: true}, user, time.Now())
+		if invalid != nil || user.Email == "" {
+			// Keep the profile so the owner can complete what the identity
+			// provider left out; dropping the event would leave them without one
+			user.NeedsCompletion, user.CompletedAt = true, nil
+			completed = false
 		}
 
-		if err := repository.CreateUser(ctx, user); err != nil {
-			logger.Error("failed to create user", err)
+		err := s.createProfile(ctx, user, completed)
+		var conflict *ConflictError
+		if errors.As(err, &conflict) && conflict.Field == "email" {
+			// Another live profile holds the email: create this one without
+			// it, like an invalid email, so the owner can enter their own
+			logger.Warnf("creating user %s without email %q: %v", e.UserID, user.Email, conflict)
+			user.Email = ""
+			user.NeedsCompletion, user.CompletedAt = true, nil
+			err = s.createProfile(ctx, user, false)
+		}
+		if errors.As(err, &conflict) && conflict.Field == "id" {
+			// A redelivered event; the profile already exists
+			logger.Infof("skipping user %s: %v", e.UserID, conflict)
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to create user %s: %w", e.UserID, err)
 		}
 
 		logger.Infof("Created user profile: %+v", user)
@@ -44,6 +84,22 @@ func CreateUserHandler(repository UserRepository) func(data json.RawMessage) err
 	}
 }
 
+// createProfile stores user with its creation revision, announcing it as
+// completed when completed is set.
+func (s *UserService) createProfile(ctx context.Context, user *model.User, completed bool) error {
+	return s.events.atomically(ctx, func(ctx context.Context) error {
+		if err := s.userRepo.CreateUser(ctx, user); err != nil {
+			return err
+		}
+		if completed {
+			if err := s.publishCompleted(ctx, user); err != nil {
+				return err
+			}
+		}
+		return recordRevision(ctx, s.revisions, ActionCreate, nil, user)
+	})
+}
+
 // UserUpdatedHandler handles user update events
 func UserUpdatedHandler(fileStorage storage.FileStorage) func(data json.RawMessage) error {
 	return func(data json.RawMessage) error {
@@ -0,0 +1,264 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/Sayan80bayev/go-project/pkg/logging"
+	"github.com/google/uuid"
+	"userService/internal/events"
+	"userService/internal/model"
+	"userService/internal/transport/response"
+)
+
+// followSort is the only ordering of follower and following lists, most
+// recent follow first. Cursor.ID is the user on the other end of the edge.
+const followSort = "-followed_at"
+
+// ErrSelfFollow means a user tried to follow or unfollow themselves.
+var ErrSelfFollow = kindError(ErrValidation, "users cannot follow themselves")
+
+// FollowRepository stores who follows whom.
+type FollowRepository interface {
+	// AddFollow stores f unless the edge exists and reports whether it was new.
+	AddFollow(ctx context.Context, f *model.Follow) (bool, error)
+	// RemoveFollow deletes the edge and reports whether it existed.
+	RemoveFollow(ctx context.Context, followerID, followeeID uuid.UUID) (bool, error)
+	IsFollowing(ctx context.Context, followerID, followeeID uuid.UUID) (bool, error)
+	// ListFollowers returns up to limit follows of userID, newest first, starting after the cursor.
+	ListFollowers(ctx context.Context, userID uuid.UUID, limit int, after *Cursor) ([]model.Follow, error)
+	// ListFollowing returns up to limit follows by userID, newest first, starting after the cursor.
+	ListFollowing(ctx context.Context, userID uuid.UUID, limit int, after *Cursor) ([]model.Follow, error)
+	// RemoveFollowsOf deletes every edge from or to userID and returns them.
+	RemoveFollowsOf(ctx context.Context, userID uuid.UUID) ([]model.Follow, error)
+}
+
+// FollowParams is the caller-facing input of ListFollowers and ListFollowing.
+type FollowParams struct {
+	Limit  int
+	Cursor string
+}
+
+// WithFollows enables the follow graph.
+func (s *UserService) WithFollows(follows FollowRepository) *UserService {
+	s.follows = follows
+	return s
+}
+
+// Follow makes followerID follow followeeID. Following twice is a no-op and
+// publishes nothing.
+func (s *UserService) Follow(ctx context.Context, followerID, followeeID uuid.UUID) error {
+	if followerID == followeeID {
+		return ErrSelfFollow
+	}
+
+	followee, err := s.userRepo.GetUserById(ctx, followeeID)
+	if err != nil {
+		return err
+	}
+	if followee == nil || !canSee(ctx, followee.ID, followee.Moderation != nil) {
+		return fmt.Errorf("%w: %s", ErrUserNotFound, followeeID)
+	}
+	if followee.DeletedAt != nil {
+		return ErrUserDeleted
+	}
+	if s.blocks != nil {
+		blocked, err := s.IsBlocked(ctx, followeeID, followerID)
+		if err != nil {
+			return err
+		}
+		if blocked {
+			return fmt.Errorf("%w: %s", ErrUserNotFound, followeeID)
+		}
+	}
+
+	follower, err := s.userRepo.GetUserById(ctx, followerID)
+	if err != nil {
+		return err
+	}
+	if follower == nil {
+		return fmt.Errorf("%w: %s", ErrUserNotFound, followerID)
+	}
+	if follower.DeletedAt != nil {
+		return ErrUserDeleted
+	}
+	if follower.Moderation != nil && !OriginFrom(ctx).Admin {
+		return ErrAccountRestricted
+	}
+
+	var created bool
+	err = s.events.atomically(ctx, func(ctx context.Context) error {
+		var err error
+		f := &model.Follow{FollowerID: followerID, FolloweeID: followeeID, CreatedAt: time.Now().UTC()}
+		if created, err = s.follows.AddFollow(ctx, f); err != nil || !created {
+			return err
+		}
+		if err = s.userRepo.AdjustFollowCounts(ctx, followerID, followeeID, 1); err != nil {
+			return err
+		}
+		return s.events.publish(ctx, events.UserFollowed, events.FollowPayload{
+			FollowerID: followerID,
+			FolloweeID: followeeID,
+		})
+	})
+	if err != nil {
+		logging.Instance.Errorf("failed to make user %s follow %s: %v", followerID, followeeID, err)
+		return err
+	}
+	if created {
+		s.invalidateFollowCounts(ctx, followerID, followeeID)
+	}
+	return nil
+}
+
+// Unfollow removes the follow of followeeID by followerID. Unfollowing a user
+// that is not followed is a no-op, and restricted or deleted followees can
+// still be unfollowed.
+func (s *UserService) Unfollow(ctx context.Context, followerID, followeeID uuid.UUID) error {
+	if followerID == followeeID {
+		return ErrSelfFollow
+	}
+
+	var removed bool
+	err := s.events.atomically(ctx, func(ctx context.Context) error {
+		var err error
+		removed, err = s.removeFollow(ctx, followerID, followeeID)
+		return err
+	})
+	if err != nil {
+		logging.Instance.Errorf("failed to make user %s unfollow %s: %v", followerID, followeeID, err)
+		return err
+	}
+	if removed {
+		s.invalidateFollowCounts(ctx, followerID, followeeID)
+	}
+	return nil
+}
+
+// removeFollow deletes the edge with its counts and event and reports whether
+// it existed. It must run inside events.atomically.
+func (s *UserService) removeFollow(ctx context.Context, followerID, followeeID uuid.UUID) (bool, error) {
+	removed, err := s.follows.RemoveFollow(ctx, followerID, followeeID)
+	if err != nil || !removed {
+		return false, err
+	}
+	if err = s.userRepo.AdjustFollowCounts(ctx, followerID, followeeID, -1); err != nil {
+		return false, err
+	}
+	return true, s.events.publish(ctx, events.UserUnfollowed, events.FollowPayload{
+		FollowerID: followerID,
+		FolloweeID: followeeID,
+	})
+}
+
+// IsFollowing reports whether followerID follows followeeID.
+func (s *UserService) IsFollowing(ctx context.Context, followerID, followeeID uuid.UUID) (bool, error) {
+	return s.follows.IsFollowing(ctx, followerID, followeeID)
+}
+
+// ListFollowers returns one page of the users following userID, most recent
+// first. Deleted and hidden followers, and those that blocked the caller, are
+// left out of the page. Soft-deleted followers still count towards
+// FollowersCount until their profile is purged.
+func (s *UserService) ListFollowers(ctx context.Context, userID uuid.UUID, p FollowParams) (*response.UserPageResponse, error) {
+	return s.listFollows(ctx, userID, p, s.follows.ListFollowers, func(f model.Follow) uuid.UUID { return f.FollowerID })
+}
+
+// ListFollowing returns one page of the users userID follows, most recent
+// first. Deleted and hidden followees, and those that blocked the caller, are
+// left out of the page. Soft-deleted followees still count towards
+// FollowingCount until their profile is purged.
+func (s *UserService) ListFollowing(ctx context.Context, userID uuid.UUID, p FollowParams) (*response.UserPageResponse, error) {
+	return s.listFollows(ctx, userID, p, s.follows.ListFollowing, func(f model.Follow) uuid.UUID { return f.FolloweeID })
+}
+
+func (s *UserService) listFollows(
+	ctx context.Context,
+	userID uuid.UUID,
+	p FollowParams,
+	list func(ctx context.Context, userID uuid.UUID, limit int, after *Cursor) ([]model.Follow, error),
+	other func(model.Follow) uuid.UUID,
+) (*response.UserPageResponse, error) {
+	limit, err := normalizeLimit(p.Limit)
+	if err != nil {
+		return nil, err
+	}
+
+	var after *Cursor
+	if p.Cursor != "" {
+		if after, err = DecodeCursor(p.Cursor); err != nil {
+			return nil, err
+		}
+		if after.Sort != followSort {
+			return nil, ErrInvalidCursor
+		}
+	}
+
+	user, err := s.userRepo.GetUserById(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil || !canSee(ctx, user.ID, user.Moderation != nil) {
+		return nil, ErrUserNotFound
+	}
+	if user.DeletedAt != nil {
+		return nil, ErrUserDeleted
+	}
+	hidden, err := s.hiddenByBlocks(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if slices.Contains(hidden, userID) {
+		return nil, ErrUserNotFound
+	}
+
+	// Fetch one extra edge to learn whether another page exists
+	follows, err := list(ctx, userID, limit+1, after)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &response.UserPageResponse{Items: []response.UserResponse{}}
+	if len(follows) > limit {
+		follows = follows[:limit]
+		last := follows[limit-1]
+		page.NextCursor = Cursor{Sort: followSort, Value: last.CreatedAt, ID: other(last)}.Encode()
+	}
+	if len(follows) == 0 {
+		return page, nil
+	}
+
+	ids := make([]uuid.UUID, len(follows))
+	for i, f := range follows {
+		ids[i] = other(f)
+	}
+	users, err := s.userRepo.GetUsersByIds(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[uuid.UUID]model.User, len(users))
+	for _, u := range users {
+		byID[u.ID] = u
+	}
+
+	// Keep the order of the edges, not of the lookup
+	for _, id := range ids {
+		u, ok := byID[id]
+		if !ok || !canSee(ctx, u.ID, u.Moderation != nil) || slices.Contains(hidden, id) {
+			continue
+		}
+		page.Items = append(page.Items, present(ctx, s.toResponse(u)))
+	}
+	return page, nil
+}
+
+// invalidateFollowCounts drops the cached profiles whose counts just changed.
+func (s *UserService) invalidateFollowCounts(ctx context.Context, ids ...uuid.UUID) {
+	for _, id := range ids {
+		if err := s.cache.Delete(ctx, fmt.Sprintf("user:%s", id)); err != nil {
+			logging.Instance.Warnf("failed to invalidate cache for user %s: %v", id, err)
+		}
+	}
+}
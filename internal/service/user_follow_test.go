@@ -0,0 +1,203 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"userService/internal/events"
+	"userService/internal/model"
+)
+
+type MockFollowRepository struct {
+	mock.Mock
+}
+
+func (m *MockFollowRepository) AddFollow(ctx context.Context, f *model.Follow) (bool, error) {
+	args := m.Called(ctx, f)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockFollowRepository) RemoveFollow(ctx context.Context, followerID, followeeID uuid.UUID) (bool, error) {
+	args := m.Called(ctx, followerID, followeeID)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockFollowRepository) IsFollowing(ctx context.Context, followerID, followeeID uuid.UUID) (bool, error) {
+	args := m.Called(ctx, followerID, followeeID)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockFollowRepository) ListFollowers(ctx context.Context, userID uuid.UUID, limit int, after *Cursor) ([]model.Follow, error) {
+	args := m.Called(ctx, userID, limit, after)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]model.Follow), args.Error(1)
+}
+
+func (m *MockFollowRepository) ListFollowing(ctx context.Context, userID uuid.UUID, limit int, after *Cursor) ([]model.Follow, error) {
+	args := m.Called(ctx, userID, limit, after)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]model.Follow), args.Error(1)
+}
+
+func (m *MockFollowRepository) RemoveFollowsOf(ctx context.Context, userID uuid.UUID) ([]model.Follow, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]model.Follow), args.Error(1)
+}
+
+func TestUserService_Follow(t *testing.T) {
+	followerID, followeeID := uuid.New(), uuid.New()
+	ctx := WithOrigin(context.Background(), Origin{Source: SourceHTTP, Actor: &followerID})
+
+	t.Run("new follows adjust counts and publish", func(t *testing.T) {
+		repo := new(MockUserRepository)
+		follows := new(MockFollowRepository)
+		p := new(MockProducer)
+		cache := new(MockCacheService)
+		repo.On("GetUserById", mock.Anything, followeeID).Return(&model.User{ID: followeeID}, nil)
+		repo.On("GetUserById", mock.Anything, followerID).Return(&model.User{ID: followerID}, nil)
+		follows.On("AddFollow", mock.Anything, mock.MatchedBy(func(f *model.Follow) bool {
+			return f.FollowerID == followerID && f.FolloweeID == followeeID && !f.CreatedAt.IsZero()
+		})).Return(true, nil)
+		repo.On("AdjustFollowCounts", mock.Anything, followerID, followeeID, int64(1)).Return(nil)
+		p.On("Produce", mock.Anything, events.UserFollowed, events.FollowPayload{
+			FollowerID: followerID, FolloweeID: followeeID,
+		}).Return(nil)
+		cache.On("Delete", mock.Anything, fmt.Sprintf("user:%s", followerID)).Return(nil)
+		cache.On("Delete", mock.Anything, fmt.Sprintf("user:%s", followeeID)).Return(nil)
+
+		svc := NewUserService(repo, nil, p, cache).WithFollows(follows)
+		err := svc.Follow(ctx, followerID, followeeID)
+
+		assert.NoError(t, err)
+		repo.AssertExpectations(t)
+		p.AssertExpectations(t)
+		cache.AssertExpectations(t)
+	})
+
+	t.Run("following twice changes nothing", func(t *testing.T) {
+		repo := new(MockUserRepository)
+		follows := new(MockFollowRepository)
+		p := new(MockProducer)
+		repo.On("GetUserById", mock.Anything, followeeID).Return(&model.User{ID: followeeID}, nil)
+		repo.On("GetUserById", mock.Anything, followerID).Return(&model.User{ID: followerID}, nil)
+		follows.On("AddFollow", mock.Anything, mock.Anything).Return(false, nil)
+
+		svc := NewUserService(repo, nil, p, nil).WithFollows(follows)
+		err := svc.Follow(ctx, followerID, followeeID)
+
+		assert.NoError(t, err)
+		repo.AssertNotCalled(t, "AdjustFollowCounts", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+		p.AssertNotCalled(t, "Produce", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("users cannot follow themselves", func(t *testing.T) {
+		svc := NewUserService(new(MockUserRepository), nil, nil, nil).WithFollows(new(MockFollowRepository))
+		err := svc.Follow(ctx, followerID, followerID)
+
+		assert.ErrorIs(t, err, ErrSelfFollow)
+		assert.ErrorIs(t, err, ErrValidation)
+	})
+
+	t.Run("restricted users are hidden and cannot follow", func(t *testing.T) {
+		banned := &model.Moderation{Status: model.StatusBanned}
+
+		repo := new(MockUserRepository)
+		repo.On("GetUserById", mock.Anything, followeeID).Return(&model.User{ID: followeeID, Moderation: banned}, nil)
+		svc := NewUserService(repo, nil, nil, nil).WithFollows(new(MockFollowRepository))
+		assert.ErrorIs(t, svc.Follow(ctx, followerID, followeeID), ErrUserNotFound)
+
+		repo = new(MockUserRepository)
+		repo.On("GetUserById", mock.Anything, followeeID).Return(&model.User{ID: followeeID}, nil)
+		repo.On("GetUserById", mock.Anything, followerID).Return(&model.User{ID: followerID, Moderation: banned}, nil)
+		svc = NewUserService(repo, nil, nil, nil).WithFollows(new(MockFollowRepository))
+		assert.ErrorIs(t, svc.Follow(ctx, followerID, followeeID), ErrAccountRestricted)
+	})
+}
+
+func TestUserService_Unfollow(t *testing.T) {
+	followerID, followeeID := uuid.New(), uuid.New()
+
+	t.Run("removed follows adjust counts and publish", func(t *testing.T) {
+		repo := new(MockUserRepository)
+		follows := new(MockFollowRepository)
+		p := new(MockProducer)
+		cache := new(MockCacheService)
+		follows.On("RemoveFollow", mock.Anything, followerID, followeeID).Return(true, nil)
+		repo.On("AdjustFollowCounts", mock.Anything, followerID, followeeID, int64(-1)).Return(nil)
+		p.On("Produce", mock.Anything, events.UserUnfollowed, events.FollowPayload{
+			FollowerID: followerID, FolloweeID: followeeID,
+		}).Return(nil)
+		cache.On("Delete", mock.Anything, mock.Anything).Return(nil)
+
+		svc := NewUserService(repo, nil, p, cache).WithFollows(follows)
+		err := svc.Unfollow(context.Background(), followerID, followeeID)
+
+		assert.NoError(t, err)
+		repo.AssertExpectations(t)
+		p.AssertExpectations(t)
+		cache.AssertNumberOfCalls(t, "Delete", 2)
+	})
+
+	t.Run("unfollowing twice changes nothing", func(t *testing.T) {
+		repo := new(MockUserRepository)
+		follows := new(MockFollowRepository)
+		follows.On("RemoveFollow", mock.Anything, followerID, followeeID).Return(false, nil)
+
+		svc := NewUserService(repo, nil, nil, nil).WithFollows(follows)
+		err := svc.Unfollow(context.Background(), followerID, followeeID)
+
+		assert.NoError(t, err)
+		repo.AssertNotCalled(t, "AdjustFollowCounts", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func TestUserService_ListFollowers(t *testing.T) {
+	userID := uuid.New()
+	first, gone, second, third := uuid.New(), uuid.New(), uuid.New(), uuid.New()
+	now := time.Now().UTC()
+
+	repo := new(MockUserRepository)
+	follows := new(MockFollowRepository)
+	repo.On("GetUserById", mock.Anything, userID).Return(&model.User{ID: userID}, nil)
+	follows.On("ListFollowers", mock.Anything, userID, 4, (*Cursor)(nil)).Return([]model.Follow{
+		{FollowerID: first, FolloweeID: userID, CreatedAt: now},
+		{FollowerID: gone, FolloweeID: userID, CreatedAt: now.Add(-time.Minute)},
+		{FollowerID: second, FolloweeID: userID, CreatedAt: now.Add(-2 * time.Minute)},
+		{FollowerID: third, FolloweeID: userID, CreatedAt: now.Add(-3 * time.Minute)},
+	}, nil)
+	// Deleted users are not returned by the lookup
+	repo.On("GetUsersByIds", mock.Anything, []uuid.UUID{first, gone, second}).Return([]model.User{
+		{ID: second}, {ID: first},
+	}, nil)
+
+	svc := NewUserService(repo, nil, nil, nil).WithFollows(follows)
+	page, err := svc.ListFollowers(context.Background(), userID, FollowParams{Limit: 3})
+
+	assert.NoError(t, err)
+	if assert.Len(t, page.Items, 2) {
+		assert.Equal(t, first, page.Items[0].ID)
+		assert.Equal(t, second, page.Items[1].ID)
+	}
+
+	cursor, err := DecodeCursor(page.NextCursor)
+	assert.NoError(t, err)
+	assert.Equal(t, followSort, cursor.Sort)
+	assert.Equal(t, second, cursor.ID)
+
+	_, err = svc.ListFollowers(context.Background(), userID, FollowParams{
+		Cursor: Cursor{Sort: historySort, ID: uuid.New()}.Encode(),
+	})
+	assert.ErrorIs(t, err, ErrInvalidCursor)
+}
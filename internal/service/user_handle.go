@@ -0,0 +1,250 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/Sayan80bayev/go-project/pkg/logging"
+	"github.com/google/uuid"
+	"userService/internal/events"
+	"userService/internal/model"
+	"userService/internal/transport/response"
+)
+
+const (
+	MinHandleLength = 3
+	MaxHandleLength = 30
+)
+
+// ErrHandleRenameTooSoon means the owner renamed their handle less than the
+// policy's RenameInterval ago.
+var ErrHandleRenameTooSoon = errors.New("handle was changed too recently")
+
+// HandlePolicy controls which handles may be claimed and how renames behave.
+type HandlePolicy struct {
+	// Reserved handles may only be assigned by admins
+	Reserved []string
+	// RenameInterval is the least time between two renames by the owner; the
+	// first handle and renames by admins are not limited
+	RenameInterval time.Duration
+	// RedirectPeriod is how long a former handle keeps resolving to its owner
+	RedirectPeriod time.Duration
+}
+
+// HandleRepository keeps every handle in use, current or still redirecting,
+// under a unique key, so that two users can never hold the same one.
+type HandleRepository interface {
+	// ReserveHandle gives handle to userID until until, or for good when until
+	// is nil, replacing an expired reservation or one of userID's own. While
+	// another user holds the handle it fails with a ConflictError.
+	ReserveHandle(ctx context.Context, handle string, userID uuid.UUID, until *time.Time, now time.Time) error
+	// ReleaseHandles drops every reservation of userID.
+	ReleaseHandles(ctx context.Context, userID uuid.UUID) error
+}
+
+// DefaultHandlePolicy reserves the names of routes and staff accounts, allows
+// a rename every 30 days and redirects former handles for 90.
+func DefaultHandlePolicy() HandlePolicy {
+	return HandlePolicy{
+		Reserved: []string{
+			"admin", "administrator", "api", "help", "me", "moderator", "root",
+			"search", "security", "settings", "support", "system", "user", "users",
+		},
+		RenameInterval: 30 * 24 * time.Hour,
+		RedirectPeriod: 90 * 24 * time.Hour,
+	}
+}
+
+// WithHandlePolicy replaces DefaultHandlePolicy.
+func (s *UserService) WithHandlePolicy(policy HandlePolicy) *UserService {
+	s.handles = policy
+	return s
+}
+
+// WithHandleReservations enforces handle ownership in storage: a rename
+// reserves the new handle and keeps the old one reserved while it redirects,
+// in the same transaction as the profile write.
+func (s *UserService) WithHandleReservations(reservations HandleRepository) *UserService {
+	s.reservations = reservations
+	return s
+}
+
+// NormalizeHandle is the canonical form handles are stored and looked up in.
+// A leading @ is dropped.
+func NormalizeHandle(handle string) string {
+	return strings.ToLower(strings.TrimPrefix(strings.TrimSpace(handle), "@"))
+}
+
+// validateHandle checks the format of a normalized handle: 3 to 30 letters,
+// digits and underscores, starting with a letter and not ending with an
+// underscore.
+func validateHandle(handle string) error {
+	verr := &ValidationError{}
+	switch {
+	case len(handle) < MinHandleLength || len(handle) > MaxHandleLength:
+		verr.add(FieldHandle, fmt.Sprintf("must be %d to %d characters long", MinHandleLength, MaxHandleLength))
+	case handle[0] < 'a' || handle[0] > 'z':
+		verr.add(FieldHandle, "must start with a letter")
+	case strings.HasSuffix(handle, "_"):
+		verr.add(FieldHandle, "must not end with an underscore")
+	case strings.IndexFunc(handle, func(r rune) bool {
+		return (r < 'a' || r > 'z') && (r < '0' || r > '9') && r != '_'
+	}) >= 0:
+		verr.add(FieldHandle, "may only contain letters, digits and underscores")
+	}
+	return verr.orNil()
+}
+
+// GetUserByHandle returns the profile with handle, or the profile that gave it
+// up while its redirect lasts. Callers tell the two apart by comparing the
+// normalized handle with the one in the response.
+func (s *UserService) GetUserByHandle(ctx context.Context, handle string) (*response.UserResponse, error) {
+	handle = NormalizeHandle(handle)
+	if validateHandle(handle) != nil {
+		return nil, ErrUserNotFound
+	}
+
+	user, err := s.userRepo.GetUserByHandle(ctx, handle)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		if user, err = s.userRepo.GetUserByFormerHandle(ctx, handle, time.Now().UTC()); err != nil {
+			return nil, err
+		}
+	}
+	if user == nil {
+		return nil, ErrUserNotFound
+	}
+	if user.DeletedAt != nil {
+		return nil, ErrUserDeleted
+	}
+	if !canSee(ctx, user.ID, user.Moderation != nil) {
+		return nil, ErrUserNotFound
+	}
+	if err := s.requireUnblocked(ctx, user.ID); err != nil {
+		return nil, err
+	}
+
+	ur := present(ctx, s.toResponse(*user))
+	return &ur, nil
+}
+
+// ChangeHandle sets the handle of userID. The previous handle redirects to the
+// profile for the policy's RedirectPeriod and stays reserved for it until
+// then, so that nobody else can claim it.
+func (s *UserService) ChangeHandle(ctx context.Context, userID uuid.UUID, handle string) (*response.UserResponse, error) {
+	origin := OriginFrom(ctx)
+	handle = NormalizeHandle(handle)
+	if err := validateHandle(handle); err != nil {
+		return nil, err
+	}
+	if slices.Contains(s.handles.Reserved, handle) && !origin.Admin {
+		verr := &ValidationError{}
+		verr.add(FieldHandle, "is reserved")
+		return nil, verr
+	}
+
+	u, err := s.userRepo.GetUserById(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if u == nil {
+		return nil, fmt.Errorf("%w: %s", ErrUserNotFound, userID)
+	}
+	if u.DeletedAt != nil {
+		return nil, ErrUserDeleted
+	}
+	if u.Moderation != nil && !origin.Admin {
+		return nil, ErrAccountRestricted
+	}
+	if u.Handle == handle {
+		ur := present(ctx, s.toResponse(*u))
+		return &ur, nil
+	}
+
+	now := time.Now().UTC()
+	if u.Handle != "" && u.HandleChangedAt != nil && !origin.Admin &&
+		now.Before(u.HandleChangedAt.Add(s.handles.RenameInterval)) {
+		return nil, ErrHandleRenameTooSoon
+	}
+
+	// Someone else's former handle stays theirs while it redirects. This only
+	// fails early: the reservations below decide races between renames.
+	holder, err := s.userRepo.GetUserByFormerHandle(ctx, handle, now)
+	if err != nil {
+		return nil, err
+	}
+	if holder != nil && holder.ID != userID {
+		return nil, &ConflictError{Field: FieldHandle}
+	}
+
+	// Drop expired redirects and the handle being reclaimed, then remember the old one
+	former := slices.DeleteFunc(slices.Clone(u.FormerHandles), func(f model.FormerHandle) bool {
+		return f.Handle == handle || !f.Until.After(now)
+	})
+	var redirectUntil *time.Time
+	if u.Handle != "" {
+		until := now.Add(s.handles.RedirectPeriod)
+		redirectUntil = &until
+		former = append([]model.FormerHandle{{Handle: u.Handle, Until: until}}, former...)
+	}
+
+	patch := UserPatch{
+		Fields:          []string{FieldHandle, FieldHandleChangedAt, FieldFormerHandles},
+		Handle:          handle,
+		HandleChangedAt: &now,
+		FormerHandles:   former,
+	}
+	var updated *model.User
+	err = s.events.atomically(ctx, func(ctx context.Context) error {
+		if err := s.reserveHandles(ctx, userID, handle, u.Handle, redirectUntil, now); err != nil {
+			return err
+		}
+		var err error
+		if updated, err = s.userRepo.PatchUser(ctx, userID, u.Version, patch); err != nil {
+			return err
+		}
+		err = s.events.publish(ctx, events.UserHandleChanged, events.UserHandleChangedPayload{
+			UserID:        userID,
+			Handle:        handle,
+			OldHandle:     u.Handle,
+			RedirectUntil: redirectUntil,
+		})
+		if err != nil {
+			return err
+		}
+		return recordRevision(ctx, s.revisions, ActionHandle, u, updated)
+	})
+	if err != nil {
+		logging.Instance.Errorf("failed to change handle of user %s: %v", userID, err)
+		return nil, err
+	}
+	// Invalidate cache
+	cacheKey := fmt.Sprintf("user:%s", userID)
+	if err = s.cache.Delete(ctx, cacheKey); err != nil {
+		logging.Instance.Warnf("failed to invalidate cache for user %s: %v", userID, err)
+	}
+
+	ur := present(ctx, s.toResponse(*updated))
+	return &ur, nil
+}
+
+// reserveHandles claims handle for userID and keeps its old handle reserved
+// until the redirect ends. It must run inside events.atomically.
+func (s *UserService) reserveHandles(ctx context.Context, userID uuid.UUID, handle, old string, redirectUntil *time.Time, now time.Time) error {
+	if s.reservations == nil {
+		return nil
+	}
+	if err := s.reservations.ReserveHandle(ctx, handle, userID, nil, now); err != nil {
+		return err
+	}
+	if old == "" {
+		return nil
+	}
+	return s.reservations.ReserveHandle(ctx, old, userID, redirectUntil, now)
+}
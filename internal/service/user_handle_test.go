@@ -0,0 +1,210 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"userService/internal/events"
+	"userService/internal/model"
+)
+
+type MockHandleRepository struct {
+	mock.Mock
+}
+
+func (m *MockHandleRepository) ReserveHandle(ctx context.Context, handle string, userID uuid.UUID, until *time.Time, now time.Time) error {
+	args := m.Called(ctx, handle, userID, until, now)
+	return args.Error(0)
+}
+
+func (m *MockHandleRepository) ReleaseHandles(ctx context.Context, userID uuid.UUID) error {
+	args := m.Called(ctx, userID)
+	return args.Error(0)
+}
+
+func TestValidateHandle(t *testing.T) {
+	valid := []string{"ada", "ada_lovelace", "a1b2c3", "abcdefghijklmnopqrstuvwxyz1234"}
+	for _, handle := range valid {
+		assert.NoError(t, validateHandle(handle), handle)
+	}
+
+	invalid := []string{"ab", "abcdefghijklmnopqrstuvwxyz12345", "1ada", "_ada", "ada_", "ada.l", "ada-l", "adá"}
+	for _, handle := range invalid {
+		assert.ErrorIs(t, validateHandle(handle), ErrValidation, handle)
+	}
+}
+
+func TestNormalizeHandle(t *testing.T) {
+	assert.Equal(t, "ada_lovelace", NormalizeHandle(" @Ada_Lovelace "))
+}
+
+func TestUserService_ChangeHandle(t *testing.T) {
+	userID, adminID := uuid.New(), uuid.New()
+	owner := WithOrigin(context.Background(), Origin{Source: SourceHTTP, Actor: &userID})
+	admin := WithOrigin(context.Background(), Origin{Source: SourceHTTP, Actor: &adminID, Admin: true})
+	cacheKey := fmt.Sprintf("user:%s", userID)
+
+	t.Run("first handle is claimed without a redirect", func(t *testing.T) {
+		repo := new(MockUserRepository)
+		p := new(MockProducer)
+		cache := new(MockCacheService)
+		repo.On("GetUserById", mock.Anything, userID).Return(&model.User{ID: userID, Version: 1}, nil)
+		repo.On("GetUserByFormerHandle", mock.Anything, "ada", mock.Anything).Return(nil, nil)
+		repo.On("PatchUser", mock.Anything, userID, int64(1), mock.MatchedBy(func(patch UserPatch) bool {
+			return patch.Handle == "ada" && patch.HandleChangedAt != nil && patch.FormerHandles == nil
+		})).Return(&model.User{ID: userID, Version: 2, Handle: "ada"}, nil)
+		p.On("Produce", mock.Anything, events.UserHandleChanged, events.UserHandleChangedPayload{
+			UserID: userID, Handle: "ada",
+		}).Return(nil)
+		cache.On("Delete", mock.Anything, cacheKey).Return(nil)
+
+		svc := NewUserService(repo, nil, p, cache)
+		resp, err := svc.ChangeHandle(owner, userID, "@Ada")
+
+		assert.NoError(t, err)
+		assert.Equal(t, "ada", resp.Handle)
+		repo.AssertExpectations(t)
+		p.AssertExpectations(t)
+	})
+
+	t.Run("renames keep the old handle as a redirect", func(t *testing.T) {
+		repo := new(MockUserRepository)
+		p := new(MockProducer)
+		cache := new(MockCacheService)
+		changedAt := time.Now().Add(-48 * time.Hour)
+		expired := model.FormerHandle{Handle: "old", Until: time.Now().Add(-time.Minute)}
+		repo.On("GetUserById", mock.Anything, userID).Return(&model.User{
+			ID: userID, Version: 3, Handle: "ada", HandleChangedAt: &changedAt, FormerHandles: []model.FormerHandle{expired},
+		}, nil)
+		repo.On("GetUserByFormerHandle", mock.Anything, "lovelace", mock.Anything).Return(nil, nil)
+		var patched UserPatch
+		repo.On("PatchUser", mock.Anything, userID, int64(3), mock.Anything).
+			Run(func(args mock.Arguments) { patched = args.Get(3).(UserPatch) }).
+			Return(&model.User{ID: userID, Version: 4, Handle: "lovelace"}, nil)
+		p.On("Produce", mock.Anything, events.UserHandleChanged, mock.MatchedBy(func(e events.UserHandleChangedPayload) bool {
+			return e.Handle == "lovelace" && e.OldHandle == "ada" && e.RedirectUntil != nil
+		})).Return(nil)
+		cache.On("Delete", mock.Anything, cacheKey).Return(nil)
+
+		svc := NewUserService(repo, nil, p, cache).WithHandlePolicy(HandlePolicy{
+			RenameInterval: 24 * time.Hour, RedirectPeriod: time.Hour,
+		})
+		_, err := svc.ChangeHandle(owner, userID, "lovelace")
+
+		assert.NoError(t, err)
+		if assert.Len(t, patched.FormerHandles, 1) {
+			assert.Equal(t, "ada", patched.FormerHandles[0].Handle)
+			assert.WithinDuration(t, time.Now().Add(time.Hour), patched.FormerHandles[0].Until, time.Minute)
+		}
+		p.AssertExpectations(t)
+	})
+
+	t.Run("owners rename at most once per interval", func(t *testing.T) {
+		changedAt := time.Now().Add(-time.Hour)
+		stored := &model.User{ID: userID, Version: 3, Handle: "ada", HandleChangedAt: &changedAt}
+		repo := new(MockUserRepository)
+		repo.On("GetUserById", mock.Anything, userID).Return(stored, nil)
+
+		svc := NewUserService(repo, nil, nil, nil).WithHandlePolicy(HandlePolicy{RenameInterval: 24 * time.Hour})
+		_, err := svc.ChangeHandle(owner, userID, "lovelace")
+
+		assert.ErrorIs(t, err, ErrHandleRenameTooSoon)
+		repo.AssertNotCalled(t, "PatchUser", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("reserved handles are for admins", func(t *testing.T) {
+		svc := NewUserService(new(MockUserRepository), nil, nil, nil)
+		_, err := svc.ChangeHandle(owner, userID, "Support")
+
+		assert.ErrorIs(t, err, ErrValidation)
+
+		repo := new(MockUserRepository)
+		repo.On("GetUserById", mock.Anything, userID).Return(&model.User{ID: userID, Handle: "support"}, nil)
+		svc = NewUserService(repo, nil, nil, nil)
+		resp, err := svc.ChangeHandle(admin, userID, "support")
+
+		assert.NoError(t, err)
+		assert.Equal(t, "support", resp.Handle)
+	})
+
+	t.Run("redirecting handles of others cannot be claimed", func(t *testing.T) {
+		repo := new(MockUserRepository)
+		repo.On("GetUserById", mock.Anything, userID).Return(&model.User{ID: userID}, nil)
+		repo.On("GetUserByFormerHandle", mock.Anything, "taken", mock.Anything).Return(&model.User{ID: uuid.New()}, nil)
+
+		svc := NewUserService(repo, nil, nil, nil)
+		_, err := svc.ChangeHandle(owner, userID, "taken")
+
+		assert.ErrorIs(t, err, ErrConflict)
+	})
+
+	t.Run("renames reserve the new handle and keep the old one", func(t *testing.T) {
+		repo := new(MockUserRepository)
+		reservations := new(MockHandleRepository)
+		p := new(MockProducer)
+		cache := new(MockCacheService)
+		repo.On("GetUserById", mock.Anything, userID).Return(&model.User{ID: userID, Version: 1, Handle: "ada"}, nil)
+		repo.On("GetUserByFormerHandle", mock.Anything, "lovelace", mock.Anything).Return(nil, nil)
+		reservations.On("ReserveHandle", mock.Anything, "lovelace", userID, (*time.Time)(nil), mock.Anything).Return(nil)
+		reservations.On("ReserveHandle", mock.Anything, "ada", userID, mock.MatchedBy(func(until *time.Time) bool {
+			return until != nil && until.After(time.Now())
+		}), mock.Anything).Return(nil)
+		repo.On("PatchUser", mock.Anything, userID, int64(1), mock.Anything).
+			Return(&model.User{ID: userID, Version: 2, Handle: "lovelace"}, nil)
+		p.On("Produce", mock.Anything, events.UserHandleChanged, mock.Anything).Return(nil)
+		cache.On("Delete", mock.Anything, cacheKey).Return(nil)
+
+		svc := NewUserService(repo, nil, p, cache).WithHandleReservations(reservations)
+		_, err := svc.ChangeHandle(owner, userID, "lovelace")
+
+		assert.NoError(t, err)
+		reservations.AssertExpectations(t)
+	})
+
+	t.Run("a handle reserved meanwhile fails the rename", func(t *testing.T) {
+		repo := new(MockUserRepository)
+		reservations := new(MockHandleRepository)
+		repo.On("GetUserById", mock.Anything, userID).Return(&model.User{ID: userID, Version: 1}, nil)
+		repo.On("GetUserByFormerHandle", mock.Anything, "taken", mock.Anything).Return(nil, nil)
+		reservations.On("ReserveHandle", mock.Anything, "taken", userID, (*time.Time)(nil), mock.Anything).
+			Return(&ConflictError{Field: FieldHandle})
+
+		svc := NewUserService(repo, nil, nil, nil).WithHandleReservations(reservations)
+		_, err := svc.ChangeHandle(owner, userID, "taken")
+
+		assert.ErrorIs(t, err, ErrConflict)
+		repo.AssertNotCalled(t, "PatchUser", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func TestUserService_GetUserByHandle(t *testing.T) {
+	userID := uuid.New()
+
+	t.Run("former handles resolve to their owner", func(t *testing.T) {
+		repo := new(MockUserRepository)
+		repo.On("GetUserByHandle", mock.Anything, "ada").Return(nil, nil)
+		repo.On("GetUserByFormerHandle", mock.Anything, "ada", mock.Anything).
+			Return(&model.User{ID: userID, Handle: "lovelace"}, nil)
+
+		svc := NewUserService(repo, nil, nil, nil)
+		resp, err := svc.GetUserByHandle(context.Background(), "ADA")
+
+		assert.NoError(t, err)
+		assert.Equal(t, "lovelace", resp.Handle)
+	})
+
+	t.Run("malformed handles are not looked up", func(t *testing.T) {
+		repo := new(MockUserRepository)
+
+		svc := NewUserService(repo, nil, nil, nil)
+		_, err := svc.GetUserByHandle(context.Background(), "a!")
+
+		assert.ErrorIs(t, err, ErrUserNotFound)
+		repo.AssertNotCalled(t, "GetUserByHandle", mock.Anything, mock.Anything)
+	})
+}
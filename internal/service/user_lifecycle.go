@@ -0,0 +1,299 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Sayan80bayev/go-project/pkg/caching"
+	"github.com/Sayan80bayev/go-project/pkg/logging"
+	"github.com/Sayan80bayev/go-project/pkg/messaging"
+	storage "github.com/Sayan80bayev/go-project/pkg/objectStorage"
+	"github.com/google/uuid"
+	"userService/internal/events"
+	"userService/internal/model"
+)
+
+// RetentionPolicy controls how long soft-deleted profiles can be restored and when they are purged.
+type RetentionPolicy struct {
+	// RestoreGracePeriod is how long after deletion the owner may restore a profile
+	RestoreGracePeriod time.Duration
+	// RetentionPeriod is how long deleted profiles are kept; admins may restore until then
+	RetentionPeriod time.Duration
+	// PurgeBatchSize bounds the number of profiles purged per pass
+	PurgeBatchSize int
+}
+
+// LifecycleService restores soft-deleted profiles and purges them after retention.
+type LifecycleService struct {
+	userRepo     UserRepository
+	fileStorage  storage.FileStorage
+	events       *publisher
+	cache        caching.CacheService
+	policy       RetentionPolicy
+	revisions    RevisionRepository
+	follows      FollowRepository
+	blocks       BlockRepository
+	blockSet     BlockSet
+	reservations HandleRepository
+	now          func() time.Time
+}
+
+func NewLifecycleService(
+	userRepo UserRepository,
+	fileStorage storage.FileStorage,
+	producer messaging.Producer,
+	cache caching.CacheService,
+	policy RetentionPolicy,
+) *LifecycleService {
+	if policy.PurgeBatchSize <= 0 {
+		policy.PurgeBatchSize = 100
+	}
+	return &LifecycleService{
+		userRepo:    userRepo,
+		fileStorage: fileStorage,
+		events:      &publisher{producer: producer},
+		cache:       cache,
+		policy:      policy,
+		now:         time.Now,
+	}
+}
+
+// WithOutbox commits events in the same transaction as the writes that cause them.
+func (s *LifecycleService) WithOutbox(tx Transactor, outbox OutboxRepository) *LifecycleService {
+	s.events.tx, s.events.outbox = tx, outbox
+	return s
+}
+
+// WithRevisions enables recording restores and dropping the history of purged profiles.
+func (s *LifecycleService) WithRevisions(revisions RevisionRepository) *LifecycleService {
+	s.revisions = revisions
+	return s
+}
+
+// WithFollows removes the follows of purged profiles and corrects the counts
+// of the users on the other end.
+func (s *LifecycleService) WithFollows(follows FollowRepository) *LifecycleService {
+	s.follows = follows
+	return s
+}
+
+// WithBlocks removes the blocks by and of purged profiles. set may be nil.
+func (s *LifecycleService) WithBlocks(blocks BlockRepository, set BlockSet) *LifecycleService {
+	s.blocks, s.blockSet = blocks, set
+	return s
+}
+
+// WithHandleReservations frees the handles of purged profiles.
+func (s *LifecycleService) WithHandleReservations(reservations HandleRepository) *LifecycleService {
+	s.reservations = reservations
+	return s
+}
+
+// RestoreUser undoes a soft delete. Owners are limited to the grace period;
+// privileged callers may restore any profile that has not been purged yet.
+func (s *LifecycleService) RestoreUser(ctx context.Context, userID uuid.UUID, privileged bool) error {
+	window := s.policy.RestoreGracePeriod
+	if privileged {
+		window = s.policy.RetentionPeriod
+	}
+
+	// Read first so the revision can report the deletion time being undone
+	var before *model.User
+	if s.revisions != nil {
+		var err error
+		if before, err = s.userRepo.GetUserById(ctx, userID); err != nil {
+			return err
+		}
+	}
+
+	// Persist restore together with its event
+	err := s.events.atomically(ctx, func(ctx context.Context) error {
+		if err := s.userRepo.RestoreUser(ctx, userID, s.now().UTC().Add(-window)); err != nil {
+			return err
+		}
+		err := s.events.publish(ctx, events.UserRestored, events.UserRestoredPayload{
+			UserID: userID,
+		})
+		if err != nil {
+			return err
+		}
+		return s.recordRestore(ctx, userID, before)
+	})
+	if err != nil {
+		return err
+	}
+
+	// Invalidate cache
+	cacheKey := fmt.Sprintf("user:%s", userID)
+	if err := s.cache.Delete(ctx, cacheKey); err != nil {
+		logging.Instance.Warnf("failed to invalidate cache for restored user %s: %v", userID, err)
+	}
+
+	return nil
+}
+
+func (s *LifecycleService) recordRestore(ctx context.Context, userID uuid.UUID, before *model.User) error {
+	if before == nil {
+		return nil
+	}
+	after, err := s.userRepo.GetUserById(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if after == nil {
+		return fmt.Errorf("%w: %s", ErrUserNotFound, userID)
+	}
+	return recordRevision(ctx, s.revisions, ActionRestore, before, after)
+}
+
+// PurgeExpired hard-deletes one batch of profiles deleted before the retention
+// window, removes their avatars and returns how many were purged. Their follows,
+// blocks and handle reservations go in the same transaction, and the follow counts of the users on
+// the other end drop accordingly. Until then the edges of a soft-deleted
+// profile are kept, and counted, so that a restore brings them back.
+func (s *LifecycleService) PurgeExpired(ctx context.Context) (int, error) {
+	cutoff := s.now().UTC().Add(-s.policy.RetentionPeriod)
+
+	users, err := s.userRepo.ListDeletedBefore(ctx, cutoff, s.policy.PurgeBatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	purged := 0
+	for _, u := range users {
+		// The cutoff is re-checked so a profile restored meanwhile survives
+		var ok bool
+		var counterparts, blocked []uuid.UUID
+		err := s.events.atomically(ctx, func(ctx context.Context) error {
+			var err error
+			if ok, err = s.userRepo.PurgeUser(ctx, u.ID, cutoff); err != nil || !ok {
+				return err
+			}
+			if counterparts, err = s.removeFollowsOf(ctx, u.ID); err != nil {
+				return err
+			}
+			if blocked, err = s.removeBlocksOf(ctx, u.ID); err != nil {
+				return err
+			}
+			if s.reservations != nil {
+				if err = s.reservations.ReleaseHandles(ctx, u.ID); err != nil {
+					return err
+				}
+			}
+			return s.events.publish(ctx, events.UserPurged, events.UserPurgedPayload{
+				UserID:   u.ID,
+				ImageURL: u.AvatarURL,
+			})
+		})
+		if err != nil {
+			return purged, fmt.Errorf("failed to purge user %s: %w", u.ID, err)
+		}
+		if !ok {
+			continue
+		}
+		purged++
+
+		for _, id := range counterparts {
+			if err := s.cache.Delete(ctx, fmt.Sprintf("user:%s", id)); err != nil {
+				logging.Instance.Warnf("failed to invalidate cache for user %s: %v", id, err)
+			}
+		}
+		if s.blockSet != nil {
+			for _, id := range blocked {
+				if err := s.blockSet.InvalidateBlockers(ctx, id); err != nil {
+					logging.Instance.Warnf("failed to invalidate block set for user %s: %v", id, err)
+				}
+			}
+		}
+
+		if s.revisions != nil {
+			if err := s.revisions.DeleteRevisions(ctx, u.ID); err != nil {
+				logging.Instance.Errorf("failed to delete history of purged user %s: %v", u.ID, err)
+			}
+		}
+
+		if u.AvatarURL != "" {
+			if err := s.fileStorage.DeleteFileByURL(ctx, u.AvatarURL); err != nil {
+				logging.Instance.Errorf("failed to delete avatar of purged user %s: %v", u.ID, err)
+			}
+		}
+	}
+
+	return purged, nil
+}
+
+// removeFollowsOf deletes the follows from and to userID, takes them off the
+// counts of the users on the other end and returns those users. It must run
+// inside events.atomically.
+func (s *LifecycleService) removeFollowsOf(ctx context.Context, userID uuid.UUID) ([]uuid.UUID, error) {
+	if s.follows == nil {
+		return nil, nil
+	}
+	follows, err := s.follows.RemoveFollowsOf(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	counterparts := make([]uuid.UUID, 0, len(follows))
+	for _, f := range follows {
+		if err := s.userRepo.AdjustFollowCounts(ctx, f.FollowerID, f.FolloweeID, -1); err != nil {
+			return nil, err
+		}
+		if f.FollowerID == userID {
+			counterparts = append(counterparts, f.FolloweeID)
+		} else {
+			counterparts = append(counterparts, f.FollowerID)
+		}
+	}
+	return uniqueIDs(counterparts), nil
+}
+
+// removeBlocksOf deletes the blocks by and of userID and returns the users it
+// had blocked, whose cached blocker sets are now stale. It must run inside
+// events.atomically.
+func (s *LifecycleService) removeBlocksOf(ctx context.Context, userID uuid.UUID) ([]uuid.UUID, error) {
+	if s.blocks == nil {
+		return nil, nil
+	}
+	blocks, err := s.blocks.RemoveBlocksOf(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	var blocked []uuid.UUID
+	for _, b := range blocks {
+		if b.BlockerID == userID {
+			blocked = append(blocked, b.BlockedID)
+		}
+	}
+	return blocked, nil
+}
+
+// RunPurgeJob purges expired profiles every interval until ctx is cancelled.
+func (s *LifecycleService) RunPurgeJob(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			// Drain full batches before waiting for the next tick
+			for {
+				n, err := s.PurgeExpired(ctx)
+				if err != nil {
+					logging.Instance.Errorf("purge job failed: %v", err)
+					break
+				}
+				if n > 0 {
+					logging.Instance.Infof("purged %d deleted users", n)
+				}
+				if n < s.policy.PurgeBatchSize {
+					break
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,143 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"userService/internal/events"
+	"userService/internal/model"
+)
+
+func newTestLifecycleService(repo *MockUserRepository, fs *MockFileService, p *MockProducer, cache *MockCacheService, now time.Time) *LifecycleService {
+	svc := NewLifecycleService(repo, fs, p, cache, RetentionPolicy{
+		RestoreGracePeriod: 24 * time.Hour,
+		RetentionPeriod:    72 * time.Hour,
+		PurgeBatchSize:     10,
+	})
+	svc.now = func() time.Time { return now }
+	return svc
+}
+
+func TestLifecycleService_RestoreUser(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name       string
+		privileged bool
+		since      time.Time
+		repoErr    error
+		wantErr    error
+	}{
+		{name: "owner within grace period", since: now.Add(-24 * time.Hour)},
+		{name: "admin within retention period", privileged: true, since: now.Add(-72 * time.Hour)},
+		{name: "restore window expired", since: now.Add(-24 * time.Hour), repoErr: ErrRestoreExpired, wantErr: ErrRestoreExpired},
+		{name: "user is not deleted", since: now.Add(-24 * time.Hour), repoErr: ErrNotDeleted, wantErr: ErrNotDeleted},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			userID := uuid.New()
+			repo := new(MockUserRepository)
+			p := new(MockProducer)
+			cache := new(MockCacheService)
+
+			repo.On("RestoreUser", mock.Anything, userID, tt.since).Return(tt.repoErr)
+			if tt.repoErr == nil {
+				cache.On("Delete", mock.Anything, fmt.Sprintf("user:%s", userID)).Return(nil)
+				p.On("Produce", mock.Anything, events.UserRestored, events.UserRestoredPayload{UserID: userID}).Return(nil)
+			}
+
+			svc := newTestLifecycleService(repo, nil, p, cache, now)
+			err := svc.RestoreUser(context.Background(), userID, tt.privileged)
+
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				p.AssertNotCalled(t, "Produce", mock.Anything, mock.Anything, mock.Anything)
+			} else {
+				assert.NoError(t, err)
+			}
+			repo.AssertExpectations(t)
+			cache.AssertExpectations(t)
+			p.AssertExpectations(t)
+		})
+	}
+}
+
+func TestLifecycleService_PurgeExpired(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	cutoff := now.Add(-72 * time.Hour)
+
+	withAvatar := model.User{ID: uuid.New(), AvatarURL: "http://minio/avatars/a.png"}
+	withoutAvatar := model.User{ID: uuid.New()}
+	restored := model.User{ID: uuid.New(), AvatarURL: "http://minio/avatars/r.png"}
+
+	repo := new(MockUserRepository)
+	fs := new(MockFileService)
+	p := new(MockProducer)
+
+	repo.On("ListDeletedBefore", mock.Anything, cutoff, 10).
+		Return([]model.User{withAvatar, withoutAvatar, restored}, nil)
+	repo.On("PurgeUser", mock.Anything, withAvatar.ID, cutoff).Return(true, nil)
+	repo.On("PurgeUser", mock.Anything, withoutAvatar.ID, cutoff).Return(true, nil)
+	// Restored between listing and purging
+	repo.On("PurgeUser", mock.Anything, restored.ID, cutoff).Return(false, nil)
+
+	fs.On("DeleteFileByURL", mock.Anything, withAvatar.AvatarURL).Return(nil)
+	p.On("Produce", mock.Anything, events.UserPurged, events.UserPurgedPayload{UserID: withAvatar.ID, ImageURL: withAvatar.AvatarURL}).Return(nil)
+	p.On("Produce", mock.Anything, events.UserPurged, events.UserPurgedPayload{UserID: withoutAvatar.ID}).Return(nil)
+
+	svc := newTestLifecycleService(repo, fs, p, nil, now)
+	n, err := svc.PurgeExpired(context.Background())
+
+	assert.NoError(t, err)
+	assert.Equal(t, 2, n)
+	repo.AssertExpectations(t)
+	fs.AssertExpectations(t)
+	p.AssertExpectations(t)
+	fs.AssertNotCalled(t, "DeleteFileByURL", mock.Anything, restored.AvatarURL)
+}
+
+func TestLifecycleService_PurgeExpired_RemovesEdges(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	cutoff := now.Add(-72 * time.Hour)
+	purged := model.User{ID: uuid.New()}
+	follower, followee, blocked := uuid.New(), uuid.New(), uuid.New()
+
+	repo := new(MockUserRepository)
+	p := new(MockProducer)
+	cache := new(MockCacheService)
+	follows := new(MockFollowRepository)
+	blocks := new(MockBlockRepository)
+	set := new(MockBlockSet)
+
+	repo.On("ListDeletedBefore", mock.Anything, cutoff, 10).Return([]model.User{purged}, nil)
+	repo.On("PurgeUser", mock.Anything, purged.ID, cutoff).Return(true, nil)
+	follows.On("RemoveFollowsOf", mock.Anything, purged.ID).Return([]model.Follow{
+		{FollowerID: follower, FolloweeID: purged.ID},
+		{FollowerID: purged.ID, FolloweeID: followee},
+	}, nil)
+	repo.On("AdjustFollowCounts", mock.Anything, follower, purged.ID, int64(-1)).Return(nil)
+	repo.On("AdjustFollowCounts", mock.Anything, purged.ID, followee, int64(-1)).Return(nil)
+	blocks.On("RemoveBlocksOf", mock.Anything, purged.ID).Return([]model.Block{
+		{BlockerID: purged.ID, BlockedID: blocked},
+		{BlockerID: follower, BlockedID: purged.ID},
+	}, nil)
+	p.On("Produce", mock.Anything, events.UserPurged, events.UserPurgedPayload{UserID: purged.ID}).Return(nil)
+	cache.On("Delete", mock.Anything, fmt.Sprintf("user:%s", follower)).Return(nil)
+	cache.On("Delete", mock.Anything, fmt.Sprintf("user:%s", followee)).Return(nil)
+	set.On("InvalidateBlockers", mock.Anything, blocked).Return(nil)
+
+	svc := newTestLifecycleService(repo, nil, p, cache, now).WithFollows(follows).WithBlocks(blocks, set)
+	n, err := svc.PurgeExpired(context.Background())
+
+	assert.NoError(t, err)
+	assert.Equal(t, 1, n)
+	repo.AssertExpectations(t)
+	cache.AssertExpectations(t)
+	set.AssertExpectations(t)
+}
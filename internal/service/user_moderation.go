@@ -0,0 +1,247 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/Sayan80bayev/go-project/pkg/caching"
+	"github.com/Sayan80bayev/go-project/pkg/logging"
+	"github.com/Sayan80bayev/go-project/pkg/messaging"
+	"github.com/google/uuid"
+	"userService/internal/events"
+	"userService/internal/model"
+)
+
+// MaxModerationReasonLength bounds the reason given for a moderation decision.
+const MaxModerationReasonLength = 500
+
+// expiryBatchSize bounds the number of restrictions lifted per pass.
+const expiryBatchSize = 100
+
+// ModerationAction is a moderator's decision to restrict an account.
+type ModerationAction struct {
+	Reason string
+	// ExpiresAt is optional for bans and required for suspensions
+	ExpiresAt *time.Time
+}
+
+// ModerationService bans, suspends and unbans accounts and lifts expired
+// restrictions. The moderator is the actor of the request's Origin.
+type ModerationService struct {
+	userRepo  UserRepository
+	events    *publisher
+	cache     caching.CacheService
+	revisions RevisionRepository
+	now       func() time.Time
+}
+
+func NewModerationService(
+	userRepo UserRepository,
+	producer messaging.Producer,
+	cache caching.CacheService,
+) *ModerationService {
+	return &ModerationService{
+		userRepo: userRepo,
+		events:   &publisher{producer: producer},
+		cache:    cache,
+		now:      time.Now,
+	}
+}
+
+// WithOutbox commits events in the same transaction as the writes that cause them.
+func (s *ModerationService) WithOutbox(tx Transactor, outbox OutboxRepository) *ModerationService {
+	s.events.tx, s.events.outbox = tx, outbox
+	return s
+}
+
+// WithRevisions records moderation decisions in the profile history.
+func (s *ModerationService) WithRevisions(revisions RevisionRepository) *ModerationService {
+	s.revisions = revisions
+	return s
+}
+
+// BanUser bans userID, replacing any earlier ban or suspension.
+func (s *ModerationService) BanUser(ctx context.Context, userID uuid.UUID, a ModerationAction) error {
+	return s.restrict(ctx, userID, model.StatusBanned, a)
+}
+
+// SuspendUser suspends userID until a.ExpiresAt, replacing any earlier ban or suspension.
+func (s *ModerationService) SuspendUser(ctx context.Context, userID uuid.UUID, a ModerationAction) error {
+	return s.restrict(ctx, userID, model.StatusSuspended, a)
+}
+
+func (s *ModerationService) restrict(ctx context.Context, userID uuid.UUID, status string, a ModerationAction) error {
+	now := s.now().UTC()
+	verr := &ValidationError{}
+	a.Reason = strings.TrimSpace(a.Reason)
+	if a.Reason == "" || utf8.RuneCountInString(a.Reason) > MaxModerationReasonLength {
+		verr.add("reason", fmt.Sprintf("is required and must be at most %d characters", MaxModerationReasonLength))
+	}
+	switch {
+	case a.ExpiresAt == nil && status == model.StatusSuspended:
+		verr.add("expires_at", "is required for a suspension")
+	case a.ExpiresAt != nil && !a.ExpiresAt.After(now):
+		verr.add("expires_at", "must be in the future")
+	}
+	if err := verr.orNil(); err != nil {
+		return err
+	}
+
+	u, err := s.liveUser(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	m := &model.Moderation{
+		Status:    status,
+		Reason:    a.Reason,
+		Since:     now,
+		ExpiresAt: cloneTime(a.ExpiresAt),
+	}
+	if actor := OriginFrom(ctx).Actor; actor != nil {
+		m.ModeratorID = *actor
+	}
+
+	return s.setModeration(ctx, u, m, events.UserBanned, events.UserBannedPayload{
+		UserID:      userID,
+		Status:      status,
+		Reason:      m.Reason,
+		ModeratorID: m.ModeratorID,
+		ExpiresAt:   m.ExpiresAt,
+	})
+}
+
+// UnbanUser lifts the ban or suspension of userID.
+func (s *ModerationService) UnbanUser(ctx context.Context, userID uuid.UUID, reason string) error {
+	reason = strings.TrimSpace(reason)
+	if utf8.RuneCountInString(reason) > MaxModerationReasonLength {
+		verr := &ValidationError{}
+		verr.add("reason", fmt.Sprintf("must be at most %d characters", MaxModerationReasonLength))
+		return verr
+	}
+
+	u, err := s.liveUser(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if u.Moderation == nil {
+		return ErrNotModerated
+	}
+
+	return s.setModeration(ctx, u, nil, events.UserUnbanned, events.UserUnbannedPayload{
+		UserID:      userID,
+		Reason:      reason,
+		ModeratorID: OriginFrom(ctx).Actor,
+	})
+}
+
+// LiftExpired lifts one batch of restrictions whose expiry has passed and
+// returns how many were lifted.
+func (s *ModerationService) LiftExpired(ctx context.Context) (int, error) {
+	users, err := s.userRepo.ListModerationExpiredBefore(ctx, s.now().UTC(), expiryBatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	lifted := 0
+	for i := range users {
+		u := &users[i]
+		err := s.setModeration(ctx, u, nil, events.UserUnbanned, events.UserUnbannedPayload{
+			UserID:  u.ID,
+			Reason:  "expired",
+			Expired: true,
+		})
+		// A moderator acted meanwhile; the next pass sees the new decision
+		if errors.Is(err, ErrVersionConflict) || errors.Is(err, ErrNotFound) || errors.Is(err, ErrDeleted) {
+			continue
+		}
+		if err != nil {
+			return lifted, fmt.Errorf("failed to lift restriction of user %s: %w", u.ID, err)
+		}
+		lifted++
+	}
+	return lifted, nil
+}
+
+// RunExpiryJob lifts expired restrictions every interval until ctx is cancelled.
+func (s *ModerationService) RunExpiryJob(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			// Drain full batches before waiting for the next tick
+			for {
+				n, err := s.LiftExpired(ctx)
+				if err != nil {
+					logging.Instance.Errorf("moderation expiry job failed: %v", err)
+					break
+				}
+				if n > 0 {
+					logging.Instance.Infof("lifted %d expired restrictions", n)
+				}
+				if n < expiryBatchSize {
+					break
+				}
+			}
+		}
+	}
+}
+
+func (s *ModerationService) liveUser(ctx context.Context, userID uuid.UUID) (*model.User, error) {
+	u, err := s.userRepo.GetUserById(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if u == nil {
+		return nil, ErrUserNotFound
+	}
+	if u.DeletedAt != nil {
+		return nil, ErrUserDeleted
+	}
+	return u, nil
+}
+
+// setModeration stores m on u, guarded by the version read, together with its event.
+func (s *ModerationService) setModeration(ctx context.Context, u *model.User, m *model.Moderation, eventType string, payload any) error {
+	patch := UserPatch{Fields: []string{FieldModeration}, Moderation: m}
+
+	var updated *model.User
+	err := s.events.atomically(ctx, func(ctx context.Context) error {
+		var err error
+		if updated, err = s.userRepo.PatchUser(ctx, u.ID, u.Version, patch); err != nil {
+			return err
+		}
+		if err := s.events.publish(ctx, eventType, payload); err != nil {
+			return err
+		}
+		return recordRevision(ctx, s.revisions, ActionModerate, u, updated)
+	})
+	if err != nil {
+		return err
+	}
+
+	// Invalidate cache
+	cacheKey := fmt.Sprintf("user:%s", u.ID)
+	if err := s.cache.Delete(ctx, cacheKey); err != nil {
+		logging.Instance.Warnf("failed to invalidate cache for moderated user %s: %v", u.ID, err)
+	}
+	return nil
+}
+
+// canSee reports whether the caller of ctx may read the profile id. Banned and
+// suspended profiles are visible to admins and their owner only.
+func canSee(ctx context.Context, id uuid.UUID, restricted bool) bool {
+	if !restricted {
+		return true
+	}
+	o := OriginFrom(ctx)
+	return o.Admin || (o.Actor != nil && *o.Actor == id)
+}
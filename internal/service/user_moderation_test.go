@@ -0,0 +1,177 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"userService/internal/events"
+	"userService/internal/model"
+)
+
+func newTestModerationService(repo *MockUserRepository, p *MockProducer, cache *MockCacheService, now time.Time) *ModerationService {
+	svc := NewModerationService(repo, p, cache)
+	svc.now = func() time.Time { return now }
+	return svc
+}
+
+func TestModerationService_BanUser(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	userID, moderatorID := uuid.New(), uuid.New()
+	ctx := WithOrigin(context.Background(), Origin{Source: SourceHTTP, Actor: &moderatorID, Admin: true})
+
+	t.Run("stores the ban and announces it", func(t *testing.T) {
+		repo := new(MockUserRepository)
+		p := new(MockProducer)
+		cache := new(MockCacheService)
+
+		want := &model.Moderation{Status: model.StatusBanned, Reason: "spam", ModeratorID: moderatorID, Since: now}
+		repo.On("GetUserById", mock.Anything, userID).Return(&model.User{ID: userID, Version: 4}, nil)
+		repo.On("PatchUser", mock.Anything, userID, int64(4), UserPatch{Fields: []string{FieldModeration}, Moderation: want}).
+			Return(&model.User{ID: userID, Version: 5, Moderation: want}, nil)
+		p.On("Produce", mock.Anything, events.UserBanned, events.UserBannedPayload{
+			UserID: userID, Status: model.StatusBanned, Reason: "spam", ModeratorID: moderatorID,
+		}).Return(nil)
+		cache.On("Delete", mock.Anything, fmt.Sprintf("user:%s", userID)).Return(nil)
+
+		err := newTestModerationService(repo, p, cache, now).BanUser(ctx, userID, ModerationAction{Reason: "  spam "})
+
+		assert.NoError(t, err)
+		repo.AssertExpectations(t)
+		p.AssertExpectations(t)
+		cache.AssertExpectations(t)
+	})
+
+	t.Run("deleted users cannot be banned", func(t *testing.T) {
+		repo := new(MockUserRepository)
+		deletedAt := now.Add(-time.Hour)
+		repo.On("GetUserById", mock.Anything, userID).Return(&model.User{ID: userID, DeletedAt: &deletedAt}, nil)
+
+		err := newTestModerationService(repo, nil, nil, now).BanUser(ctx, userID, ModerationAction{Reason: "spam"})
+
+		assert.ErrorIs(t, err, ErrUserDeleted)
+		repo.AssertNotCalled(t, "PatchUser", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func TestModerationService_SuspendUser_Validation(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	past := now.Add(-time.Minute)
+
+	tests := []struct {
+		name   string
+		action ModerationAction
+		fields []string
+	}{
+		{name: "reason and expiry are required", action: ModerationAction{}, fields: []string{"reason", "expires_at"}},
+		{name: "expiry must be in the future", action: ModerationAction{Reason: "spam", ExpiresAt: &past}, fields: []string{"expires_at"}},
+		{name: "reason is bounded", action: ModerationAction{Reason: strings.Repeat("a", MaxModerationReasonLength+1), ExpiresAt: &now}, fields: []string{"reason", "expires_at"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := new(MockUserRepository)
+
+			err := newTestModerationService(repo, nil, nil, now).SuspendUser(context.Background(), uuid.New(), tt.action)
+
+			var verr *ValidationError
+			assert.True(t, errors.As(err, &verr))
+			var fields []string
+			for _, f := range verr.Fields {
+				fields = append(fields, f.Field)
+			}
+			assert.Equal(t, tt.fields, fields)
+			repo.AssertNotCalled(t, "GetUserById", mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestModerationService_UnbanUser(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	userID := uuid.New()
+
+	t.Run("users that are not moderated cannot be unbanned", func(t *testing.T) {
+		repo := new(MockUserRepository)
+		repo.On("GetUserById", mock.Anything, userID).Return(&model.User{ID: userID}, nil)
+
+		err := newTestModerationService(repo, nil, nil, now).UnbanUser(context.Background(), userID, "")
+
+		assert.ErrorIs(t, err, ErrNotModerated)
+	})
+
+	t.Run("lifts the ban", func(t *testing.T) {
+		repo := new(MockUserRepository)
+		p := new(MockProducer)
+		cache := new(MockCacheService)
+		banned := &model.User{ID: userID, Version: 2, Moderation: &model.Moderation{Status: model.StatusBanned}}
+
+		repo.On("GetUserById", mock.Anything, userID).Return(banned, nil)
+		repo.On("PatchUser", mock.Anything, userID, int64(2), UserPatch{Fields: []string{FieldModeration}}).
+			Return(&model.User{ID: userID, Version: 3}, nil)
+		p.On("Produce", mock.Anything, events.UserUnbanned, events.UserUnbannedPayload{UserID: userID, Reason: "appeal"}).Return(nil)
+		cache.On("Delete", mock.Anything, fmt.Sprintf("user:%s", userID)).Return(nil)
+
+		err := newTestModerationService(repo, p, cache, now).UnbanUser(context.Background(), userID, "appeal")
+
+		assert.NoError(t, err)
+		repo.AssertExpectations(t)
+		p.AssertExpectations(t)
+	})
+}
+
+func TestModerationService_LiftExpired(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	expired := model.User{ID: uuid.New(), Version: 1, Moderation: &model.Moderation{Status: model.StatusSuspended}}
+	raced := model.User{ID: uuid.New(), Version: 1, Moderation: &model.Moderation{Status: model.StatusSuspended}}
+
+	repo := new(MockUserRepository)
+	p := new(MockProducer)
+	cache := new(MockCacheService)
+
+	repo.On("ListModerationExpiredBefore", mock.Anything, now, expiryBatchSize).Return([]model.User{expired, raced}, nil)
+	repo.On("PatchUser", mock.Anything, expired.ID, int64(1), mock.Anything).Return(&model.User{ID: expired.ID, Version: 2}, nil)
+	// A moderator changed the decision after the listing
+	repo.On("PatchUser", mock.Anything, raced.ID, int64(1), mock.Anything).Return(nil, ErrVersionConflict)
+	p.On("Produce", mock.Anything, events.UserUnbanned, events.UserUnbannedPayload{
+		UserID: expired.ID, Reason: "expired", Expired: true,
+	}).Return(nil)
+	cache.On("Delete", mock.Anything, fmt.Sprintf("user:%s", expired.ID)).Return(nil)
+
+	n, err := newTestModerationService(repo, p, cache, now).LiftExpired(context.Background())
+
+	assert.NoError(t, err)
+	assert.Equal(t, 1, n)
+	repo.AssertExpectations(t)
+	p.AssertExpectations(t)
+}
+
+func TestUserService_GetUserById_Moderated(t *testing.T) {
+	userID := uuid.New()
+	banned := &model.User{ID: userID, Moderation: &model.Moderation{Status: model.StatusBanned, Reason: "spam"}}
+
+	newService := func() *UserService {
+		cache := new(MockCacheService)
+		cache.On("Get", mock.Anything, mock.Anything).Return("", errors.New("cache miss"))
+		cache.On("Set", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+		repo := new(MockUserRepository)
+		repo.On("GetUserById", mock.Anything, userID).Return(banned, nil)
+		return NewUserService(repo, nil, nil, cache)
+	}
+
+	_, err := newService().GetUserById(context.Background(), userID)
+	assert.ErrorIs(t, err, ErrUserNotFound)
+
+	adminID := uuid.New()
+	resp, err := newService().GetUserById(WithOrigin(context.Background(), Origin{Actor: &adminID, Admin: true}), userID)
+	assert.NoError(t, err)
+	assert.Equal(t, model.StatusBanned, resp.AccountStatus)
+
+	_, err = newService().GetUserById(WithOrigin(context.Background(), Origin{Actor: &userID}), userID)
+	assert.NoError(t, err)
+}
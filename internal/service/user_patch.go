@@ -0,0 +1,306 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/Sayan80bayev/go-project/pkg/date"
+	"github.com/Sayan80bayev/go-project/pkg/logging"
+	"github.com/google/uuid"
+	"userService/internal/events"
+	"userService/internal/model"
+	"userService/internal/transport/response"
+)
+
+// Patchable profile fields, named as in the JSON body, the protobuf field mask and the stored document.
+const (
+	FieldFirstname   = "firstname"
+	FieldLastname    = "lastname"
+	FieldAbout       = "about"
+	FieldDateOfBirth = "date_of_birth"
+	FieldGender      = "gender"
+	FieldLocation    = "location"
+	FieldSocials     = "socials"
+)
+
+// Fields the service maintains itself. They may appear in a UserPatch built by
+// the service but are rejected in client patches.
+const (
+	FieldNeedsCompletion = "needs_completion"
+	FieldCompletedAt     = "completed_at"
+	FieldModeration      = "moderation"
+	FieldRoles           = "roles"
+	FieldPrivacy         = "privacy"
+	FieldHandle          = "handle"
+	FieldHandleChangedAt = "handle_changed_at"
+	FieldFormerHandles   = "former_handles"
+)
+
+var patchableFields = map[string]struct{}{
+	FieldFirstname: {}, FieldLastname: {}, FieldAbout: {}, FieldDateOfBirth: {},
+	FieldGender: {}, FieldLocation: {}, FieldSocials: {},
+}
+
+// UserPatch is a partial profile update. Only the fields named in Fields are
+// touched; a named field holding its zero value is removed from the profile.
+type UserPatch struct {
+	Fields []string
+
+	Firstname   string
+	Lastname    string
+	About       string
+	DateOfBirth *time.Time
+	Gender      string
+	Location    string
+	Socials     []string
+
+	NeedsCompletion bool
+	CompletedAt     *time.Time
+	Moderation      *model.Moderation
+	Roles           []string
+	Privacy         model.Privacy
+	Handle          string
+	HandleChangedAt *time.Time
+	FormerHandles   []model.FormerHandle
+}
+
+// Value returns the new value of field and whether it should be set (true) or removed (false).
+func (p UserPatch) Value(field string) (interface{}, bool) {
+	switch field {
+	case FieldFirstname:
+		return p.Firstname, p.Firstname != ""
+	case FieldLastname:
+		return p.Lastname, p.Lastname != ""
+	case FieldAbout:
+		return p.About, p.About != ""
+	case FieldDateOfBirth:
+		return p.DateOfBirth, p.DateOfBirth != nil
+	case FieldGender:
+		return p.Gender, p.Gender != ""
+	case FieldLocation:
+		return p.Location, p.Location != ""
+	case FieldSocials:
+		return p.Socials, len(p.Socials) > 0
+	case FieldNeedsCompletion:
+		return p.NeedsCompletion, true
+	case FieldCompletedAt:
+		return p.CompletedAt, p.CompletedAt != nil
+	case FieldModeration:
+		return p.Moderation, p.Moderation != nil
+	case FieldRoles:
+		return p.Roles, len(p.Roles) > 0
+	case FieldPrivacy:
+		return p.Privacy, len(p.Privacy) > 0
+	case FieldHandle:
+		return p.Handle, p.Handle != ""
+	case FieldHandleChangedAt:
+		return p.HandleChangedAt, p.HandleChangedAt != nil
+	case FieldFormerHandles:
+		return p.FormerHandles, len(p.FormerHandles) > 0
+	}
+	return nil, false
+}
+
+// ApplyPatch sets the fields named in patch on u.
+func ApplyPatch(u *model.User, patch UserPatch) {
+	for _, field := range patch.Fields {
+		value, _ := patch.Value(field)
+		switch field {
+		case FieldFirstname:
+			u.Firstname = value.(string)
+		case FieldLastname:
+			u.Lastname = value.(string)
+		case FieldAbout:
+			u.About = value.(string)
+		case FieldDateOfBirth:
+			u.DateOfBirth = cloneTime(value.(*time.Time))
+		case FieldGender:
+			u.Gender = value.(string)
+		case FieldLocation:
+			u.Location = value.(string)
+		case FieldSocials:
+			u.Socials = slices.Clone(value.([]string))
+		case FieldNeedsCompletion:
+			u.NeedsCompletion = value.(bool)
+		case FieldCompletedAt:
+			u.CompletedAt = cloneTime(value.(*time.Time))
+		case FieldModeration:
+			u.Moderation = cloneModeration(value.(*model.Moderation))
+		case FieldRoles:
+			u.Roles = slices.Clone(value.([]string))
+		case FieldPrivacy:
+			u.Privacy = maps.Clone(value.(model.Privacy))
+		case FieldHandle:
+			u.Handle = value.(string)
+		case FieldHandleChangedAt:
+			u.HandleChangedAt = cloneTime(value.(*time.Time))
+		case FieldFormerHandles:
+			u.FormerHandles = slices.Clone(value.([]model.FormerHandle))
+		}
+	}
+}
+
+func cloneModeration(m *model.Moderation) *model.Moderation {
+	if m == nil {
+		return nil
+	}
+	c := *m
+	c.ExpiresAt = cloneTime(m.ExpiresAt)
+	return &c
+}
+
+func cloneTime(t *time.Time) *time.Time {
+	if t == nil {
+		return nil
+	}
+	c := *t
+	return &c
+}
+
+// ParseMergePatch decodes an RFC 7396 JSON merge patch into a UserPatch.
+// A null member removes the field; arrays are replaced wholesale.
+func ParseMergePatch(doc []byte) (UserPatch, error) {
+	var patch UserPatch
+	verr := &ValidationError{}
+
+	var members map[string]json.RawMessage
+	if err := json.Unmarshal(doc, &members); err != nil || members == nil {
+		verr.add("body", "must be a JSON object")
+		return patch, verr
+	}
+
+	for field, raw := range members {
+		if _, ok := patchableFields[field]; !ok {
+			verr.add(field, "unknown or read-only field")
+			continue
+		}
+		patch.Fields = append(patch.Fields, field)
+		if bytes.Equal(bytes.TrimSpace(raw), []byte("null")) {
+			continue
+		}
+
+		var err error
+		switch field {
+		case FieldFirstname:
+			err = json.Unmarshal(raw, &patch.Firstname)
+		case FieldLastname:
+			err = json.Unmarshal(raw, &patch.Lastname)
+		case FieldAbout:
+			err = json.Unmarshal(raw, &patch.About)
+		case FieldGender:
+			err = json.Unmarshal(raw, &patch.Gender)
+		case FieldLocation:
+			err = json.Unmarshal(raw, &patch.Location)
+		case FieldSocials:
+			err = json.Unmarshal(raw, &patch.Socials)
+		case FieldDateOfBirth:
+			var s string
+			if err = json.Unmarshal(raw, &s); err == nil {
+				var dob time.Time
+				if dob, err = date.ParseDate(s); err == nil {
+					patch.DateOfBirth = &dob
+				}
+			}
+		}
+		if err != nil {
+			verr.add(field, "has the wrong type or format")
+		}
+	}
+
+	sort.Strings(patch.Fields)
+	return patch, verr.orNil()
+}
+
+// Validate checks every field named in the patch.
+func (p UserPatch) Validate() error {
+	verr := &ValidationError{}
+	if len(p.Fields) == 0 {
+		verr.add("body", "no fields to update")
+	}
+
+	for _, field := range p.Fields {
+		value, _ := p.Value(field)
+		validateField(verr, field, value)
+	}
+
+	return verr.orNil()
+}
+
+// PatchUser applies patch to userID and returns the updated profile.
+// expectedVersion must equal the stored version unless it is AnyVersion.
+func (s *UserService) PatchUser(ctx context.Context, userID uuid.UUID, patch UserPatch, expectedVersion int64) (*response.UserResponse, error) {
+	if err := patch.Validate(); err != nil {
+		return nil, err
+	}
+
+	u, err := s.userRepo.GetUserById(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if u == nil {
+		return nil, fmt.Errorf("%w: %s", ErrUserNotFound, userID)
+	}
+	if u.DeletedAt != nil {
+		return nil, ErrUserDeleted
+	}
+	if u.Moderation != nil && !OriginFrom(ctx).Admin {
+		return nil, ErrAccountRestricted
+	}
+	if expectedVersion != AnyVersion && u.Version != expectedVersion {
+		return nil, ErrPreconditionFailed
+	}
+
+	// Carry the completion state of the patched profile along with the patch
+	next := *u
+	ApplyPatch(&next, patch)
+	completed := s.completion.track(u, &next, time.Now())
+	if next.NeedsCompletion != u.NeedsCompletion {
+		patch.Fields = append(patch.Fields, FieldNeedsCompletion)
+		patch.NeedsCompletion = next.NeedsCompletion
+	}
+	if next.CompletedAt != u.CompletedAt {
+		patch.Fields = append(patch.Fields, FieldCompletedAt)
+		patch.CompletedAt = next.CompletedAt
+	}
+
+	// Persist patch together with its events
+	var updated *model.User
+	err = s.events.atomically(ctx, func(ctx context.Context) error {
+		var err error
+		if updated, err = s.userRepo.PatchUser(ctx, userID, u.Version, patch); err != nil {
+			return err
+		}
+		err = s.events.publish(ctx, events.UserUpdated, events.UserUpdatedPayload{
+			UserID:    userID,
+			OldURL:    u.AvatarURL,
+			AvatarURL: updated.AvatarURL,
+		})
+		if err != nil {
+			return err
+		}
+		if completed {
+			if err := s.publishCompleted(ctx, updated); err != nil {
+				return err
+			}
+		}
+		return recordRevision(ctx, s.revisions, ActionUpdate, u, updated)
+	})
+	if err != nil {
+		logging.Instance.Errorf("failed to patch user %s: %v", userID, err)
+		return nil, err
+	}
+	// Invalidate cache
+	cacheKey := fmt.Sprintf("user:%s", userID)
+	if err = s.cache.Delete(ctx, cacheKey); err != nil {
+		logging.Instance.Warnf("failed to invalidate cache for user %s: %v", userID, err)
+	}
+
+	ur := present(ctx, s.toResponse(*updated))
+	return &ur, nil
+}
@@ -0,0 +1,123 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"userService/internal/events"
+	"userService/internal/model"
+)
+
+func TestParseMergePatch(t *testing.T) {
+	t.Run("null removes a field and values are decoded", func(t *testing.T) {
+		patch, err := ParseMergePatch([]byte(`{"location":"Almaty","about":null,"date_of_birth":"02.01.2006"}`))
+
+		assert.NoError(t, err)
+		assert.Equal(t, []string{FieldAbout, FieldDateOfBirth, FieldLocation}, patch.Fields)
+		assert.Equal(t, "Almaty", patch.Location)
+		assert.NotNil(t, patch.DateOfBirth)
+
+		_, set := patch.Value(FieldAbout)
+		assert.False(t, set, "null should unset about")
+	})
+
+	t.Run("unknown and mistyped fields are reported", func(t *testing.T) {
+		_, err := ParseMergePatch([]byte(`{"email":"x@y.z","socials":"not-an-array"}`))
+
+		var verr *ValidationError
+		assert.ErrorAs(t, err, &verr)
+		assert.Len(t, verr.Fields, 2)
+	})
+
+	t.Run("non-object body is rejected", func(t *testing.T) {
+		_, err := ParseMergePatch([]byte(`[1,2]`))
+
+		assert.ErrorIs(t, err, ErrValidation)
+	})
+}
+
+func TestUserPatch_Validate(t *testing.T) {
+	patch := UserPatch{
+		Fields:    []string{FieldFirstname, FieldGender, FieldSocials},
+		Firstname: "",
+		Gender:    "unknown",
+		Socials:   []string{"https://github.com/tester", "nope"},
+	}
+
+	var verr *ValidationError
+	assert.ErrorAs(t, patch.Validate(), &verr)
+	assert.Equal(t, []FieldError{
+		{Field: FieldFirstname, Message: "is required and must be 2 to 20 characters"},
+		{Field: FieldGender, Message: "must be one of male, female, other"},
+		{Field: "socials[1]", Message: "must be a valid URL"},
+	}, verr.Fields)
+}
+
+func TestUserService_PatchUser(t *testing.T) {
+	userUUID := uuid.New()
+	patch := UserPatch{Fields: []string{FieldLocation}, Location: "Almaty"}
+	completedAt := time.Now().Add(-time.Hour)
+
+	t.Run("only the patch is written with the stored version", func(t *testing.T) {
+		repo := new(MockUserRepository)
+		p := new(MockProducer)
+		cache := new(MockCacheService)
+
+		stored := &model.User{ID: userUUID, Firstname: "Test", Lastname: "User", CompletedAt: &completedAt, Version: 4}
+		repo.On("GetUserById", mock.Anything, userUUID).Return(stored, nil)
+		repo.On("PatchUser", mock.Anything, userUUID, int64(4), patch).
+			Return(&model.User{ID: userUUID, Location: "Almaty", Version: 5}, nil)
+		cache.On("Delete", mock.Anything, fmt.Sprintf("user:%s", userUUID)).Return(nil)
+		p.On("Produce", mock.Anything, events.UserUpdated, mock.Anything).Return(nil)
+
+		svc := NewUserService(repo, nil, p, cache)
+		user, err := svc.PatchUser(context.Background(), userUUID, patch, AnyVersion)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "Almaty", user.Location)
+		assert.Equal(t, int64(5), user.Version)
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("stale version is rejected", func(t *testing.T) {
+		repo := new(MockUserRepository)
+		repo.On("GetUserById", mock.Anything, userUUID).Return(&model.User{ID: userUUID, Version: 4}, nil)
+
+		svc := NewUserService(repo, nil, nil, nil)
+		_, err := svc.PatchUser(context.Background(), userUUID, patch, 3)
+
+		assert.ErrorIs(t, err, ErrPreconditionFailed)
+		repo.AssertNotCalled(t, "PatchUser", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("completing a flagged profile clears the flag and emits ProfileCompleted", func(t *testing.T) {
+		repo := new(MockUserRepository)
+		p := new(MockProducer)
+		cache := new(MockCacheService)
+
+		names := UserPatch{Fields: []string{FieldFirstname, FieldLastname}, Firstname: "Test", Lastname: "User"}
+		repo.On("GetUserById", mock.Anything, userUUID).Return(&model.User{ID: userUUID, NeedsCompletion: true, Version: 1}, nil)
+		repo.On("PatchUser", mock.Anything, userUUID, int64(1), mock.MatchedBy(func(p UserPatch) bool {
+			return assert.ObjectsAreEqual([]string{FieldFirstname, FieldLastname, FieldNeedsCompletion, FieldCompletedAt}, p.Fields) &&
+				!p.NeedsCompletion && p.CompletedAt != nil
+		})).Return(&model.User{ID: userUUID, Firstname: "Test", Lastname: "User", CompletedAt: &completedAt, Version: 2}, nil)
+		cache.On("Delete", mock.Anything, mock.Anything).Return(nil)
+		p.On("Produce", mock.Anything, events.UserUpdated, mock.Anything).Return(nil)
+		p.On("Produce", mock.Anything, events.ProfileCompleted, mock.Anything).Return(nil).Once()
+
+		svc := NewUserService(repo, nil, p, cache)
+		owner := WithOrigin(context.Background(), Origin{Source: SourceHTTP, Actor: &userUUID})
+		user, err := svc.PatchUser(owner, userUUID, names, AnyVersion)
+
+		assert.NoError(t, err)
+		assert.False(t, user.NeedsCompletion)
+		assert.Equal(t, 25, user.Completeness)
+		repo.AssertExpectations(t)
+		p.AssertExpectations(t)
+	})
+}
@@ -0,0 +1,128 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+
+	"github.com/Sayan80bayev/go-project/pkg/logging"
+	"github.com/google/uuid"
+	"userService/internal/events"
+	"userService/internal/mappers"
+	"userService/internal/model"
+	"userService/internal/transport/response"
+)
+
+// UpdatePrivacy changes the visibility of the given profile fields. A nil
+// visibility resets the field to its default.
+func (s *UserService) UpdatePrivacy(ctx context.Context, userID uuid.UUID, changes map[string]*string) (*response.UserResponse, error) {
+	if err := validatePrivacy(changes); err != nil {
+		return nil, err
+	}
+
+	u, err := s.userRepo.GetUserById(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if u == nil {
+		return nil, fmt.Errorf("%w: %s", ErrUserNotFound, userID)
+	}
+	if u.DeletedAt != nil {
+		return nil, ErrUserDeleted
+	}
+	if u.Moderation != nil && !OriginFrom(ctx).Admin {
+		return nil, ErrAccountRestricted
+	}
+
+	// Store overrides only, so a changed default applies to untouched fields
+	privacy := maps.Clone(u.Privacy)
+	if privacy == nil {
+		privacy = model.Privacy{}
+	}
+	for field, v := range changes {
+		if v == nil || *v == model.DefaultVisibility[field] {
+			delete(privacy, field)
+		} else {
+			privacy[field] = *v
+		}
+	}
+	if maps.Equal(privacy, u.Privacy) {
+		ur := present(ctx, s.toResponse(*u))
+		return &ur, nil
+	}
+	if len(privacy) == 0 {
+		privacy = nil
+	}
+
+	patch := UserPatch{Fields: []string{FieldPrivacy}, Privacy: privacy}
+	var updated *model.User
+	err = s.events.atomically(ctx, func(ctx context.Context) error {
+		var err error
+		if updated, err = s.userRepo.PatchUser(ctx, userID, u.Version, patch); err != nil {
+			return err
+		}
+		err = s.events.publish(ctx, events.UserPrivacyChanged, events.UserPrivacyChangedPayload{
+			UserID:  userID,
+			Privacy: updated.Privacy,
+		})
+		if err != nil {
+			return err
+		}
+		return recordRevision(ctx, s.revisions, ActionPrivacy, u, updated)
+	})
+	if err != nil {
+		logging.Instance.Errorf("failed to update privacy of user %s: %v", userID, err)
+		return nil, err
+	}
+	// Invalidate cache
+	cacheKey := fmt.Sprintf("user:%s", userID)
+	if err = s.cache.Delete(ctx, cacheKey); err != nil {
+		logging.Instance.Warnf("failed to invalidate cache for user %s: %v", userID, err)
+	}
+
+	ur := present(ctx, s.toResponse(*updated))
+	return &ur, nil
+}
+
+func validatePrivacy(changes map[string]*string) error {
+	verr := &ValidationError{}
+	if len(changes) == 0 {
+		verr.add("privacy", "at least one field is required")
+	}
+	for _, field := range slices.Sorted(maps.Keys(changes)) {
+		v := changes[field]
+		if _, ok := model.DefaultVisibility[field]; !ok {
+			verr.add("privacy."+field, "visibility of this field cannot be changed")
+			continue
+		}
+		if v == nil {
+			continue
+		}
+		switch *v {
+		case model.VisibilityPublic, model.VisibilityAuthenticated, model.VisibilityPrivate:
+		default:
+			verr.add("privacy."+field, "must be public, authenticated or private")
+		}
+	}
+	return verr.orNil()
+}
+
+// audienceOf tells how the caller of ctx relates to the profile id.
+func audienceOf(ctx context.Context, id uuid.UUID) mappers.Audience {
+	o := OriginFrom(ctx)
+	switch {
+	case o.Admin:
+		return mappers.AudienceAdmin
+	case o.Actor != nil && *o.Actor == id:
+		return mappers.AudienceOwner
+	case o.Actor != nil:
+		return mappers.AudienceAuthenticated
+	}
+	return mappers.AudienceAnonymous
+}
+
+// present narrows a full response to the fields the caller of ctx may see.
+func present(ctx context.Context, ur response.UserResponse) response.UserResponse {
+	return mappers.UserView(ur, audienceOf(ctx, ur.ID))
+}
@@ -0,0 +1,174 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"userService/internal/events"
+	"userService/internal/model"
+)
+
+func TestUserService_Privacy_Views(t *testing.T) {
+	ownerID, otherID := uuid.New(), uuid.New()
+	dob := time.Date(1990, 1, 2, 0, 0, 0, 0, time.UTC)
+	user := &model.User{
+		ID: ownerID, Email: "owner@example.com", Firstname: "Owner", Lastname: "User",
+		DateOfBirth: &dob, Gender: "female", Location: "Almaty",
+		Privacy: model.Privacy{"location": model.VisibilityPrivate},
+		Roles:   []string{"user-admin"},
+	}
+
+	cases := []struct {
+		name             string
+		origin           Origin
+		email, location  string
+		dateOfBirth      bool
+		privacyDisclosed bool
+	}{
+		{"anonymous", Origin{Source: SourceHTTP}, "", "", false, false},
+		{"authenticated", Origin{Source: SourceHTTP, Actor: &otherID}, "", "", true, false},
+		{"owner", Origin{Source: SourceHTTP, Actor: &ownerID}, "owner@example.com", "Almaty", true, true},
+		{"admin", Origin{Source: SourceHTTP, Actor: &otherID, Admin: true}, "owner@example.com", "Almaty", true, true},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			repo := new(MockUserRepository)
+			cache := new(MockCacheService)
+			cache.On("Get", mock.Anything, fmt.Sprintf("user:%s", ownerID)).Return("", fmt.Errorf("cache miss"))
+			repo.On("GetUserById", mock.Anything, ownerID).Return(user, nil)
+			cache.On("Set", mock.Anything, fmt.Sprintf("user:%s", ownerID), mock.Anything, mock.Anything).Return(nil)
+
+			svc := NewUserService(repo, nil, nil, cache)
+			resp, err := svc.GetUserById(WithOrigin(context.Background(), tc.origin), ownerID)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tc.email, resp.Email)
+			assert.Equal(t, tc.location, resp.Location)
+			assert.Equal(t, tc.dateOfBirth, resp.DateOfBirth != nil)
+			assert.Equal(t, "female", resp.Gender)
+			assert.Equal(t, tc.privacyDisclosed, resp.Privacy != nil)
+			// Who holds which role is as private as the privacy settings
+			assert.Equal(t, tc.privacyDisclosed, resp.Roles != nil)
+		})
+	}
+}
+
+func TestUserService_Privacy_CompletionHidden(t *testing.T) {
+	ownerID := uuid.New()
+	dob := time.Date(1990, 1, 2, 0, 0, 0, 0, time.UTC)
+	hidden := model.Privacy{"date_of_birth": model.VisibilityPrivate, "location": model.VisibilityPrivate}
+	withHidden := &model.User{
+		ID: ownerID, Firstname: "Owner", Lastname: "User",
+		DateOfBirth: &dob, Location: "Almaty", Privacy: hidden,
+	}
+	withoutHidden := &model.User{ID: ownerID, Firstname: "Owner", Lastname: "User", Privacy: hidden}
+
+	view := func(user *model.User, origin Origin) ([]string, int) {
+		repo := new(MockUserRepository)
+		cache := new(MockCacheService)
+		cache.On("Get", mock.Anything, fmt.Sprintf("user:%s", ownerID)).Return("", fmt.Errorf("cache miss"))
+		repo.On("GetUserById", mock.Anything, ownerID).Return(user, nil)
+		cache.On("Set", mock.Anything, fmt.Sprintf("user:%s", ownerID), mock.Anything, mock.Anything).Return(nil)
+
+		resp, err := NewUserService(repo, nil, nil, cache).GetUserById(WithOrigin(context.Background(), origin), ownerID)
+		assert.NoError(t, err)
+		return resp.MissingFields, resp.Completeness
+	}
+
+	// A public viewer gets the same answer whether or not the hidden fields are set
+	missingSet, completenessSet := view(withHidden, Origin{Source: SourceHTTP})
+	missingUnset, completenessUnset := view(withoutHidden, Origin{Source: SourceHTTP})
+	assert.Equal(t, missingUnset, missingSet)
+	assert.Equal(t, completenessUnset, completenessSet)
+	assert.Empty(t, missingSet)
+	assert.Zero(t, completenessSet)
+
+	// The owner still sees what is left to fill in
+	missing, _ := view(withoutHidden, Origin{Source: SourceHTTP, Actor: &ownerID})
+	assert.Contains(t, missing, FieldLocation)
+}
+
+func TestUserService_UpdatePrivacy(t *testing.T) {
+	userID := uuid.New()
+	ctx := WithOrigin(context.Background(), Origin{Source: SourceHTTP, Actor: &userID})
+	visibility := func(v string) *string { return &v }
+
+	t.Run("stores overrides and drops defaults", func(t *testing.T) {
+		repo := new(MockUserRepository)
+		cache := new(MockCacheService)
+		repo.On("GetUserById", mock.Anything, userID).Return(&model.User{
+			ID: userID, Version: 4, Privacy: model.Privacy{"gender": model.VisibilityPrivate},
+		}, nil)
+		repo.On("PatchUser", mock.Anything, userID, int64(4), UserPatch{
+			Fields: []string{FieldPrivacy}, Privacy: model.Privacy{"email": model.VisibilityPublic},
+		}).Return(&model.User{ID: userID, Version: 5, Privacy: model.Privacy{"email": model.VisibilityPublic}}, nil)
+		cache.On("Delete", mock.Anything, fmt.Sprintf("user:%s", userID)).Return(nil)
+		p := new(MockProducer)
+		p.On("Produce", mock.Anything, events.UserPrivacyChanged, events.UserPrivacyChangedPayload{
+			UserID: userID, Privacy: map[string]string{"email": model.VisibilityPublic},
+		}).Return(nil)
+		revisions := new(MockRevisionRepository)
+		revisions.On("AppendRevision", mock.Anything, mock.MatchedBy(func(rev *model.UserRevision) bool {
+			return rev.Action == ActionPrivacy && rev.Version == 5 && len(rev.Changes) == 1 &&
+				rev.Changes[0].Field == FieldPrivacy
+		})).Return(nil)
+
+		svc := NewUserService(repo, nil, p, cache).WithRevisions(revisions)
+		resp, err := svc.UpdatePrivacy(ctx, userID, map[string]*string{
+			"email":         visibility(model.VisibilityPublic),
+			"gender":        nil,
+			"date_of_birth": visibility(model.VisibilityAuthenticated),
+		})
+
+		assert.NoError(t, err)
+		assert.Equal(t, model.VisibilityPublic, resp.Privacy["email"])
+		assert.Equal(t, model.VisibilityPublic, resp.Privacy["gender"])
+		repo.AssertExpectations(t)
+		cache.AssertExpectations(t)
+		p.AssertExpectations(t)
+		revisions.AssertExpectations(t)
+	})
+
+	t.Run("unchanged settings are not written", func(t *testing.T) {
+		repo := new(MockUserRepository)
+		repo.On("GetUserById", mock.Anything, userID).Return(&model.User{ID: userID}, nil)
+
+		svc := NewUserService(repo, nil, nil, nil)
+		_, err := svc.UpdatePrivacy(ctx, userID, map[string]*string{"email": visibility(model.VisibilityPrivate)})
+
+		assert.NoError(t, err)
+		repo.AssertNotCalled(t, "PatchUser", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("unknown fields and visibilities are rejected", func(t *testing.T) {
+		svc := NewUserService(new(MockUserRepository), nil, nil, nil)
+		_, err := svc.UpdatePrivacy(ctx, userID, map[string]*string{
+			"firstname": visibility(model.VisibilityPrivate),
+			"email":     visibility("friends"),
+		})
+
+		var verr *ValidationError
+		assert.ErrorAs(t, err, &verr)
+		assert.Equal(t, []FieldError{
+			{Field: "privacy.email", Message: "must be public, authenticated or private"},
+			{Field: "privacy.firstname", Message: "visibility of this field cannot be changed"},
+		}, verr.Fields)
+	})
+
+	t.Run("restricted owners cannot change settings", func(t *testing.T) {
+		repo := new(MockUserRepository)
+		repo.On("GetUserById", mock.Anything, userID).Return(&model.User{
+			ID: userID, Moderation: &model.Moderation{Status: model.StatusBanned},
+		}, nil)
+
+		svc := NewUserService(repo, nil, nil, nil)
+		_, err := svc.UpdatePrivacy(ctx, userID, map[string]*string{"email": visibility(model.VisibilityPublic)})
+
+		assert.ErrorIs(t, err, ErrAccountRestricted)
+	})
+}
@@ -0,0 +1,162 @@
+package service
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Sort keys accepted by ListUsers. A leading "-" sorts in descending order.
+const (
+	SortCreatedAt = "created_at"
+	SortUpdatedAt = "updated_at"
+
+	DefaultSort      = "-" + SortCreatedAt
+	DefaultPageLimit = 20
+	MaxPageLimit     = 100
+)
+
+// ListUsersParams is the caller-facing input of UserService.ListUsers.
+type ListUsersParams struct {
+	Limit  int
+	Sort   string
+	Cursor string
+	Filter UserFilter
+}
+
+// ListQuery is the decoded form of ListUsersParams handed to the repository.
+type ListQuery struct {
+	Limit     int
+	SortField string
+	Desc      bool
+	After     *Cursor
+	Filter    UserFilter
+}
+
+// UserFilter narrows a user listing. Zero-valued fields are not applied.
+type UserFilter struct {
+	Gender          string
+	Location        string
+	NeedsCompletion *bool
+	HasAvatar       *bool
+	CreatedFrom     *time.Time
+	CreatedTo       *time.Time
+	UpdatedFrom     *time.Time
+	UpdatedTo       *time.Time
+	// Moderated selects banned and suspended profiles (true) or the others (false)
+	Moderated *bool
+	// ExcludeIDs are never listed; they hide blockers from the caller
+	ExcludeIDs []uuid.UUID
+}
+
+// Validate rejects values no stored user could match.
+func (f UserFilter) Validate() error {
+	switch f.Gender {
+	case "", "male", "female", "other":
+	default:
+		return fmt.Errorf("%w: unknown gender %q", ErrInvalidFilter, f.Gender)
+	}
+	if f.CreatedFrom != nil && f.CreatedTo != nil && f.CreatedFrom.After(*f.CreatedTo) {
+		return fmt.Errorf("%w: created_from is after created_to", ErrInvalidFilter)
+	}
+	if f.UpdatedFrom != nil && f.UpdatedTo != nil && f.UpdatedFrom.After(*f.UpdatedTo) {
+		return fmt.Errorf("%w: updated_from is after updated_to", ErrInvalidFilter)
+	}
+	return nil
+}
+
+// Cursor marks the last item of a page. It is handed to clients as an opaque token.
+type Cursor struct {
+	Sort  string    `json:"s"`
+	Value time.Time `json:"v"`
+	ID    uuid.UUID `json:"id"`
+}
+
+// Encode serializes the cursor into a URL-safe token.
+func (c Cursor) Encode() string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// DecodeCursor parses a token produced by Cursor.Encode.
+func DecodeCursor(token string) (*Cursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	var c Cursor
+	if err := json.Unmarshal(data, &c); err != nil || c.ID == uuid.Nil {
+		return nil, ErrInvalidCursor
+	}
+	return &c, nil
+}
+
+// ParseSort splits a sort key such as "-created_at" into field and direction.
+func ParseSort(sort string) (field string, desc bool, err error) {
+	if sort == "" {
+		sort = DefaultSort
+	}
+	field = strings.TrimPrefix(sort, "-")
+	desc = field != sort
+
+	switch field {
+	case SortCreatedAt, SortUpdatedAt:
+		return field, desc, nil
+	default:
+		return "", false, fmt.Errorf("%w: %q", ErrInvalidSort, sort)
+	}
+}
+
+// buildListQuery validates params and decodes the cursor.
+func buildListQuery(p ListUsersParams) (ListQuery, error) {
+	q := ListQuery{Filter: p.Filter}
+
+	var err error
+	if q.Limit, err = normalizeLimit(p.Limit); err != nil {
+		return q, err
+	}
+
+	if err = q.Filter.Validate(); err != nil {
+		return q, err
+	}
+
+	if q.SortField, q.Desc, err = ParseSort(p.Sort); err != nil {
+		return q, err
+	}
+
+	if p.Cursor != "" {
+		if q.After, err = DecodeCursor(p.Cursor); err != nil {
+			return q, err
+		}
+		// A cursor is only meaningful for the ordering it was issued for
+		if q.After.Sort != sortKey(q.SortField, q.Desc) {
+			return q, ErrInvalidCursor
+		}
+	}
+
+	return q, nil
+}
+
+// normalizeLimit applies the default page size and caps it at MaxPageLimit.
+func normalizeLimit(limit int) (int, error) {
+	switch {
+	case limit < 0:
+		return 0, ErrInvalidLimit
+	case limit == 0:
+		return DefaultPageLimit, nil
+	case limit > MaxPageLimit:
+		return MaxPageLimit, nil
+	}
+	return limit, nil
+}
+
+func sortKey(field string, desc bool) string {
+	if desc {
+		return "-" + field
+	}
+	return field
+}
@@ -0,0 +1,401 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"reflect"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+	"userService/internal/model"
+	"userService/internal/transport/response"
+)
+
+// Sources of a profile change, recorded on every revision.
+const (
+	SourceHTTP  = "http"
+	SourceGRPC  = "grpc"
+	SourceEvent = "event"
+)
+
+// Revision actions.
+const (
+	ActionCreate   = "create"
+	ActionUpdate   = "update"
+	ActionDelete   = "delete"
+	ActionRestore  = "restore"
+	ActionModerate = "moderate"
+	ActionRoles    = "roles"
+	ActionHandle   = "handle"
+	ActionPrivacy  = "privacy"
+)
+
+// historySort is the only ordering of a profile history, newest first.
+const historySort = "-" + SortCreatedAt
+
+// dateOfBirthLayout is how date_of_birth values are stored in revisions.
+const dateOfBirthLayout = "2006-01-02"
+
+// RevisionRepository stores the change history of user profiles.
+type RevisionRepository interface {
+	AppendRevision(ctx context.Context, rev *model.UserRevision) error
+	// ListRevisions returns up to limit revisions of userID, newest first, starting after the cursor.
+	ListRevisions(ctx context.Context, userID uuid.UUID, limit int, after *Cursor) ([]model.UserRevision, error)
+	// GetRevision returns nil, nil if userID has no revision id.
+	GetRevision(ctx context.Context, userID, id uuid.UUID) (*model.UserRevision, error)
+	DeleteRevisions(ctx context.Context, userID uuid.UUID) error
+}
+
+// Origin tells through which entry point and by whom a change was made.
+type Origin struct {
+	Source string
+	Actor  *uuid.UUID
+	// Admin is set when the actor holds the admin role; admins also see
+	// banned and suspended profiles
+	Admin bool
+}
+
+type originKey struct{}
+
+// WithOrigin returns a copy of ctx whose writes are recorded as coming from o.
+func WithOrigin(ctx context.Context, o Origin) context.Context {
+	return context.WithValue(ctx, originKey{}, o)
+}
+
+// OriginFrom returns the Origin attached by WithOrigin, or the zero Origin.
+func OriginFrom(ctx context.Context) Origin {
+	o, _ := ctx.Value(originKey{}).(Origin)
+	return o
+}
+
+// HistoryParams is the caller-facing input of UserService.GetUserHistory.
+type HistoryParams struct {
+	Limit  int
+	Cursor string
+}
+
+// WithRevisions enables recording the change history of profiles.
+func (s *UserService) WithRevisions(revisions RevisionRepository) *UserService {
+	s.revisions = revisions
+	return s
+}
+
+// GetUserHistory returns one page of the revisions of userID, newest first.
+// Soft-deleted profiles keep their history until they are purged.
+func (s *UserService) GetUserHistory(ctx context.Context, userID uuid.UUID, p HistoryParams) (*response.UserRevisionPageResponse, error) {
+	limit, err := normalizeLimit(p.Limit)
+	if err != nil {
+		return nil, err
+	}
+
+	var after *Cursor
+	if p.Cursor != "" {
+		if after, err = DecodeCursor(p.Cursor); err != nil {
+			return nil, err
+		}
+		if after.Sort != historySort {
+			return nil, ErrInvalidCursor
+		}
+	}
+
+	if err := s.requireUser(ctx, userID); err != nil {
+		return nil, err
+	}
+
+	// Fetch one extra revision to learn whether another page exists
+	revisions, err := s.revisions.ListRevisions(ctx, userID, limit+1, after)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &response.UserRevisionPageResponse{}
+	if len(revisions) > limit {
+		revisions = revisions[:limit]
+		last := revisions[limit-1]
+		page.NextCursor = Cursor{Sort: historySort, Value: last.CreatedAt, ID: last.ID}.Encode()
+	}
+	page.Items = s.revisionMapper.MapEach(revisions)
+
+	return page, nil
+}
+
+// GetUserAtRevision returns the profile of userID as it was right after revisionID.
+// It starts from the current profile and undoes every later revision.
+func (s *UserService) GetUserAtRevision(ctx context.Context, userID, revisionID uuid.UUID) (*response.UserResponse, error) {
+	user, err := s.userRepo.GetUserById(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, ErrUserNotFound
+	}
+
+	target, err := s.revisions.GetRevision(ctx, userID, revisionID)
+	if err != nil {
+		return nil, err
+	}
+	if target == nil {
+		return nil, ErrRevisionNotFound
+	}
+
+	var after *Cursor
+	for {
+		revisions, err := s.revisions.ListRevisions(ctx, userID, MaxPageLimit, after)
+		if err != nil {
+			return nil, err
+		}
+		for _, rev := range revisions {
+			if rev.ID == target.ID {
+				user.Version = rev.Version
+				user.UpdatedAt = rev.CreatedAt
+				ur := present(ctx, s.toResponse(*user))
+				return &ur, nil
+			}
+			undoRevision(user, rev)
+		}
+		if len(revisions) < MaxPageLimit {
+			return nil, ErrRevisionNotFound
+		}
+		last := revisions[len(revisions)-1]
+		after = &Cursor{Sort: historySort, Value: last.CreatedAt, ID: last.ID}
+	}
+}
+
+// requireUser fails with ErrUserNotFound unless userID exists, deleted or not.
+func (s *UserService) requireUser(ctx context.Context, userID uuid.UUID) error {
+	user, err := s.userRepo.GetUserById(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if user == nil {
+		return ErrUserNotFound
+	}
+	return nil
+}
+
+// recordRevision appends the difference between before and after to the
+// history of after. A nil before records a newly created profile. It runs in
+// the transaction of the change, so a change is never stored without its
+// revision.
+func recordRevision(ctx context.Context, revisions RevisionRepository, action string, before, after *model.User) error {
+	if revisions == nil {
+		return nil
+	}
+
+	changes := diffUsers(before, after)
+	if len(changes) == 0 {
+		return nil
+	}
+
+	origin := OriginFrom(ctx)
+	rev := &model.UserRevision{
+		ID:        uuid.Must(uuid.NewV7()),
+		UserID:    after.ID,
+		Version:   after.Version,
+		Action:    action,
+		Changes:   changes,
+		Actor:     origin.Actor,
+		Source:    origin.Source,
+		CreatedAt: time.Now().UTC(),
+	}
+	if err := revisions.AppendRevision(ctx, rev); err != nil {
+		return fmt.Errorf("failed to record %s revision of user %s: %w", action, after.ID, err)
+	}
+	return nil
+}
+
+// revisionFields lists the tracked profile fields in the order changes are reported.
+var revisionFields = []string{
+	FieldFirstname, FieldLastname, "email", FieldHandle, FieldAbout, FieldDateOfBirth,
+	"avatar_url", FieldGender, FieldLocation, FieldSocials, FieldPrivacy, "needs_completion", "status", FieldRoles, "deleted_at",
+}
+
+// diffUsers lists the tracked fields whose values differ between before and after.
+func diffUsers(before, after *model.User) []model.FieldChange {
+	if before == nil {
+		before = &model.User{}
+	}
+
+	var changes []model.FieldChange
+	for _, field := range revisionFields {
+		old, cur := fieldValue(before, field), fieldValue(after, field)
+		if !reflect.DeepEqual(old, cur) {
+			changes = append(changes, model.FieldChange{Field: field, Old: old, New: cur})
+		}
+	}
+	return changes
+}
+
+// fieldValue returns the revision representation of field: a string, a
+// []string, a map[string]string or a bool, and nil when the field is not set.
+func fieldValue(u *model.User, field string) any {
+	switch field {
+	case FieldFirstname:
+		return stringValue(u.Firstname)
+	case FieldLastname:
+		return stringValue(u.Lastname)
+	case "email":
+		return stringValue(u.Email)
+	case FieldHandle:
+		return stringValue(u.Handle)
+	case FieldAbout:
+		return stringValue(u.About)
+	case FieldDateOfBirth:
+		if u.DateOfBirth == nil {
+			return nil
+		}
+		return u.DateOfBirth.UTC().Format(dateOfBirthLayout)
+	case "avatar_url":
+		return stringValue(u.AvatarURL)
+	case FieldGender:
+		return stringValue(u.Gender)
+	case FieldLocation:
+		return stringValue(u.Location)
+	case FieldSocials:
+		if len(u.Socials) == 0 {
+			return nil
+		}
+		return slices.Clone(u.Socials)
+	case FieldPrivacy:
+		if len(u.Privacy) == 0 {
+			return nil
+		}
+		return map[string]string(maps.Clone(u.Privacy))
+	case "needs_completion":
+		return u.NeedsCompletion
+	case FieldRoles:
+		if len(u.Roles) == 0 {
+			return nil
+		}
+		return slices.Clone(u.Roles)
+	case "status":
+		if u.Moderation == nil {
+			return nil
+		}
+		return u.Moderation.Status
+	case "deleted_at":
+		if u.DeletedAt == nil {
+			return nil
+		}
+		return u.DeletedAt.UTC().Format(time.RFC3339Nano)
+	}
+	return nil
+}
+
+func stringValue(s string) any {
+	if s == "" {
+		return nil
+	}
+	return s
+}
+
+// undoRevision sets every field changed by rev back to its old value.
+func undoRevision(u *model.User, rev model.UserRevision) {
+	for _, c := range rev.Changes {
+		setFieldValue(u, c.Field, c.Old)
+	}
+}
+
+// setFieldValue is the inverse of fieldValue. Values read back from storage
+// may have lost their Go types, so lists are converted element by element.
+func setFieldValue(u *model.User, field string, value any) {
+	s, _ := value.(string)
+	switch field {
+	case FieldFirstname:
+		u.Firstname = s
+	case FieldLastname:
+		u.Lastname = s
+	case "email":
+		u.Email = s
+	case FieldHandle:
+		u.Handle = s
+	case FieldAbout:
+		u.About = s
+	case FieldDateOfBirth:
+		u.DateOfBirth = parseTimeValue(dateOfBirthLayout, s)
+	case "avatar_url":
+		u.AvatarURL = s
+	case FieldGender:
+		u.Gender = s
+	case FieldLocation:
+		u.Location = s
+	case FieldSocials:
+		u.Socials = stringsValue(value)
+	case FieldPrivacy:
+		u.Privacy = privacyValue(value)
+	case "needs_completion":
+		b, _ := value.(bool)
+		u.NeedsCompletion = b
+	case FieldRoles:
+		u.Roles = stringsValue(value)
+	case "status":
+		// Only the status is tracked; reason and moderator are not restored
+		u.Moderation = nil
+		if s != "" {
+			u.Moderation = &model.Moderation{Status: s}
+		}
+	case "deleted_at":
+		u.DeletedAt = parseTimeValue(time.RFC3339Nano, s)
+	}
+}
+
+func parseTimeValue(layout, s string) *time.Time {
+	t, err := time.Parse(layout, s)
+	if err != nil {
+		return nil
+	}
+	return &t
+}
+
+// stringsValue converts any slice of strings, such as a decoded BSON or JSON array, to []string.
+func stringsValue(value any) []string {
+	v := reflect.ValueOf(value)
+	if v.Kind() != reflect.Slice || v.Len() == 0 {
+		return nil
+	}
+	out := make([]string, 0, v.Len())
+	for i := 0; i < v.Len(); i++ {
+		if s, ok := v.Index(i).Interface().(string); ok {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+// privacyValue converts any map of strings, such as a decoded JSON object or
+// the key/value pairs of a BSON document, to model.Privacy.
+func privacyValue(value any) model.Privacy {
+	privacy := model.Privacy{}
+	set := func(key string, v reflect.Value) {
+		if s, ok := v.Interface().(string); ok {
+			privacy[key] = s
+		}
+	}
+
+	v := reflect.ValueOf(value)
+	switch v.Kind() {
+	case reflect.Map:
+		for iter := v.MapRange(); iter.Next(); {
+			set(iter.Key().String(), iter.Value())
+		}
+	case reflect.Slice:
+		for i := 0; i < v.Len(); i++ {
+			e := reflect.Indirect(v.Index(i))
+			if e.Kind() != reflect.Struct {
+				continue
+			}
+			if key := e.FieldByName("Key"); key.Kind() == reflect.String {
+				if val := e.FieldByName("Value"); val.IsValid() {
+					set(key.String(), val)
+				}
+			}
+		}
+	}
+
+	if len(privacy) == 0 {
+		return nil
+	}
+	return privacy
+}
@@ -0,0 +1,185 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"userService/internal/events"
+	"userService/internal/model"
+)
+
+type MockRevisionRepository struct {
+	mock.Mock
+}
+
+func (m *MockRevisionRepository) AppendRevision(ctx context.Context, rev *model.UserRevision) error {
+	args := m.Called(ctx, rev)
+	return args.Error(0)
+}
+
+func (m *MockRevisionRepository) ListRevisions(ctx context.Context, userID uuid.UUID, limit int, after *Cursor) ([]model.UserRevision, error) {
+	args := m.Called(ctx, userID, limit, after)
+	return args.Get(0).([]model.UserRevision), args.Error(1)
+}
+
+func (m *MockRevisionRepository) GetRevision(ctx context.Context, userID, id uuid.UUID) (*model.UserRevision, error) {
+	args := m.Called(ctx, userID, id)
+	rev, _ := args.Get(0).(*model.UserRevision)
+	return rev, args.Error(1)
+}
+
+func (m *MockRevisionRepository) DeleteRevisions(ctx context.Context, userID uuid.UUID) error {
+	args := m.Called(ctx, userID)
+	return args.Error(0)
+}
+
+func TestDiffUsers(t *testing.T) {
+	dob := time.Date(1990, 5, 17, 0, 0, 0, 0, time.UTC)
+	before := &model.User{Firstname: "Ada", Lastname: "Byron", Socials: []string{"https://a.example"}}
+	after := &model.User{Firstname: "Ada", Lastname: "Lovelace", DateOfBirth: &dob}
+
+	assert.Equal(t, []model.FieldChange{
+		{Field: FieldLastname, Old: "Byron", New: "Lovelace"},
+		{Field: FieldDateOfBirth, Old: nil, New: "1990-05-17"},
+		{Field: FieldSocials, Old: []string{"https://a.example"}, New: nil},
+	}, diffUsers(before, after))
+
+	// A new profile reports every field that is set
+	created := diffUsers(nil, before)
+	assert.Len(t, created, 3)
+	assert.Empty(t, diffUsers(before, before))
+}
+
+func TestUndoRevision_Privacy(t *testing.T) {
+	want := model.Privacy{"email": model.VisibilityPublic}
+
+	// Stored revisions come back as JSON objects or BSON key/value pairs
+	for _, old := range []any{
+		map[string]any{"email": model.VisibilityPublic},
+		primitive.D{{Key: "email", Value: model.VisibilityPublic}},
+	} {
+		u := &model.User{Privacy: model.Privacy{"email": model.VisibilityPrivate}}
+		undoRevision(u, model.UserRevision{Changes: []model.FieldChange{{Field: FieldPrivacy, Old: old}}})
+		assert.Equal(t, want, u.Privacy)
+	}
+}
+
+func TestUserService_PatchUser_RecordsRevision(t *testing.T) {
+	userID, actor := uuid.New(), uuid.New()
+	repo := new(MockUserRepository)
+	revisions := new(MockRevisionRepository)
+	p := new(MockProducer)
+	cache := new(MockCacheService)
+
+	repo.On("GetUserById", mock.Anything, userID).Return(&model.User{ID: userID, About: "old", Version: 1}, nil)
+	repo.On("PatchUser", mock.Anything, userID, int64(1), mock.Anything).
+		Return(&model.User{ID: userID, About: "new", Version: 2}, nil)
+	cache.On("Delete", mock.Anything, fmt.Sprintf("user:%s", userID)).Return(nil)
+	p.On("Produce", mock.Anything, events.UserUpdated, mock.Anything).Return(nil)
+	revisions.On("AppendRevision", mock.Anything, mock.MatchedBy(func(rev *model.UserRevision) bool {
+		return rev.UserID == userID &&
+			rev.Version == 2 &&
+			rev.Action == ActionUpdate &&
+			rev.Source == SourceHTTP &&
+			rev.Actor != nil && *rev.Actor == actor &&
+			assert.ObjectsAreEqual([]model.FieldChange{{Field: FieldAbout, Old: "old", New: "new"}}, rev.Changes)
+	})).Return(nil)
+
+	svc := NewUserService(repo, nil, p, cache).WithRevisions(revisions)
+	ctx := WithOrigin(context.Background(), Origin{Source: SourceHTTP, Actor: &actor})
+	_, err := svc.PatchUser(ctx, userID, UserPatch{Fields: []string{FieldAbout}, About: "new"}, AnyVersion)
+
+	require.NoError(t, err)
+	revisions.AssertExpectations(t)
+}
+
+func TestUserService_PatchUser_FailsWithoutRevision(t *testing.T) {
+	userID := uuid.New()
+	repo := new(MockUserRepository)
+	revisions := new(MockRevisionRepository)
+	p := new(MockProducer)
+	cache := new(MockCacheService)
+
+	repo.On("GetUserById", mock.Anything, userID).Return(&model.User{ID: userID, About: "old", Version: 1}, nil)
+	repo.On("PatchUser", mock.Anything, userID, int64(1), mock.Anything).
+		Return(&model.User{ID: userID, About: "new", Version: 2}, nil)
+	cache.On("Delete", mock.Anything, mock.Anything).Return(nil).Maybe()
+	p.On("Produce", mock.Anything, events.UserUpdated, mock.Anything).Return(nil)
+	revisions.On("AppendRevision", mock.Anything, mock.Anything).Return(fmt.Errorf("history unavailable"))
+
+	svc := NewUserService(repo, nil, p, cache).WithRevisions(revisions)
+	_, err := svc.PatchUser(context.Background(), userID, UserPatch{Fields: []string{FieldAbout}, About: "new"}, AnyVersion)
+
+	// The transaction is rolled back, so the change is reported as failed
+	require.ErrorContains(t, err, "history unavailable")
+}
+
+func TestUserService_GetUserHistory(t *testing.T) {
+	userID := uuid.New()
+	repo := new(MockUserRepository)
+	revisions := new(MockRevisionRepository)
+
+	now := time.Now().UTC()
+	page := []model.UserRevision{
+		{ID: uuid.New(), UserID: userID, Version: 3, CreatedAt: now},
+		{ID: uuid.New(), UserID: userID, Version: 2, CreatedAt: now.Add(-time.Minute)},
+		{ID: uuid.New(), UserID: userID, Version: 1, CreatedAt: now.Add(-2 * time.Minute)},
+	}
+	repo.On("GetUserById", mock.Anything, userID).Return(&model.User{ID: userID}, nil)
+	revisions.On("ListRevisions", mock.Anything, userID, 3, (*Cursor)(nil)).Return(page, nil)
+
+	svc := NewUserService(repo, nil, nil, nil).WithRevisions(revisions)
+	res, err := svc.GetUserHistory(context.Background(), userID, HistoryParams{Limit: 2})
+	require.NoError(t, err)
+	require.Len(t, res.Items, 2)
+	require.NotEmpty(t, res.NextCursor)
+
+	c, err := DecodeCursor(res.NextCursor)
+	require.NoError(t, err)
+	assert.Equal(t, page[1].ID, c.ID)
+
+	// Cursors of user listings are not accepted
+	listCursor := Cursor{Sort: SortCreatedAt, Value: now, ID: uuid.New()}.Encode()
+	_, err = svc.GetUserHistory(context.Background(), userID, HistoryParams{Cursor: listCursor})
+	assert.ErrorIs(t, err, ErrInvalidCursor)
+}
+
+func TestUserService_GetUserAtRevision(t *testing.T) {
+	userID := uuid.New()
+	repo := new(MockUserRepository)
+	revisions := new(MockRevisionRepository)
+
+	now := time.Now().UTC()
+	first := model.UserRevision{ID: uuid.New(), UserID: userID, Version: 1, CreatedAt: now.Add(-2 * time.Minute),
+		Changes: []model.FieldChange{{Field: FieldFirstname, New: "Ada"}}}
+	second := model.UserRevision{ID: uuid.New(), UserID: userID, Version: 2, CreatedAt: now.Add(-time.Minute),
+		Changes: []model.FieldChange{{Field: FieldLocation, Old: "London", New: "Paris"}}}
+	// Values read back from storage lose their Go types
+	third := model.UserRevision{ID: uuid.New(), UserID: userID, Version: 3, CreatedAt: now,
+		Changes: []model.FieldChange{{Field: FieldSocials, Old: []any{"https://a.example"}, New: nil}}}
+
+	repo.On("GetUserById", mock.Anything, userID).
+		Return(&model.User{ID: userID, Firstname: "Ada", Location: "Paris", Version: 3}, nil)
+	revisions.On("GetRevision", mock.Anything, userID, second.ID).Return(&second, nil)
+	revisions.On("GetRevision", mock.Anything, userID, mock.Anything).Return(nil, nil)
+	revisions.On("ListRevisions", mock.Anything, userID, MaxPageLimit, (*Cursor)(nil)).
+		Return([]model.UserRevision{third, second, first}, nil)
+
+	svc := NewUserService(repo, nil, nil, nil).WithRevisions(revisions)
+
+	user, err := svc.GetUserAtRevision(context.Background(), userID, second.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "Paris", user.Location)
+	assert.Equal(t, []string{"https://a.example"}, user.Socials)
+	assert.Equal(t, int64(2), user.Version)
+
+	_, err = svc.GetUserAtRevision(context.Background(), userID, uuid.New())
+	assert.ErrorIs(t, err, ErrRevisionNotFound)
+}
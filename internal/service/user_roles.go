@@ -0,0 +1,178 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/Sayan80bayev/go-project/pkg/caching"
+	"github.com/Sayan80bayev/go-project/pkg/logging"
+	"github.com/Sayan80bayev/go-project/pkg/messaging"
+	"github.com/google/uuid"
+	"userService/internal/events"
+	"userService/internal/model"
+)
+
+// RoleDirectory mirrors application roles to the identity provider, so they
+// appear in the tokens it issues. Adding a role a user already has, or
+// removing one they lack, must succeed.
+type RoleDirectory interface {
+	AddRoles(ctx context.Context, userID uuid.UUID, roles []string) error
+	RemoveRoles(ctx context.Context, userID uuid.UUID, roles []string) error
+}
+
+// RoleService grants and revokes application roles. Roles are stored with the
+// profile and pushed to the RoleDirectory before the profile is written.
+type RoleService struct {
+	userRepo   UserRepository
+	events     *publisher
+	cache      caching.CacheService
+	revisions  RevisionRepository
+	directory  RoleDirectory
+	assignable []string
+}
+
+// NewRoleService creates a service that may assign only the assignable roles.
+// A nil directory keeps roles in the profile only.
+func NewRoleService(
+	userRepo UserRepository,
+	producer messaging.Producer,
+	cache caching.CacheService,
+	directory RoleDirectory,
+	assignable []string,
+) *RoleService {
+	return &RoleService{
+		userRepo:   userRepo,
+		events:     &publisher{producer: producer},
+		cache:      cache,
+		directory:  directory,
+		assignable: assignable,
+	}
+}
+
+// WithOutbox commits events in the same transaction as the writes that cause them.
+func (s *RoleService) WithOutbox(tx Transactor, outbox OutboxRepository) *RoleService {
+	s.events.tx, s.events.outbox = tx, outbox
+	return s
+}
+
+// WithRevisions records role changes in the profile history.
+func (s *RoleService) WithRevisions(revisions RevisionRepository) *RoleService {
+	s.revisions = revisions
+	return s
+}
+
+// GrantRoles adds roles to userID and returns the resulting roles. Roles the
+// user already holds are ignored.
+func (s *RoleService) GrantRoles(ctx context.Context, userID uuid.UUID, roles []string) ([]string, error) {
+	return s.change(ctx, userID, roles, nil)
+}
+
+// RevokeRoles removes roles from userID and returns the resulting roles. Roles
+// the user does not hold are ignored.
+func (s *RoleService) RevokeRoles(ctx context.Context, userID uuid.UUID, roles []string) ([]string, error) {
+	return s.change(ctx, userID, nil, roles)
+}
+
+func (s *RoleService) change(ctx context.Context, userID uuid.UUID, grant, revoke []string) ([]string, error) {
+	if err := s.validate(append(slices.Clone(grant), revoke...)); err != nil {
+		return nil, err
+	}
+
+	u, err := s.userRepo.GetUserById(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if u == nil {
+		return nil, ErrUserNotFound
+	}
+	if u.DeletedAt != nil {
+		return nil, ErrUserDeleted
+	}
+
+	var granted, revoked []string
+	for _, role := range grant {
+		if !slices.Contains(u.Roles, role) && !slices.Contains(granted, role) {
+			granted = append(granted, role)
+		}
+	}
+	for _, role := range revoke {
+		if slices.Contains(u.Roles, role) && !slices.Contains(revoked, role) {
+			revoked = append(revoked, role)
+		}
+	}
+	if len(granted) == 0 && len(revoked) == 0 {
+		return u.Roles, nil
+	}
+
+	roles := slices.DeleteFunc(append(slices.Clone(u.Roles), granted...), func(role string) bool {
+		return slices.Contains(revoked, role)
+	})
+	slices.Sort(roles)
+
+	if err := s.push(ctx, userID, granted, revoked); err != nil {
+		logging.Instance.Errorf("failed to sync roles of user %s: %v", userID, err)
+		return nil, fmt.Errorf("%w: %v", ErrRoleSync, err)
+	}
+
+	patch := UserPatch{Fields: []string{FieldRoles}, Roles: roles}
+	var updated *model.User
+	err = s.events.atomically(ctx, func(ctx context.Context) error {
+		var err error
+		if updated, err = s.userRepo.PatchUser(ctx, userID, u.Version, patch); err != nil {
+			return err
+		}
+		err = s.events.publish(ctx, events.UserRolesChanged, events.UserRolesChangedPayload{
+			UserID:    userID,
+			Roles:     roles,
+			Granted:   granted,
+			Revoked:   revoked,
+			ChangedBy: OriginFrom(ctx).Actor,
+		})
+		if err != nil {
+			return err
+		}
+		return recordRevision(ctx, s.revisions, ActionRoles, u, updated)
+	})
+	if err != nil {
+		// Undo the directory change so it keeps matching the stored roles
+		if undoErr := s.push(ctx, userID, revoked, granted); undoErr != nil {
+			logging.Instance.Errorf("roles of user %s diverged from the identity provider: %v", userID, undoErr)
+		}
+		return nil, err
+	}
+	// Invalidate cache
+	cacheKey := fmt.Sprintf("user:%s", userID)
+	if err := s.cache.Delete(ctx, cacheKey); err != nil {
+		logging.Instance.Warnf("failed to invalidate cache for user %s: %v", userID, err)
+	}
+	return roles, nil
+}
+
+func (s *RoleService) validate(roles []string) error {
+	verr := &ValidationError{}
+	if len(roles) == 0 {
+		verr.add("roles", "at least one role is required")
+	}
+	for _, role := range roles {
+		if !slices.Contains(s.assignable, role) {
+			verr.add("roles", fmt.Sprintf("%q is not an assignable role", role))
+		}
+	}
+	return verr.orNil()
+}
+
+func (s *RoleService) push(ctx context.Context, userID uuid.UUID, add, remove []string) error {
+	if s.directory == nil {
+		return nil
+	}
+	if len(add) > 0 {
+		if err := s.directory.AddRoles(ctx, userID, add); err != nil {
+			return err
+		}
+	}
+	if len(remove) > 0 {
+		return s.directory.RemoveRoles(ctx, userID, remove)
+	}
+	return nil
+}
@@ -0,0 +1,123 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"userService/internal/events"
+	"userService/internal/model"
+)
+
+type MockRoleDirectory struct {
+	mock.Mock
+}
+
+func (m *MockRoleDirectory) AddRoles(ctx context.Context, userID uuid.UUID, roles []string) error {
+	args := m.Called(ctx, userID, roles)
+	return args.Error(0)
+}
+
+func (m *MockRoleDirectory) RemoveRoles(ctx context.Context, userID uuid.UUID, roles []string) error {
+	args := m.Called(ctx, userID, roles)
+	return args.Error(0)
+}
+
+var testAssignableRoles = []string{"user-admin", "moderator"}
+
+func TestRoleService_GrantRoles(t *testing.T) {
+	userID, adminID := uuid.New(), uuid.New()
+	ctx := WithOrigin(context.Background(), Origin{Source: SourceHTTP, Actor: &adminID, Admin: true})
+
+	t.Run("pushes new roles to the directory before storing them", func(t *testing.T) {
+		repo := new(MockUserRepository)
+		p := new(MockProducer)
+		cache := new(MockCacheService)
+		dir := new(MockRoleDirectory)
+
+		repo.On("GetUserById", mock.Anything, userID).Return(&model.User{ID: userID, Version: 2, Roles: []string{"moderator"}}, nil)
+		dir.On("AddRoles", mock.Anything, userID, []string{"user-admin"}).Return(nil)
+		repo.On("PatchUser", mock.Anything, userID, int64(2), UserPatch{
+			Fields: []string{FieldRoles}, Roles: []string{"moderator", "user-admin"},
+		}).Return(&model.User{ID: userID, Version: 3, Roles: []string{"moderator", "user-admin"}}, nil)
+		p.On("Produce", mock.Anything, events.UserRolesChanged, events.UserRolesChangedPayload{
+			UserID: userID, Roles: []string{"moderator", "user-admin"}, Granted: []string{"user-admin"}, ChangedBy: &adminID,
+		}).Return(nil)
+		cache.On("Delete", mock.Anything, fmt.Sprintf("user:%s", userID)).Return(nil)
+
+		svc := NewRoleService(repo, p, cache, dir, testAssignableRoles)
+		roles, err := svc.GrantRoles(ctx, userID, []string{"user-admin", "moderator"})
+
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"moderator", "user-admin"}, roles)
+		repo.AssertExpectations(t)
+		dir.AssertExpectations(t)
+		p.AssertExpectations(t)
+	})
+
+	t.Run("granting held roles changes nothing", func(t *testing.T) {
+		repo := new(MockUserRepository)
+		dir := new(MockRoleDirectory)
+		repo.On("GetUserById", mock.Anything, userID).Return(&model.User{ID: userID, Roles: []string{"moderator"}}, nil)
+
+		svc := NewRoleService(repo, nil, nil, dir, testAssignableRoles)
+		roles, err := svc.GrantRoles(ctx, userID, []string{"moderator"})
+
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"moderator"}, roles)
+		dir.AssertNotCalled(t, "AddRoles", mock.Anything, mock.Anything, mock.Anything)
+		repo.AssertNotCalled(t, "PatchUser", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("unknown roles are rejected", func(t *testing.T) {
+		repo := new(MockUserRepository)
+
+		svc := NewRoleService(repo, nil, nil, nil, testAssignableRoles)
+		_, err := svc.GrantRoles(ctx, userID, []string{"superuser"})
+
+		var verr *ValidationError
+		assert.True(t, errors.As(err, &verr))
+		assert.Equal(t, "roles", verr.Fields[0].Field)
+		repo.AssertNotCalled(t, "GetUserById", mock.Anything, mock.Anything)
+	})
+
+	t.Run("directory failures leave the profile unchanged", func(t *testing.T) {
+		repo := new(MockUserRepository)
+		dir := new(MockRoleDirectory)
+		repo.On("GetUserById", mock.Anything, userID).Return(&model.User{ID: userID}, nil)
+		dir.On("AddRoles", mock.Anything, userID, []string{"moderator"}).Return(errors.New("503 Service Unavailable"))
+
+		svc := NewRoleService(repo, nil, nil, dir, testAssignableRoles)
+		_, err := svc.GrantRoles(ctx, userID, []string{"moderator"})
+
+		assert.ErrorIs(t, err, ErrRoleSync)
+		repo.AssertNotCalled(t, "PatchUser", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func TestRoleService_RevokeRoles(t *testing.T) {
+	userID := uuid.New()
+
+	t.Run("a lost write is undone in the directory", func(t *testing.T) {
+		repo := new(MockUserRepository)
+		p := new(MockProducer)
+		dir := new(MockRoleDirectory)
+
+		repo.On("GetUserById", mock.Anything, userID).Return(&model.User{ID: userID, Version: 1, Roles: []string{"user-admin"}}, nil)
+		dir.On("RemoveRoles", mock.Anything, userID, []string{"user-admin"}).Return(nil)
+		repo.On("PatchUser", mock.Anything, userID, int64(1), UserPatch{Fields: []string{FieldRoles}, Roles: []string{}}).
+			Return(nil, ErrVersionConflict)
+		dir.On("AddRoles", mock.Anything, userID, []string{"user-admin"}).Return(nil)
+
+		svc := NewRoleService(repo, p, nil, dir, testAssignableRoles)
+		_, err := svc.RevokeRoles(context.Background(), userID, []string{"user-admin"})
+
+		assert.ErrorIs(t, err, ErrVersionConflict)
+		dir.AssertExpectations(t)
+		p.AssertNotCalled(t, "Produce", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
@@ -0,0 +1,114 @@
+package service
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	"userService/internal/transport/response"
+)
+
+// MaxSearchQueryLength bounds the text accepted by SearchUsers.
+const MaxSearchQueryLength = 100
+
+// SearchUsersParams is the caller-facing input of UserService.SearchUsers.
+type SearchUsersParams struct {
+	Query  string
+	Limit  int
+	Cursor string
+}
+
+// SearchQuery is the decoded form of SearchUsersParams handed to the repository.
+type SearchQuery struct {
+	Text   string
+	Limit  int
+	Offset int
+	// IncludeModerated also matches banned and suspended profiles
+	IncludeModerated bool
+	// ExcludeIDs are never matched; they hide blockers from the caller
+	ExcludeIDs []uuid.UUID
+}
+
+// searchCursor continues a ranked result list. Relevance scores are not stable
+// keys, so search pages are addressed by offset rather than by keyset.
+type searchCursor struct {
+	Text   string `json:"q"`
+	Offset int    `json:"o"`
+}
+
+func (c searchCursor) encode() string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodeSearchCursor(token string) (*searchCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	var c searchCursor
+	if err := json.Unmarshal(data, &c); err != nil || c.Offset <= 0 {
+		return nil, ErrInvalidCursor
+	}
+	return &c, nil
+}
+
+// SearchUsers returns one page of users matching text, best matches first.
+func (s *UserService) SearchUsers(ctx context.Context, p SearchUsersParams) (*response.UserPageResponse, error) {
+	q, err := buildSearchQuery(p)
+	if err != nil {
+		return nil, err
+	}
+
+	q.IncludeModerated = OriginFrom(ctx).Admin
+	if q.ExcludeIDs, err = s.hiddenByBlocks(ctx); err != nil {
+		return nil, err
+	}
+
+	// Fetch one extra document to learn whether another page exists
+	limit := q.Limit
+	q.Limit++
+	users, err := s.userRepo.SearchUsers(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &response.UserPageResponse{}
+	if len(users) > limit {
+		users = users[:limit]
+		page.NextCursor = searchCursor{Text: q.Text, Offset: q.Offset + limit}.encode()
+	}
+	page.Items = s.toResponses(ctx, users)
+
+	return page, nil
+}
+
+func buildSearchQuery(p SearchUsersParams) (SearchQuery, error) {
+	q := SearchQuery{Text: strings.TrimSpace(p.Query), Limit: p.Limit}
+
+	if q.Text == "" || utf8.RuneCountInString(q.Text) > MaxSearchQueryLength {
+		return q, ErrInvalidSearch
+	}
+
+	var err error
+	if q.Limit, err = normalizeLimit(q.Limit); err != nil {
+		return q, err
+	}
+
+	if p.Cursor != "" {
+		c, err := decodeSearchCursor(p.Cursor)
+		if err != nil {
+			return q, err
+		}
+		// Offsets are only meaningful for the query they were issued for
+		if c.Text != q.Text {
+			return q, ErrInvalidCursor
+		}
+		q.Offset = c.Offset
+	}
+
+	return q, nil
+}
@@ -19,19 +19,50 @@ import (
 )
 
 type UserRepository interface {
+	// CreateUser stores a new profile. A taken ID or email yields a *ConflictError.
 	CreateUser(ctx context.Context, user *model.User) error
 	UpdateUser(ctx context.Context, user *model.User) error
+	PatchUser(ctx context.Context, id uuid.UUID, version int64, patch UserPatch) (*model.User, error)
 	DeleteUserById(ctx context.Context, userId uuid.UUID) error
-	GetAllUsers(ctx context.Context) ([]model.User, error)
+	RestoreUser(ctx context.Context, id uuid.UUID, deletedSince time.Time) error
+	ListDeletedBefore(ctx context.Context, cutoff time.Time, limit int) ([]model.User, error)
+	PurgeUser(ctx context.Context, id uuid.UUID, cutoff time.Time) (bool, error)
+	ListUsers(ctx context.Context, q ListQuery) ([]model.User, error)
+	SearchUsers(ctx context.Context, q SearchQuery) ([]model.User, error)
 	GetUserById(ctx context.Context, id uuid.UUID) (*model.User, error)
+	// GetUserByEmail finds a user by normalized email, soft-deleted ones included.
+	GetUserByEmail(ctx context.Context, email string) (*model.User, error)
+	// AdjustFollowCounts adds delta to the following count of followerID and
+	// the followers count of followeeID without bumping versions.
+	AdjustFollowCounts(ctx context.Context, followerID, followeeID uuid.UUID, delta int64) error
+	// GetUserByHandle finds a user by current handle, soft-deleted ones included.
+	GetUserByHandle(ctx context.Context, handle string) (*model.User, error)
+	// GetUserByFormerHandle finds the live user that gave up handle and whose
+	// redirect is still in effect at now.
+	GetUserByFormerHandle(ctx context.Context, handle string, now time.Time) (*model.User, error)
+	// GetUsersByIds returns the live users among ids in no particular order.
+	GetUsersByIds(ctx context.Context, ids []uuid.UUID) ([]model.User, error)
+	// ListModerationExpiredBefore returns up to limit live users whose
+	// restriction expires before cutoff, soonest first.
+	ListModerationExpiredBefore(ctx context.Context, cutoff time.Time, limit int) ([]model.User, error)
 }
 
 type UserService struct {
-	cache       caching.CacheService
-	userRepo    UserRepository
-	fileStorage storage.FileStorage
-	producer    messaging.Producer
-	mapper      *mappers.UserMapper
+	cache        caching.CacheService
+	userRepo     UserRepository
+	fileStorage  storage.FileStorage
+	events       *publisher
+	mapper       *mappers.UserMapper
+	multiCache   MultiGetter
+	completion   CompletionPolicy
+	handles      HandlePolicy
+	reservations HandleRepository
+	follows      FollowRepository
+	blocks       BlockRepository
+	blockSet     BlockSet
+
+	revisions      RevisionRepository
+	revisionMapper *mappers.RevisionMapper
 }
 
 func NewUserService(
@@ -43,52 +74,109 @@ func NewUserService(
 	return &UserService{
 		userRepo:    userRepo,
 		fileStorage: fileStorage,
-		producer:    producer,
+		events:      &publisher{producer: producer},
 		mapper:      mappers.NewUserMapper(),
 		cache:       cache,
+		completion:  DefaultCompletionPolicy(),
+		handles:     DefaultHandlePolicy(),
+
+		revisionMapper: mappers.NewRevisionMapper(),
 	}
 }
 
-func (s *UserService) UpdateUser(ctx context.Context, ur request.UserRequest, userID uuid.UUID) error {
+// WithOutbox commits events in the same transaction as the writes that cause
+// them; OutboxRelay publishes them afterwards.
+func (s *UserService) WithOutbox(tx Transactor, outbox OutboxRepository) *UserService {
+	s.events.tx, s.events.outbox = tx, outbox
+	return s
+}
+
+// AnyVersion disables the version precondition of UpdateUser (If-Match: *).
+const AnyVersion int64 = -1
+
+// UpdateUser replaces the profile of userID with ur. expectedVersion must equal
+// the stored version unless it is AnyVersion.
+func (s *UserService) UpdateUser(ctx context.Context, ur request.UserRequest, userID uuid.UUID, expectedVersion int64) error {
 	u, err := s.userRepo.GetUserById(ctx, userID)
 	if err != nil {
 		return err
 	}
 	if u == nil {
-		return fmt.Errorf("user not found: %s", userID)
+		return fmt.Errorf("%w: %s", ErrUserNotFound, userID)
+	}
+	if u.DeletedAt != nil {
+		return ErrUserDeleted
+	}
+	if u.Moderation != nil && !OriginFrom(ctx).Admin {
+		return ErrAccountRestricted
+	}
+	if expectedVersion != AnyVersion && u.Version != expectedVersion {
+		return ErrPreconditionFailed
 	}
 
+	before := *u
 	oldURL := u.AvatarURL
 
-	// Avatar update
-	if ur.Avatar != nil && ur.Header != nil {
-		if u.AvatarURL, err = s.fileStorage.UploadFile(ctx, ur.Avatar, ur.Header); err != nil {
-			return err
-		}
-	}
-
 	// Mandatory fields
 	u.Lastname = ur.Lastname
 	u.Firstname = ur.Firstname
 
 	// Optional fields
+	verr := &ValidationError{}
 	u.About = ur.About
 	if ur.DateOfBirth == "" {
 		u.DateOfBirth = nil
+	} else if dob, err := date.ParseDate(ur.DateOfBirth); err != nil {
+		verr.add(FieldDateOfBirth, "has the wrong type or format")
 	} else {
-		dob, err := date.ParseDate(ur.DateOfBirth)
-		if err != nil {
-			return err
-		}
 		u.DateOfBirth = &dob
 	}
 	u.Gender = ur.Gender
 	u.Location = ur.Location
 	u.Socials = ur.Socials
 
-	// Persist update
-	if err = s.userRepo.UpdateUser(ctx, u); err != nil {
+	// Validate before uploading so a rejected update leaves no orphaned avatar
+	validateProfile(verr, u)
+	if err := verr.orNil(); err != nil {
+		return err
+	}
+
+	// Avatar update
+	if ur.Avatar != nil && ur.Header != nil {
+		if u.AvatarURL, err = s.fileStorage.UploadFile(ctx, ur.Avatar, ur.Header); err != nil {
+			return err
+		}
+	}
+	completed := s.completion.track(&before, u, time.Now())
+
+	// Persist update together with its events
+	err = s.events.atomically(ctx, func(ctx context.Context) error {
+		if err := s.userRepo.UpdateUser(ctx, u); err != nil {
+			return err
+		}
+		err := s.events.publish(ctx, events.UserUpdated, events.UserUpdatedPayload{
+			UserID:    userID,
+			OldURL:    oldURL,
+			AvatarURL: u.AvatarURL,
+		})
+		if err != nil {
+			return err
+		}
+		if completed {
+			if err := s.publishCompleted(ctx, u); err != nil {
+				return err
+			}
+		}
+		return recordRevision(ctx, s.revisions, ActionUpdate, &before, u)
+	})
+	if err != nil {
 		logging.Instance.Errorf("failed to update user %s: %v", userID, err)
+		// The profile still points at the old avatar
+		if u.AvatarURL != oldURL {
+			if delErr := s.fileStorage.DeleteFileByURL(context.WithoutCancel(ctx), u.AvatarURL); delErr != nil {
+				logging.Instance.Warnf("failed to delete unused avatar %s: %v", u.AvatarURL, delErr)
+			}
+		}
 		return err
 	}
 
@@ -98,20 +186,24 @@ func (s *UserService) UpdateUser(ctx context.Context, ur request.UserRequest, us
 		logging.Instance.Warnf("failed to invalidate cache for user %s: %v", userID, err)
 	}
 
-	// Publish event (non-blocking for DB update)
-	if err = s.producer.Produce(ctx, events.UserUpdated, events.UserUpdatedPayload{
-		UserID:    userID,
-		OldURL:    oldURL,
-		AvatarURL: u.AvatarURL,
-	}); err != nil {
-		logging.Instance.Errorf("failed to publish UserUpdated event for user %s: %v", userID, err)
-	}
-
 	return nil
 }
 
 func (s *UserService) DeleteUserById(ctx context.Context, userId uuid.UUID) error {
-	if err := s.userRepo.DeleteUserById(ctx, userId); err != nil {
+	// Persist delete together with its event
+	err := s.events.atomically(ctx, func(ctx context.Context) error {
+		if err := s.userRepo.DeleteUserById(ctx, userId); err != nil {
+			return err
+		}
+		err := s.events.publish(ctx, events.UserDeleted, events.UserDeletedPayload{
+			UserID: userId,
+		})
+		if err != nil {
+			return err
+		}
+		return s.recordDeletion(ctx, userId)
+	})
+	if err != nil {
 		return err
 	}
 
@@ -121,26 +213,46 @@ func (s *UserService) DeleteUserById(ctx context.Context, userId uuid.UUID) erro
 		logging.Instance.Warnf("failed to invalidate cache for deleted user %s: %v", userId, err)
 	}
 
-	// Publish event (non-blocking for DB delete)
-	if err := s.producer.Produce(ctx, events.UserDeleted, events.UserDeletedPayload{
-		UserID: userId,
-	}); err != nil {
-		logging.Instance.Errorf("failed to publish UserDeleted event for user %s: %v", userId, err)
-	}
-
 	return nil
 }
 
+// recordDeletion records the soft delete of userID, read back for its deletion time.
+func (s *UserService) recordDeletion(ctx context.Context, userID uuid.UUID) error {
+	if s.revisions == nil {
+		return nil
+	}
+	deleted, err := s.userRepo.GetUserById(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if deleted == nil {
+		return fmt.Errorf("%w: %s", ErrUserNotFound, userID)
+	}
+	before := *deleted
+	before.DeletedAt = nil
+	return recordRevision(ctx, s.revisions, ActionDelete, &before, deleted)
+}
+
+// GetUserById returns a live profile. Soft-deleted profiles yield ErrUserDeleted;
+// banned and suspended ones are not found unless the caller may see them.
 func (s *UserService) GetUserById(ctx context.Context, id uuid.UUID) (*response.UserResponse, error) {
 	cacheKey := fmt.Sprintf("user:%s", id.String())
 
 	// 1. Try cache first
 	if cached, err := s.cache.Get(ctx, cacheKey); err == nil && cached != "" {
 		var ur response.UserResponse
-		if err := json.Unmarshal([]byte(cached), &ur); err == nil {
+		if err := json.Unmarshal([]byte(cached), &ur); err != nil {
+			logging.Instance.Warnf("failed to unmarshal cached user %s: %v", id, err)
+		} else if ur.DeletedAt == nil {
+			if !canSee(ctx, ur.ID, ur.Moderation != nil) {
+				return nil, ErrUserNotFound
+			}
+			if err := s.requireUnblocked(ctx, ur.ID); err != nil {
+				return nil, err
+			}
+			ur = present(ctx, ur)
 			return &ur, nil
 		}
-		logging.Instance.Warnf("failed to unmarshal cached user %s: %v", id, err)
 	} else if err != nil {
 		logging.Instance.Warnf("cache get failed for user %s: %v", id, err)
 	}
@@ -151,10 +263,17 @@ func (s *UserService) GetUserById(ctx context.Context, id uuid.UUID) (*response.
 		return nil, err
 	}
 	if user == nil {
-		return nil, fmt.Errorf("user not found")
+		return nil, ErrUserNotFound
+	}
+	if user.DeletedAt != nil {
+		// Drop whatever is cached so the deleted profile is never served from there
+		if err := s.cache.Delete(ctx, cacheKey); err != nil {
+			logging.Instance.Warnf("failed to invalidate cache for deleted user %s: %v", id, err)
+		}
+		return nil, ErrUserDeleted
 	}
 
-	ur := s.mapper.Map(*user)
+	ur := s.toResponse(*user)
 
 	// 3. Store in cache with TTL
 	if data, err := json.Marshal(ur); err == nil {
@@ -165,14 +284,85 @@ func (s *UserService) GetUserById(ctx context.Context, id uuid.UUID) (*response.
 		logging.Instance.Warnf("failed to marshal user %s for cache: %v", id, err)
 	}
 
-	// 4. Return the user
+	// 4. Return the view the caller may see; the cache keeps the full one
+	if !canSee(ctx, ur.ID, ur.Moderation != nil) {
+		return nil, ErrUserNotFound
+	}
+	if err := s.requireUnblocked(ctx, ur.ID); err != nil {
+		return nil, err
+	}
+	ur = present(ctx, ur)
 	return &ur, nil
 }
 
-func (s *UserService) GetAllUsers(ctx context.Context) ([]response.UserResponse, error) {
-	users, err := s.userRepo.GetAllUsers(ctx)
+// GetUserByIdIncludingDeleted returns a profile whether or not it was soft-deleted.
+// It is meant for administrators and always reads from the database.
+func (s *UserService) GetUserByIdIncludingDeleted(ctx context.Context, id uuid.UUID) (*response.UserResponse, error) {
+	user, err := s.userRepo.GetUserById(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, ErrUserNotFound
+	}
+
+	ur := present(ctx, s.toResponse(*user))
+	return &ur, nil
+}
+
+// GetOwnProfile returns the private view of the caller's own profile. It
+// bypasses the cache so owners always see their latest write.
+func (s *UserService) GetOwnProfile(ctx context.Context, id uuid.UUID) (*response.UserResponse, error) {
+	user, err := s.userRepo.GetUserById(ctx, id)
 	if err != nil {
 		return nil, err
 	}
-	return s.mapper.MapEach(users), nil
+	if user == nil {
+		return nil, ErrUserNotFound
+	}
+	if user.DeletedAt != nil {
+		return nil, ErrUserDeleted
+	}
+
+	// The owner always sees the full view
+	ur := s.toResponse(*user)
+	return &ur, nil
+}
+
+// ListUsers returns one page of users ordered by the requested sort key.
+func (s *UserService) ListUsers(ctx context.Context, p ListUsersParams) (*response.UserPageResponse, error) {
+	q, err := buildListQuery(p)
+	if err != nil {
+		return nil, err
+	}
+
+	if !OriginFrom(ctx).Admin {
+		visible := false
+		q.Filter.Moderated = &visible
+	}
+	if q.Filter.ExcludeIDs, err = s.hiddenByBlocks(ctx); err != nil {
+		return nil, err
+	}
+
+	// Fetch one extra document to learn whether another page exists
+	limit := q.Limit
+	q.Limit++
+	users, err := s.userRepo.ListUsers(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &response.UserPageResponse{}
+	if len(users) > limit {
+		users = users[:limit]
+		last := users[limit-1]
+		c := Cursor{Sort: sortKey(q.SortField, q.Desc), Value: last.CreatedAt, ID: last.ID}
+		if q.SortField == SortUpdatedAt {
+			c.Value = last.UpdatedAt
+		}
+		page.NextCursor = c.Encode()
+	}
+	page.Items = s.toResponses(ctx, users)
+
+	return page, nil
 }
@@ -69,13 +69,26 @@ func (m *MockUserRepository) UpdateUser(ctx context.Context, user *model.User) e
 	return args.Error(0)
 }
 
+func (m *MockUserRepository) PatchUser(ctx context.Context, id uuid.UUID, version int64, patch UserPatch) (*model.User, error) {
+	args := m.Called(ctx, id, version, patch)
+	if u, ok := args.Get(0).(*model.User); ok {
+		return u, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
 func (m *MockUserRepository) DeleteUserById(ctx context.Context, userId uuid.UUID) error {
 	args := m.Called(ctx, userId)
 	return args.Error(0)
 }
 
-func (m *MockUserRepository) GetAllUsers(ctx context.Context) ([]model.User, error) {
-	args := m.Called(ctx)
+func (m *MockUserRepository) ListUsers(ctx context.Context, q ListQuery) ([]model.User, error) {
+	args := m.Called(ctx, q)
+	return args.Get(0).([]model.User), args.Error(1)
+}
+
+func (m *MockUserRepository) SearchUsers(ctx context.Context, q SearchQuery) ([]model.User, error) {
+	args := m.Called(ctx, q)
 	return args.Get(0).([]model.User), args.Error(1)
 }
 
@@ -84,6 +97,60 @@ func (m *MockUserRepository) GetUserById(ctx context.Context, id uuid.UUID) (*mo
 	return args.Get(0).(*model.User), args.Error(1)
 }
 
+func (m *MockUserRepository) GetUserByEmail(ctx context.Context, email string) (*model.User, error) {
+	args := m.Called(ctx, email)
+	if u, ok := args.Get(0).(*model.User); ok {
+		return u, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockUserRepository) AdjustFollowCounts(ctx context.Context, followerID, followeeID uuid.UUID, delta int64) error {
+	args := m.Called(ctx, followerID, followeeID, delta)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) GetUserByHandle(ctx context.Context, handle string) (*model.User, error) {
+	args := m.Called(ctx, handle)
+	if u, ok := args.Get(0).(*model.User); ok {
+		return u, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockUserRepository) GetUserByFormerHandle(ctx context.Context, handle string, now time.Time) (*model.User, error) {
+	args := m.Called(ctx, handle, now)
+	if u, ok := args.Get(0).(*model.User); ok {
+		return u, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockUserRepository) GetUsersByIds(ctx context.Context, ids []uuid.UUID) ([]model.User, error) {
+	args := m.Called(ctx, ids)
+	return args.Get(0).([]model.User), args.Error(1)
+}
+
+func (m *MockUserRepository) RestoreUser(ctx context.Context, id uuid.UUID, deletedSince time.Time) error {
+	args := m.Called(ctx, id, deletedSince)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) ListDeletedBefore(ctx context.Context, cutoff time.Time, limit int) ([]model.User, error) {
+	args := m.Called(ctx, cutoff, limit)
+	return args.Get(0).([]model.User), args.Error(1)
+}
+
+func (m *MockUserRepository) ListModerationExpiredBefore(ctx context.Context, cutoff time.Time, limit int) ([]model.User, error) {
+	args := m.Called(ctx, cutoff, limit)
+	return args.Get(0).([]model.User), args.Error(1)
+}
+
+func (m *MockUserRepository) PurgeUser(ctx context.Context, id uuid.UUID, cutoff time.Time) (bool, error) {
+	args := m.Called(ctx, id, cutoff)
+	return args.Bool(0), args.Error(1)
+}
+
 type MockFileService struct {
 	mock.Mock
 }
@@ -126,6 +193,7 @@ func TestUserService_UpdateUser(t *testing.T) {
 		setupMocks    func(*MockUserRepository, *MockFileService, *MockProducer, *MockCacheService)
 		req           request.UserRequest
 		userID        uuid.UUID
+		version       int64
 		expectedError string
 	}{
 		{
@@ -158,12 +226,79 @@ func TestUserService_UpdateUser(t *testing.T) {
 				// No cache delete expected in this case
 			},
 			req: request.UserRequest{
-				Avatar: avatarFile,
-				Header: avatarHeader,
+				Avatar:    avatarFile,
+				Header:    avatarHeader,
+				Firstname: "newfirstname",
+				Lastname:  "newlastname",
 			},
 			userID:        userUUID,
 			expectedError: "upload error",
 		},
+		{
+			name: "a failed write removes the uploaded avatar",
+			setupMocks: func(repo *MockUserRepository, fs *MockFileService, p *MockProducer, cache *MockCacheService) {
+				repo.On("GetUserById", mock.Anything, userUUID).Return(&model.User{AvatarURL: "old.jpg", Version: 3}, nil)
+				fs.On("UploadFile", mock.Anything, mock.Anything, mock.Anything).Return("new.jpg", nil)
+				repo.On("UpdateUser", mock.Anything, mock.Anything).Return(ErrVersionConflict)
+				fs.On("DeleteFileByURL", mock.Anything, "new.jpg").Return(nil)
+			},
+			req: request.UserRequest{
+				Avatar:    avatarFile,
+				Header:    avatarHeader,
+				Firstname: "newfirstname",
+				Lastname:  "newlastname",
+			},
+			userID:        userUUID,
+			version:       3,
+			expectedError: ErrVersionConflict.Error(),
+		},
+		{
+			name: "invalid profile is rejected before uploading",
+			setupMocks: func(repo *MockUserRepository, fs *MockFileService, p *MockProducer, cache *MockCacheService) {
+				repo.On("GetUserById", mock.Anything, userUUID).Return(&model.User{}, nil)
+			},
+			req: request.UserRequest{
+				Avatar:      avatarFile,
+				Header:      avatarHeader,
+				Firstname:   "A",
+				Lastname:    "newlastname",
+				DateOfBirth: "2004-01-02",
+				Gender:      "robot",
+			},
+			userID:        userUUID,
+			expectedError: "validation failed: date_of_birth: has the wrong type or format; firstname: is required and must be 2 to 20 characters; gender: must be one of male, female, other",
+		},
+		{
+			name: "stale version is rejected before any write",
+			setupMocks: func(repo *MockUserRepository, fs *MockFileService, p *MockProducer, cache *MockCacheService) {
+				repo.On("GetUserById", mock.Anything, userUUID).Return(&model.User{Version: 3}, nil)
+			},
+			req: request.UserRequest{
+				Firstname: "newfirstname",
+				Lastname:  "newlastname",
+			},
+			userID:        userUUID,
+			version:       2,
+			expectedError: ErrPreconditionFailed.Error(),
+		},
+		{
+			name: "any version skips the precondition",
+			setupMocks: func(repo *MockUserRepository, fs *MockFileService, p *MockProducer, cache *MockCacheService) {
+				repo.On("GetUserById", mock.Anything, userUUID).Return(&model.User{Version: 3}, nil)
+				repo.On("UpdateUser", mock.Anything, mock.MatchedBy(func(u *model.User) bool {
+					return u.Version == 3
+				})).Return(nil)
+				p.On("Produce", mock.Anything, events.UserUpdated, mock.Anything).Return(nil)
+				cache.On("Delete", mock.Anything, fmt.Sprintf("user:%s", userUUID.String())).Return(nil)
+			},
+			req: request.UserRequest{
+				Firstname: "newfirstname",
+				Lastname:  "newlastname",
+			},
+			userID:        userUUID,
+			version:       AnyVersion,
+			expectedError: "",
+		},
 	}
 
 	for _, tt := range tests {
@@ -176,7 +311,7 @@ func TestUserService_UpdateUser(t *testing.T) {
 			tt.setupMocks(repo, fs, p, cache)
 
 			svc := NewUserService(repo, fs, p, cache)
-			err = svc.UpdateUser(context.Background(), tt.req, tt.userID)
+			err = svc.UpdateUser(context.Background(), tt.req, tt.userID, tt.version)
 
 			if tt.expectedError == "" {
 				assert.NoError(t, err)
@@ -231,31 +366,192 @@ func TestUserService_GetUserById(t *testing.T) {
 	assert.Equal(t, "testuser", resp.Firstname)
 }
 
-func TestUserService_GetAllUsers(t *testing.T) {
-	userUUID1 := uuid.New()
-	userUUID2 := uuid.New()
-	repo := new(MockUserRepository)
+func TestUserService_GetUserById_Deleted(t *testing.T) {
+	userUUID := uuid.New()
+	deletedAt := time.Now().UTC()
+	cacheKey := fmt.Sprintf("user:%s", userUUID)
+
+	t.Run("stale cache entry of a deleted user is not served", func(t *testing.T) {
+		cache := new(MockCacheService)
+		repo := new(MockUserRepository)
+
+		cache.On("Get", mock.Anything, cacheKey).Return(fmt.Sprintf(`{"id":%q,"deleted_at":%q}`, userUUID, deletedAt.Format(time.RFC3339)), nil)
+		cache.On("Delete", mock.Anything, cacheKey).Return(nil)
+		repo.On("GetUserById", mock.Anything, userUUID).Return(&model.User{ID: userUUID, DeletedAt: &deletedAt}, nil)
+
+		svc := NewUserService(repo, nil, nil, cache)
+		_, err := svc.GetUserById(context.Background(), userUUID)
+
+		assert.ErrorIs(t, err, ErrUserDeleted)
+		cache.AssertNotCalled(t, "Set", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+		cache.AssertExpectations(t)
+	})
+
+	t.Run("admins can still read a deleted user", func(t *testing.T) {
+		repo := new(MockUserRepository)
+		repo.On("GetUserById", mock.Anything, userUUID).Return(&model.User{ID: userUUID, DeletedAt: &deletedAt}, nil)
+
+		svc := NewUserService(repo, nil, nil, nil)
+		resp, err := svc.GetUserByIdIncludingDeleted(context.Background(), userUUID)
+
+		assert.NoError(t, err)
+		assert.NotNil(t, resp.DeletedAt)
+	})
+}
+
+func TestUserService_GetOwnProfile(t *testing.T) {
+	userUUID := uuid.New()
+
+	t.Run("reads the private view from the database", func(t *testing.T) {
+		repo := new(MockUserRepository)
+		cache := new(MockCacheService)
+		repo.On("GetUserById", mock.Anything, userUUID).
+			Return(&model.User{ID: userUUID, Email: "me@example.com", NeedsCompletion: true}, nil)
+
+		svc := NewUserService(repo, nil, nil, cache)
+		resp, err := svc.GetOwnProfile(context.Background(), userUUID)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "me@example.com", resp.Email)
+		assert.Contains(t, resp.MissingFields, FieldFirstname)
+		cache.AssertNotCalled(t, "Get", mock.Anything, mock.Anything)
+	})
+
+	t.Run("deleted profiles are gone", func(t *testing.T) {
+		deletedAt := time.Now().UTC()
+		repo := new(MockUserRepository)
+		repo.On("GetUserById", mock.Anything, userUUID).Return(&model.User{ID: userUUID, DeletedAt: &deletedAt}, nil)
+
+		svc := NewUserService(repo, nil, nil, nil)
+		_, err := svc.GetOwnProfile(context.Background(), userUUID)
+
+		assert.ErrorIs(t, err, ErrUserDeleted)
+	})
+}
+
+func TestUserService_ListUsers(t *testing.T) {
+	now := time.Now().UTC()
 	users := []model.User{
-		{
-			ID:        userUUID1,
-			Firstname: "user1",
-		},
-		{
-			ID:        userUUID2,
-			Firstname: "user2",
-		},
+		{ID: uuid.New(), Firstname: "user1", CreatedAt: now},
+		{ID: uuid.New(), Firstname: "user2", CreatedAt: now.Add(-time.Minute)},
+		{ID: uuid.New(), Firstname: "user3", CreatedAt: now.Add(-2 * time.Minute)},
 	}
-	repo.On("GetAllUsers", mock.Anything).Return(users, nil)
 
-	svc := NewUserService(repo, nil, nil, nil)
-	resp, err := svc.GetAllUsers(context.Background())
+	t.Run("first page returns next cursor", func(t *testing.T) {
+		repo := new(MockUserRepository)
+		// Anonymous callers never list banned or suspended profiles
+		unmoderated := false
+		repo.On("ListUsers", mock.Anything, ListQuery{
+			Limit: 3, SortField: SortCreatedAt, Desc: true, Filter: UserFilter{Moderated: &unmoderated},
+		}).Return(users, nil)
 
-	assert.NoError(t, err)
-	assert.Len(t, resp, 2)
-	assert.Equal(t, userUUID1, resp[0].ID)
-	assert.Equal(t, "user1", resp[0].Firstname)
-	assert.Equal(t, userUUID2, resp[1].ID)
-	assert.Equal(t, "user2", resp[1].Firstname)
+		svc := NewUserService(repo, nil, nil, nil)
+		page, err := svc.ListUsers(context.Background(), ListUsersParams{Limit: 2})
+
+		assert.NoError(t, err)
+		assert.Len(t, page.Items, 2)
+		assert.NotEmpty(t, page.NextCursor)
+
+		c, err := DecodeCursor(page.NextCursor)
+		assert.NoError(t, err)
+		assert.Equal(t, users[1].ID, c.ID)
+		assert.Equal(t, "-created_at", c.Sort)
+	})
+
+	t.Run("last page has no cursor", func(t *testing.T) {
+		repo := new(MockUserRepository)
+		repo.On("ListUsers", mock.Anything, mock.Anything).Return(users[2:], nil)
+
+		svc := NewUserService(repo, nil, nil, nil)
+		page, err := svc.ListUsers(context.Background(), ListUsersParams{Limit: 2})
+
+		assert.NoError(t, err)
+		assert.Len(t, page.Items, 1)
+		assert.Empty(t, page.NextCursor)
+	})
+
+	t.Run("cursor issued for another sort is rejected", func(t *testing.T) {
+		token := Cursor{Sort: "updated_at", Value: now, ID: users[0].ID}.Encode()
+
+		svc := NewUserService(new(MockUserRepository), nil, nil, nil)
+		_, err := svc.ListUsers(context.Background(), ListUsersParams{Cursor: token})
+
+		assert.ErrorIs(t, err, ErrInvalidCursor)
+	})
+
+	t.Run("filter is passed to repository", func(t *testing.T) {
+		needsCompletion := true
+		filter := UserFilter{Gender: "female", NeedsCompletion: &needsCompletion}
+
+		repo := new(MockUserRepository)
+		repo.On("ListUsers", mock.Anything, mock.MatchedBy(func(q ListQuery) bool {
+			return q.Filter.Gender == "female" && *q.Filter.NeedsCompletion
+		})).Return([]model.User{}, nil)
+
+		svc := NewUserService(repo, nil, nil, nil)
+		_, err := svc.ListUsers(context.Background(), ListUsersParams{Filter: filter})
+
+		assert.NoError(t, err)
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("inverted date range is rejected", func(t *testing.T) {
+		from, to := now, now.Add(-time.Hour)
+
+		svc := NewUserService(new(MockUserRepository), nil, nil, nil)
+		_, err := svc.ListUsers(context.Background(), ListUsersParams{
+			Filter: UserFilter{CreatedFrom: &from, CreatedTo: &to},
+		})
+
+		assert.ErrorIs(t, err, ErrInvalidFilter)
+	})
+
+	t.Run("unknown sort key is rejected", func(t *testing.T) {
+		svc := NewUserService(new(MockUserRepository), nil, nil, nil)
+		_, err := svc.ListUsers(context.Background(), ListUsersParams{Sort: "email"})
+
+		assert.ErrorIs(t, err, ErrInvalidSort)
+	})
+}
+
+func TestUserService_SearchUsers(t *testing.T) {
+	users := []model.User{
+		{ID: uuid.New(), Firstname: "Sayan"},
+		{ID: uuid.New(), Firstname: "Sayat"},
+	}
+
+	t.Run("pages are addressed by offset", func(t *testing.T) {
+		repo := new(MockUserRepository)
+		repo.On("SearchUsers", mock.Anything, SearchQuery{Text: "say", Limit: 2}).Return(users, nil)
+		repo.On("SearchUsers", mock.Anything, SearchQuery{Text: "say", Limit: 2, Offset: 1}).Return(users[1:], nil)
+
+		svc := NewUserService(repo, nil, nil, nil)
+		first, err := svc.SearchUsers(context.Background(), SearchUsersParams{Query: " say ", Limit: 1})
+		assert.NoError(t, err)
+		assert.Len(t, first.Items, 1)
+		assert.NotEmpty(t, first.NextCursor)
+
+		second, err := svc.SearchUsers(context.Background(), SearchUsersParams{Query: "say", Limit: 1, Cursor: first.NextCursor})
+		assert.NoError(t, err)
+		assert.Equal(t, users[1].ID, second.Items[0].ID)
+		assert.Empty(t, second.NextCursor)
+	})
+
+	t.Run("empty query is rejected", func(t *testing.T) {
+		svc := NewUserService(new(MockUserRepository), nil, nil, nil)
+		_, err := svc.SearchUsers(context.Background(), SearchUsersParams{Query: "   "})
+
+		assert.ErrorIs(t, err, ErrInvalidSearch)
+	})
+
+	t.Run("cursor from another query is rejected", func(t *testing.T) {
+		token := searchCursor{Text: "other", Offset: 20}.encode()
+
+		svc := NewUserService(new(MockUserRepository), nil, nil, nil)
+		_, err := svc.SearchUsers(context.Background(), SearchUsersParams{Query: "say", Cursor: token})
+
+		assert.ErrorIs(t, err, ErrInvalidCursor)
+	})
 }
 
 type mockMultipartFile struct {
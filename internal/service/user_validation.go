@@ -0,0 +1,125 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"net/mail"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"userService/internal/model"
+)
+
+// Profile limits shared by every write path: HTTP, gRPC and Kafka events.
+const (
+	MinNameLength     = 2
+	MaxNameLength     = 20
+	MaxAboutLength    = 500
+	MaxLocationLength = 100
+)
+
+// Genders lists the accepted values of the gender field.
+var Genders = []string{"male", "female", "other"}
+
+// ValidateUser checks a whole profile before it is stored. Names may only be
+// missing while the profile still needs completion. The email may be missing
+// when the identity provider sent none or an invalid one.
+func ValidateUser(u *model.User) error {
+	verr := &ValidationError{}
+	validateUser(verr, u)
+	return verr.orNil()
+}
+
+func validateUser(verr *ValidationError, u *model.User) {
+	if u.Email != "" && !validEmail(u.Email) {
+		verr.add("email", "must be a valid email address")
+	}
+	validateProfile(verr, u)
+	if u.AvatarURL != "" && !validURL(u.AvatarURL) {
+		verr.add("avatar_url", "must be a valid URL")
+	}
+}
+
+// clearInvalid empties the email and names of u that fail validation, the
+// fields a registration event supplies, and returns what was wrong with them.
+// u must still need completion for the emptied names to be valid.
+func clearInvalid(u *model.User) *ValidationError {
+	var verr *ValidationError
+	if !errors.As(ValidateUser(u), &verr) {
+		return nil
+	}
+	for _, f := range verr.Fields {
+		switch f.Field {
+		case "email":
+			u.Email = ""
+		case FieldFirstname:
+			u.Firstname = ""
+		case FieldLastname:
+			u.Lastname = ""
+		}
+	}
+	return verr
+}
+
+// validateProfile checks the fields owners edit themselves.
+func validateProfile(verr *ValidationError, u *model.User) {
+	if u.Firstname != "" || !u.NeedsCompletion {
+		validateField(verr, FieldFirstname, u.Firstname)
+	}
+	if u.Lastname != "" || !u.NeedsCompletion {
+		validateField(verr, FieldLastname, u.Lastname)
+	}
+	validateField(verr, FieldAbout, u.About)
+	validateField(verr, FieldDateOfBirth, u.DateOfBirth)
+	validateField(verr, FieldGender, u.Gender)
+	validateField(verr, FieldLocation, u.Location)
+	validateField(verr, FieldSocials, u.Socials)
+}
+
+// validateField checks the new value of a patchable field, typed as
+// UserPatch.Value returns it.
+func validateField(verr *ValidationError, field string, value any) {
+	switch field {
+	case FieldFirstname, FieldLastname:
+		if n := utf8.RuneCountInString(value.(string)); n < MinNameLength || n > MaxNameLength {
+			verr.add(field, fmt.Sprintf("is required and must be %d to %d characters", MinNameLength, MaxNameLength))
+		}
+	case FieldAbout:
+		if utf8.RuneCountInString(value.(string)) > MaxAboutLength {
+			verr.add(field, fmt.Sprintf("must be at most %d characters", MaxAboutLength))
+		}
+	case FieldDateOfBirth:
+		if dob := value.(*time.Time); dob != nil && dob.After(time.Now()) {
+			verr.add(field, "must not be in the future")
+		}
+	case FieldGender:
+		if gender := value.(string); gender != "" && !slices.Contains(Genders, gender) {
+			verr.add(field, "must be one of "+strings.Join(Genders, ", "))
+		}
+	case FieldLocation:
+		if utf8.RuneCountInString(value.(string)) > MaxLocationLength {
+			verr.add(field, fmt.Sprintf("must be at most %d characters", MaxLocationLength))
+		}
+	case FieldSocials:
+		for i, link := range value.([]string) {
+			if !validURL(link) {
+				verr.add(fmt.Sprintf("%s[%d]", field, i), "must be a valid URL")
+			}
+		}
+	default:
+		verr.add(field, "unknown or read-only field")
+	}
+}
+
+func validEmail(email string) bool {
+	_, err := mail.ParseAddress(email)
+	return err == nil && !strings.ContainsAny(email, "<> ")
+}
+
+func validURL(raw string) bool {
+	u, err := url.ParseRequestURI(raw)
+	return err == nil && u.Host != ""
+}
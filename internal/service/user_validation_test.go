@@ -0,0 +1,134 @@
+package service
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"userService/internal/events"
+	"userService/internal/model"
+)
+
+func TestValidateUser(t *testing.T) {
+	future := time.Now().Add(24 * time.Hour)
+	valid := func() *model.User {
+		return &model.User{
+			Email:     "ada@example.com",
+			Firstname: "Ada",
+			Lastname:  "Lovelace",
+			Gender:    "female",
+			Socials:   []string{"https://github.com/ada"},
+		}
+	}
+
+	tests := []struct {
+		name       string
+		modify     func(u *model.User)
+		wantFields []string
+	}{
+		{name: "valid profile", modify: func(u *model.User) {}},
+		{name: "two letter names are allowed", modify: func(u *model.User) { u.Firstname, u.Lastname = "Al", "Li" }},
+		{
+			name:       "names are required on complete profiles",
+			modify:     func(u *model.User) { u.Firstname, u.Lastname = "", "A" },
+			wantFields: []string{FieldFirstname, FieldLastname},
+		},
+		{
+			name:   "names may be missing while the profile needs completion",
+			modify: func(u *model.User) { u.Firstname, u.Lastname, u.NeedsCompletion = "", "", true },
+		},
+		{
+			name:       "present names are checked even when incomplete",
+			modify:     func(u *model.User) { u.Firstname, u.NeedsCompletion = strings.Repeat("a", 21), true },
+			wantFields: []string{FieldFirstname},
+		},
+		{
+			name: "every field is reported",
+			modify: func(u *model.User) {
+				u.Email = "not-an-email"
+				u.About = strings.Repeat("a", 501)
+				u.DateOfBirth = &future
+				u.Gender = "robot"
+				u.Location = strings.Repeat("a", 101)
+				u.Socials = []string{"https://ok.example", "nope"}
+				u.AvatarURL = "avatar.png"
+			},
+			wantFields: []string{"email", FieldAbout, FieldDateOfBirth, FieldGender, FieldLocation, "socials[1]", "avatar_url"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			u := valid()
+			tt.modify(u)
+			err := ValidateUser(u)
+			if len(tt.wantFields) == 0 {
+				assert.NoError(t, err)
+				return
+			}
+
+			var verr *ValidationError
+			assert.ErrorIs(t, err, ErrValidation)
+			if assert.ErrorAs(t, err, &verr) {
+				fields := make([]string, 0, len(verr.Fields))
+				for _, f := range verr.Fields {
+					fields = append(fields, f.Field)
+				}
+				assert.Equal(t, tt.wantFields, fields)
+			}
+		})
+	}
+}
+
+func TestCreateUserHandler_Validation(t *testing.T) {
+	t.Run("missing names mark the profile incomplete", func(t *testing.T) {
+		repo := new(MockUserRepository)
+		repo.On("CreateUser", mock.Anything, mock.MatchedBy(func(u *model.User) bool {
+			return u.Firstname == "" && u.Lastname == "Doe" && u.NeedsCompletion
+		})).Return(nil)
+
+		data, _ := json.Marshal(events.UserCreatedPayload{UserID: uuid.New(), Firstname: "null", Lastname: "Doe", Email: "jane@example.com"})
+
+		assert.NoError(t, NewUserService(repo, nil, nil, nil).CreateUserHandler()(data))
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("profiles created complete emit ProfileCompleted", func(t *testing.T) {
+		userID := uuid.New()
+		repo := new(MockUserRepository)
+		p := new(MockProducer)
+		repo.On("CreateUser", mock.Anything, mock.MatchedBy(func(u *model.User) bool {
+			return !u.NeedsCompletion && u.CompletedAt != nil
+		})).Return(nil)
+		p.On("Produce", mock.Anything, events.ProfileCompleted, mock.MatchedBy(func(e events.ProfileCompletedPayload) bool {
+			return e.UserID == userID
+		})).Return(nil).Once()
+
+		data, _ := json.Marshal(events.UserCreatedPayload{UserID: userID, Firstname: "Jane", Lastname: "Doe", Email: "jane@example.com"})
+
+		assert.NoError(t, NewUserService(repo, nil, p, nil).CreateUserHandler()(data))
+		repo.AssertExpectations(t)
+		p.AssertExpectations(t)
+	})
+
+	t.Run("invalid fields are dropped and the profile marked incomplete", func(t *testing.T) {
+		repo := new(MockUserRepository)
+		repo.On("CreateUser", mock.Anything, mock.MatchedBy(func(u *model.User) bool {
+			return u.Email == "" && u.Firstname == "" && u.Lastname == "Doe" && u.NeedsCompletion && u.CompletedAt == nil
+		})).Return(nil).Once()
+		repo.On("CreateUser", mock.Anything, mock.MatchedBy(func(u *model.User) bool {
+			return u.Email == "" && u.Firstname == "Jane" && u.NeedsCompletion && u.CompletedAt == nil
+		})).Return(nil).Once()
+
+		data, _ := json.Marshal(events.UserCreatedPayload{UserID: uuid.New(), Firstname: "J", Lastname: "Doe", Email: "not-an-email"})
+		assert.NoError(t, NewUserService(repo, nil, nil, nil).CreateUserHandler()(data))
+		data, _ = json.Marshal(events.UserCreatedPayload{UserID: uuid.New(), Firstname: "Jane", Lastname: "Doe"})
+		assert.NoError(t, NewUserService(repo, nil, nil, nil).CreateUserHandler()(data))
+
+		repo.AssertExpectations(t)
+	})
+}
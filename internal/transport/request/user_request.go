@@ -1,19 +1,69 @@
 package request
 
-import "mime/multipart"
+import (
+	"mime/multipart"
+	"time"
+)
 
-// UserRequest is the incoming DTO for creating/updating a user profile
+// UserRequest is the incoming DTO for creating/updating a user profile.
+// It is validated by service.ValidateUser, whatever transport it came from.
 type UserRequest struct {
-	Email       string   `form:"email" binding:"required,email"`
-	Lastname    string   `form:"lastname" binding:"required,min=3,max=20"`
-	Firstname   string   `form:"firstname" binding:"required,min=3,max=20"`
-	About       string   `form:"about,omitempty" validate:"omitempty,max=500"`
+	// Email is owned by the identity provider and ignored on updates
+	Email       string   `form:"email"`
+	Lastname    string   `form:"lastname"`
+	Firstname   string   `form:"firstname"`
+	About       string   `form:"about,omitempty"`
 	DateOfBirth string   `form:"dateOfBirth,omitempty"` // keep as string, parse to time.Time later
-	Gender      string   `form:"gender,omitempty" validate:"omitempty,oneof=male female other"`
-	Location    string   `form:"location,omitempty" validate:"omitempty,max=100"`
-	Socials     []string `form:"socials[]" validate:"omitempty,dive,url"`
+	Gender      string   `form:"gender,omitempty"`
+	Location    string   `form:"location,omitempty"`
+	Socials     []string `form:"socials[]"`
 
 	// File upload fields (unchanged)
 	Avatar multipart.File
 	Header *multipart.FileHeader
 }
+
+// ListUsersQuery is the query string accepted by GET /api/v1/users
+type ListUsersQuery struct {
+	Limit  int    `form:"limit" binding:"omitempty,min=1"`
+	Cursor string `form:"cursor"`
+	Sort   string `form:"sort"`
+
+	Gender          string     `form:"gender" binding:"omitempty,oneof=male female other"`
+	Location        string     `form:"location" binding:"omitempty,max=100"`
+	NeedsCompletion *bool      `form:"needs_completion"`
+	HasAvatar       *bool      `form:"has_avatar"`
+	Moderated       *bool      `form:"moderated"`
+	CreatedFrom     *time.Time `form:"created_from" time_format:"2006-01-02T15:04:05Z07:00"`
+	CreatedTo       *time.Time `form:"created_to" time_format:"2006-01-02T15:04:05Z07:00"`
+	UpdatedFrom     *time.Time `form:"updated_from" time_format:"2006-01-02T15:04:05Z07:00"`
+	UpdatedTo       *time.Time `form:"updated_to" time_format:"2006-01-02T15:04:05Z07:00"`
+}
+
+// ListUsersQueryKeys lists every query parameter ListUsersQuery understands
+var ListUsersQueryKeys = map[string]struct{}{
+	"limit": {}, "cursor": {}, "sort": {},
+	"gender": {}, "location": {}, "needs_completion": {}, "has_avatar": {}, "moderated": {},
+	"created_from": {}, "created_to": {}, "updated_from": {}, "updated_to": {},
+}
+
+// ModerationRequest is the body of ban, suspend and unban requests
+type ModerationRequest struct {
+	Reason string `json:"reason"`
+	// ExpiresAt ends a suspension or a temporary ban; ignored on unban
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+}
+
+// RolesRequest is the body of role grant requests
+type RolesRequest struct {
+	Roles []string `json:"roles"`
+}
+
+// PrivacyRequest is the body of privacy updates: the visibility to set for
+// each field, or null to restore its default
+type PrivacyRequest map[string]*string
+
+// HandleRequest is the body of handle changes
+type HandleRequest struct {
+	Handle string `json:"handle"`
+}
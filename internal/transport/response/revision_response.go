@@ -0,0 +1,30 @@
+package response
+
+import (
+	"github.com/google/uuid"
+	"time"
+)
+
+// FieldChangeResponse is the old and new value of one changed field; null means not set.
+type FieldChangeResponse struct {
+	Field string `json:"field"`
+	Old   any    `json:"old"`
+	New   any    `json:"new"`
+}
+
+type UserRevisionResponse struct {
+	ID        uuid.UUID             `json:"id"`
+	UserID    uuid.UUID             `json:"user_id"`
+	Version   int64                 `json:"version"`
+	Action    string                `json:"action"`
+	Changes   []FieldChangeResponse `json:"changes"`
+	Actor     *uuid.UUID            `json:"actor,omitempty"`
+	Source    string                `json:"source"`
+	CreatedAt time.Time             `json:"created_at"`
+}
+
+// UserRevisionPageResponse is a single page of a profile history, newest first.
+type UserRevisionPageResponse struct {
+	Items      []UserRevisionResponse `json:"items"`
+	NextCursor string                 `json:"next_cursor,omitempty"`
+}
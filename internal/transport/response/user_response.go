@@ -10,10 +10,12 @@ type UserResponse struct {
 	CreatedAt time.Time  `bson:"created_at,omitempty" json:"created_at" validate:"omitempty"`
 	UpdatedAt time.Time  `bson:"updated_at,omitempty" json:"updated_at" validate:"omitempty"`
 	DeletedAt *time.Time `bson:"deleted_at,omitempty" json:"deleted_at,omitempty" validate:"omitempty"`
+	Version   int64      `bson:"version" json:"version"`
 
-	Email     string `bson:"email" json:"email" validate:"required,email"`
-	Firstname string `bson:"firstname" json:"firstname" validate:"required,min=3,max=20"`
-	Lastname  string `bson:"lastname" json:"lastname" validate:"required,min=3,max=20"`
+	Email     string `bson:"email" json:"email,omitempty" validate:"required,email"`
+	Handle    string `bson:"handle,omitempty" json:"handle,omitempty"`
+	Firstname string `bson:"firstname" json:"firstname" validate:"required,min=2,max=20"`
+	Lastname  string `bson:"lastname" json:"lastname" validate:"required,min=2,max=20"`
 	About     string `bson:"about,omitempty" json:"about,omitempty" validate:"omitempty,max=500"`
 
 	DateOfBirth *time.Time `bson:"date_of_birth,omitempty" json:"date_of_birth,omitempty" validate:"omitempty,lte"`
@@ -24,4 +26,45 @@ type UserResponse struct {
 
 	Socials         []string `bson:"socials,omitempty" json:"socials,omitempty" validate:"omitempty,dive,url"`
 	NeedsCompletion bool     `bson:"needs_completion" json:"needs_completion"`
+
+	// Completeness is the share of required and recommended fields set, 0 to 100.
+	// The completion fields are shown to the owner and admins only
+	Completeness  int        `json:"completeness"`
+	MissingFields []string   `json:"missing_fields,omitempty"`
+	CompletedAt   *time.Time `json:"completed_at,omitempty"`
+
+	// AccountStatus is active, suspended or banned; Moderation explains the latter two
+	AccountStatus string              `json:"account_status"`
+	Moderation    *ModerationResponse `json:"moderation,omitempty"`
+
+	// Roles are the application roles granted to the user, shown to its owner and admins only
+	Roles []string `json:"roles,omitempty"`
+
+	// Privacy is the visibility of each sensitive field, shown to the owner and admins
+	Privacy map[string]string `json:"privacy,omitempty"`
+
+	FollowersCount int64 `json:"followers_count"`
+	FollowingCount int64 `json:"following_count"`
+}
+
+// ModerationResponse is the restriction placed on a banned or suspended account.
+type ModerationResponse struct {
+	Status      string     `json:"status"`
+	Reason      string     `json:"reason"`
+	ModeratorID uuid.UUID  `json:"moderator_id"`
+	Since       time.Time  `json:"since"`
+	ExpiresAt   *time.Time `json:"expires_at,omitempty"`
+}
+
+// UserPageResponse is a single page of users with the token for the next one.
+type UserPageResponse struct {
+	Items      []UserResponse `json:"items"`
+	NextCursor string         `json:"next_cursor,omitempty"`
+}
+
+// UserBatchResponse holds the users found for a batch lookup, in request order,
+// and the IDs that matched no live user.
+type UserBatchResponse struct {
+	Users   []UserResponse `json:"users"`
+	Missing []uuid.UUID    `json:"missing"`
 }
@@ -13,6 +13,7 @@ import (
 	"github.com/testcontainers/testcontainers-go/network"
 	"io"
 	"log"
+	"net/http"
 	"os"
 	"testing"
 	"time"
@@ -30,6 +31,9 @@ var (
 	testApp   *gin.Engine
 	container *bootstrap.Container
 	jwksURL   string
+	redisAddr string
+	// keycloakAdmin records the roles the service pushes to Keycloak
+	keycloakAdmin *testutil.KeycloakAdmin
 )
 
 func TestMain(m *testing.M) {
@@ -50,9 +54,11 @@ func TestMain(m *testing.M) {
 	}()
 
 	// --- MongoDB ---
+	// Single-node replica set: the outbox commits events in multi-document transactions
 	mongoReq := testcontainers.ContainerRequest{
 		Image:        "mongo:6.0",
 		ExposedPorts: []string{"27017/tcp"},
+		Cmd:          []string{"--replSet", "rs0", "--bind_ip_all"},
 		Networks:     []string{net.Name},
 		WaitingFor:   wait.ForListeningPort("27017/tcp"),
 		NetworkAliases: map[string][]string{
@@ -61,9 +67,18 @@ func TestMain(m *testing.M) {
 	}
 	mongoC, err := testcontainers.GenericContainer(rootCtx, testcontainers.GenericContainerRequest{ContainerRequest: mongoReq, Started: true})
 	require.NoError(nil, err)
+	initCmd := []string{"mongosh", "--quiet", "--eval",
+		"rs.initiate({_id: 'rs0', members: [{_id: 0, host: 'localhost:27017'}]}); " +
+			"while (!db.hello().isWritablePrimary) { sleep(100) }"}
+	exitCode, reader, err := mongoC.Exec(rootCtx, initCmd)
+	require.NoError(nil, err)
+	if exitCode != 0 {
+		body, _ := io.ReadAll(reader)
+		log.Fatalf("Failed to initiate replica set: %s", string(body))
+	}
 	mongoHost, _ := mongoC.Host(rootCtx)
 	mongoPort, _ := mongoC.MappedPort(rootCtx, "27017")
-	mongoURI := fmt.Sprintf("mongodb://%s:%s", mongoHost, mongoPort.Port())
+	mongoURI := fmt.Sprintf("mongodb://%s:%s/?directConnection=true", mongoHost, mongoPort.Port())
 
 	// --- Redis ---
 	redisReq := testcontainers.ContainerRequest{
@@ -79,7 +94,7 @@ func TestMain(m *testing.M) {
 	require.NoError(nil, err)
 	redisHost, _ := redisC.Host(rootCtx)
 	redisPort, _ := redisC.MappedPort(rootCtx, "6379")
-	redisAddr := fmt.Sprintf("%s:%s", redisHost, redisPort.Port())
+	redisAddr = fmt.Sprintf("%s:%s", redisHost, redisPort.Port())
 
 	// --- MinIO ---
 	minioReq := testcontainers.ContainerRequest{
@@ -170,7 +185,7 @@ func TestMain(m *testing.M) {
 		"--replication-factor", "1",
 	}
 
-	exitCode, reader, err := kafkaC.Exec(rootCtx, execCmd)
+	exitCode, reader, err = kafkaC.Exec(rootCtx, execCmd)
 	require.NoError(nil, err)
 	if exitCode != 0 {
 		body, _ := io.ReadAll(reader)
@@ -181,13 +196,15 @@ func TestMain(m *testing.M) {
 
 	// --- JWKS mock ---
 	jwksURL = "http://localhost:9095/certs"
+	keycloakAdmin = testutil.NewKeycloakAdmin(http.DefaultServeMux, "go-project")
 	testutil.StartMockJWKS(":9095")
 
 	// --- Bootstrap Application ---
-	container = bootstrap.NewTestContainer(mongoURI, kafkaAddr, minioHost, minioPort.Port(), redisAddr, jwksURL)
+	container = bootstrap.NewTestContainer(mongoURI, kafkaAddr, minioHost, minioPort.Port(), redisAddr, jwksURL, "http://localhost:9095")
 
 	// Start the Kafka consumer once for the entire test run. Use rootCtx so it can be cancelled at teardown.
 	go container.Consumer.Start(rootCtx)
+	go container.OutboxRelay.Run(rootCtx)
 
 	// Setup gin + routes for testApp
 	grpc.SetupGRPCServer(container)
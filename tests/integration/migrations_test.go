@@ -0,0 +1,62 @@
+package integration
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"userService/internal/migrations"
+)
+
+func TestMigrations_UpDown(t *testing.T) {
+	ctx := context.Background()
+
+	// Use a scratch database so the application's schema is left alone
+	db := container.DB.Client().Database("migrations_test")
+	defer func() { _ = db.Drop(ctx) }()
+
+	// --- Dry run reports pending steps but records nothing ---
+	pending, err := migrations.NewMigrator(db, migrations.All, true).Up(ctx)
+	require.NoError(t, err)
+	require.Len(t, pending, len(migrations.All))
+
+	count, err := db.Collection("schema_migrations").CountDocuments(ctx, bson.M{})
+	require.NoError(t, err)
+	require.Zero(t, count)
+
+	// --- Up applies everything once ---
+	m := migrations.NewMigrator(db, migrations.All, false)
+	applied, err := m.Up(ctx)
+	require.NoError(t, err)
+	require.Len(t, applied, len(migrations.All))
+
+	applied, err = m.Up(ctx)
+	require.NoError(t, err)
+	require.Empty(t, applied, "second run should be a no-op")
+
+	require.Contains(t, indexNames(t, db.Collection("users").Indexes()), "users_text_search")
+
+	// --- Down reverts the newest steps, here everything but the first ---
+	reverted, err := m.Down(ctx, len(migrations.All)-1)
+	require.NoError(t, err)
+	require.Len(t, reverted, len(migrations.All)-1)
+	require.NotContains(t, indexNames(t, db.Collection("users").Indexes()), "users_text_search")
+
+	statuses, err := m.Status(ctx)
+	require.NoError(t, err)
+	require.True(t, statuses[0].Applied)
+	require.False(t, statuses[1].Applied)
+}
+
+func indexNames(t *testing.T, iv mongo.IndexView) []string {
+	specs, err := iv.ListSpecifications(context.Background())
+	require.NoError(t, err)
+
+	names := make([]string, 0, len(specs))
+	for _, s := range specs {
+		names = append(names, s.Name)
+	}
+	return names
+}
@@ -0,0 +1,119 @@
+package integration
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"userService/internal/events"
+	"userService/internal/model"
+	"userService/internal/repository"
+	"userService/tests/testutil"
+)
+
+func TestOutbox_UpdateIsRelayed(t *testing.T) {
+	ctx := context.Background()
+	userID := createUser(t, events.UserCreatedPayload{
+		UserID: uuid.New(), Firstname: "Outbox", Lastname: "Tester", Email: "outbox@example.com",
+	})
+	headers := map[string]string{
+		"Authorization": "Bearer " + testutil.GenerateMockToken(userID.String()),
+		"Content-Type":  "application/merge-patch+json",
+	}
+
+	w := doRequest(t, http.MethodPatch, "/api/v1/users/"+userID.String(), strings.NewReader(`{"about":"outboxed"}`), headers)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	// The event was stored with the write and the relay marks it sent once Kafka accepted it
+	outbox := container.DB.Collection("outbox")
+	require.Eventually(t, func() bool {
+		cur, err := outbox.Find(ctx, bson.M{"event_type": events.UserUpdated, "status": model.OutboxSent})
+		if err != nil {
+			return false
+		}
+		var messages []model.OutboxMessage
+		if err := cur.All(ctx, &messages); err != nil {
+			return false
+		}
+		for _, msg := range messages {
+			var payload events.UserUpdatedPayload
+			if json.Unmarshal(msg.Payload, &payload) == nil && payload.UserID == userID {
+				return msg.SentAt != nil
+			}
+		}
+		return false
+	}, 10*time.Second, 100*time.Millisecond)
+}
+
+func TestMongoSupportsTransactions(t *testing.T) {
+	// The test MongoDB runs as a single-node replica set
+	ok, err := repository.MongoSupportsTransactions(context.Background(), container.DB.Client())
+	require.NoError(t, err)
+	require.True(t, ok)
+}
+
+func TestPostgresOutbox_RolledBackWithWrite(t *testing.T) {
+	ctx := context.Background()
+	pool := startPostgres(t)
+	users := repository.NewPostgresUserRepository(pool)
+	outbox := repository.NewPostgresOutboxRepository(pool)
+	tx := repository.NewPostgresTransactor(pool)
+
+	user := &model.User{ID: uuid.New(), Firstname: "Roll", Lastname: "Back", Email: "rollback@example.com"}
+	now := time.Now().UTC()
+	msg := &model.OutboxMessage{
+		ID:            uuid.Must(uuid.NewV7()),
+		EventType:     events.UserUpdated,
+		Payload:       []byte(`{"user_id":"` + user.ID.String() + `"}`),
+		Status:        model.OutboxPending,
+		NextAttemptAt: now,
+		CreatedAt:     now,
+	}
+
+	// --- A failing transaction leaves neither the user nor the event ---
+	boom := errors.New("boom")
+	err := tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		require.NoError(t, users.CreateUser(ctx, user))
+		require.NoError(t, outbox.Enqueue(ctx, msg))
+		return boom
+	})
+	require.ErrorIs(t, err, boom)
+
+	got, err := users.GetUserById(ctx, user.ID)
+	require.NoError(t, err)
+	require.Nil(t, got)
+	claimed, err := outbox.ClaimPending(ctx, now.Add(time.Second), time.Minute, 10)
+	require.NoError(t, err)
+	require.Empty(t, claimed)
+
+	// --- A committed one keeps both ---
+	require.NoError(t, tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := users.CreateUser(ctx, user); err != nil {
+			return err
+		}
+		return outbox.Enqueue(ctx, msg)
+	}))
+
+	claimed, err = outbox.ClaimPending(ctx, now.Add(time.Second), time.Minute, 10)
+	require.NoError(t, err)
+	require.Len(t, claimed, 1)
+	require.Equal(t, msg.ID, claimed[0].ID)
+	require.JSONEq(t, string(msg.Payload), string(claimed[0].Payload))
+
+	// Claimed messages stay hidden until the lease runs out
+	again, err := outbox.ClaimPending(ctx, now.Add(2*time.Second), time.Minute, 10)
+	require.NoError(t, err)
+	require.Empty(t, again)
+
+	require.NoError(t, outbox.MarkSent(ctx, msg.ID, now))
+	stats, err := outbox.Stats(ctx)
+	require.NoError(t, err)
+	require.Zero(t, stats.Pending)
+}
@@ -0,0 +1,257 @@
+package integration
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/stretchr/testify/require"
+	"github.com/testcontainers/testcontainers-go"
+	"github.com/testcontainers/testcontainers-go/wait"
+	"userService/internal/migrations"
+	"userService/internal/model"
+	"userService/internal/repository"
+	"userService/internal/service"
+)
+
+// startPostgres runs a throwaway Postgres with the SQL migrations applied.
+func startPostgres(t *testing.T) *pgxpool.Pool {
+	ctx := context.Background()
+
+	pg, err := testcontainers.GenericContainer(ctx, testcontainers.GenericContainerRequest{
+		ContainerRequest: testcontainers.ContainerRequest{
+			Image:        "postgres:16-alpine",
+			ExposedPorts: []string{"5432/tcp"},
+			Env: map[string]string{
+				"POSTGRES_USER":     "test",
+				"POSTGRES_PASSWORD": "test",
+				"POSTGRES_DB":       "users",
+			},
+			WaitingFor: wait.ForLog("database system is ready to accept connections").
+				WithOccurrence(2).
+				WithStartupTimeout(60 * time.Second),
+		},
+		Started: true,
+	})
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = pg.Terminate(context.Background()) })
+
+	host, err := pg.Host(ctx)
+	require.NoError(t, err)
+	port, err := pg.MappedPort(ctx, "5432")
+	require.NoError(t, err)
+
+	pool, err := pgxpool.New(ctx, fmt.Sprintf("postgres://test:test@%s:%s/users?sslmode=disable", host, port.Port()))
+	require.NoError(t, err)
+	t.Cleanup(pool.Close)
+
+	all, err := migrations.PostgresMigrations()
+	require.NoError(t, err)
+	_, err = migrations.NewPostgresMigrator(pool, all, false).Up(ctx)
+	require.NoError(t, err)
+
+	return pool
+}
+
+func TestPostgresUserRepository(t *testing.T) {
+	ctx := context.Background()
+	repo := repository.NewPostgresUserRepository(startPostgres(t))
+
+	alice := &model.User{ID: uuid.New(), Firstname: "Alice", Lastname: "Postgres", Email: "alice@example.com", Location: "Almaty"}
+	bob := &model.User{ID: uuid.New(), Firstname: "Bob", Lastname: "Postgres", Email: "bob@example.com"}
+	require.NoError(t, repo.CreateUser(ctx, alice))
+	require.NoError(t, repo.CreateUser(ctx, bob))
+
+	// --- Unique email ---
+	var conflict *service.ConflictError
+	require.ErrorAs(t, repo.CreateUser(ctx, &model.User{ID: uuid.New(), Email: "alice@example.com"}), &conflict)
+	require.Equal(t, "email", conflict.Field)
+
+	// --- Guarded update and patch ---
+	alice.About = "first"
+	require.NoError(t, repo.UpdateUser(ctx, alice))
+	require.EqualValues(t, 2, alice.Version)
+
+	patched, err := repo.PatchUser(ctx, alice.ID, alice.Version, service.UserPatch{
+		Fields:  []string{service.FieldAbout, service.FieldSocials},
+		Socials: []string{"https://github.com/alice"},
+	})
+	require.NoError(t, err)
+	require.Empty(t, patched.About)
+	require.Equal(t, []string{"https://github.com/alice"}, patched.Socials)
+
+	_, err = repo.PatchUser(ctx, alice.ID, 1, service.UserPatch{Fields: []string{service.FieldAbout}})
+	require.ErrorIs(t, err, service.ErrVersionConflict)
+
+	// --- Listing, filtering and search ---
+	page, err := repo.ListUsers(ctx, service.ListQuery{Limit: 1, SortField: service.SortCreatedAt})
+	require.NoError(t, err)
+	require.Len(t, page, 1)
+	next, err := repo.ListUsers(ctx, service.ListQuery{
+		Limit:     10,
+		SortField: service.SortCreatedAt,
+		After:     &service.Cursor{Sort: service.SortCreatedAt, Value: page[0].CreatedAt, ID: page[0].ID},
+	})
+	require.NoError(t, err)
+	require.Len(t, next, 1)
+	require.NotEqual(t, page[0].ID, next[0].ID)
+
+	filtered, err := repo.ListUsers(ctx, service.ListQuery{Limit: 10, SortField: service.SortCreatedAt, Filter: service.UserFilter{Location: "almaty"}})
+	require.NoError(t, err)
+	require.Len(t, filtered, 1)
+	require.Equal(t, alice.ID, filtered[0].ID)
+
+	found, err := repo.SearchUsers(ctx, service.SearchQuery{Text: "bob", Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, found, 1)
+	require.Equal(t, bob.ID, found[0].ID)
+
+	// --- Soft delete, restore and purge ---
+	require.NoError(t, repo.DeleteUserById(ctx, bob.ID))
+	deleted, err := repo.GetUserById(ctx, bob.ID)
+	require.NoError(t, err)
+	require.NotNil(t, deleted.DeletedAt)
+
+	batch, err := repo.GetUsersByIds(ctx, []uuid.UUID{alice.ID, bob.ID})
+	require.NoError(t, err)
+	require.Len(t, batch, 1)
+
+	require.ErrorIs(t, repo.RestoreUser(ctx, bob.ID, time.Now().Add(time.Hour)), service.ErrRestoreExpired)
+	require.NoError(t, repo.RestoreUser(ctx, bob.ID, time.Now().Add(-time.Hour)))
+	require.ErrorIs(t, repo.RestoreUser(ctx, bob.ID, time.Now().Add(-time.Hour)), service.ErrNotDeleted)
+
+	require.NoError(t, repo.DeleteUserById(ctx, bob.ID))
+	purged, err := repo.PurgeUser(ctx, bob.ID, time.Now().Add(time.Minute))
+	require.NoError(t, err)
+	require.True(t, purged)
+	gone, err := repo.GetUserById(ctx, bob.ID)
+	require.NoError(t, err)
+	require.Nil(t, gone)
+}
+
+func TestPostgresFollowRepository(t *testing.T) {
+	ctx := context.Background()
+	repo := repository.NewPostgresFollowRepository(startPostgres(t))
+
+	userID := uuid.New()
+	now := time.Now().UTC().Truncate(time.Microsecond)
+	followers := []uuid.UUID{uuid.New(), uuid.New(), uuid.New()}
+	for i, id := range followers {
+		created, err := repo.AddFollow(ctx, &model.Follow{FollowerID: id, FolloweeID: userID, CreatedAt: now.Add(time.Duration(i) * time.Second)})
+		require.NoError(t, err)
+		require.True(t, created)
+	}
+
+	// --- Following twice keeps the first edge ---
+	created, err := repo.AddFollow(ctx, &model.Follow{FollowerID: followers[0], FolloweeID: userID, CreatedAt: now.Add(time.Hour)})
+	require.NoError(t, err)
+	require.False(t, created)
+
+	following, err := repo.IsFollowing(ctx, followers[0], userID)
+	require.NoError(t, err)
+	require.True(t, following)
+	following, err = repo.IsFollowing(ctx, userID, followers[0])
+	require.NoError(t, err)
+	require.False(t, following)
+
+	// --- Newest first, paged by cursor ---
+	page, err := repo.ListFollowers(ctx, userID, 2, nil)
+	require.NoError(t, err)
+	require.Len(t, page, 2)
+	require.Equal(t, followers[2], page[0].FollowerID)
+	last := page[1]
+	page, err = repo.ListFollowers(ctx, userID, 2, &service.Cursor{Value: last.CreatedAt, ID: last.FollowerID})
+	require.NoError(t, err)
+	require.Len(t, page, 1)
+	require.Equal(t, followers[0], page[0].FollowerID)
+
+	page, err = repo.ListFollowing(ctx, followers[1], 10, nil)
+	require.NoError(t, err)
+	require.Len(t, page, 1)
+	require.Equal(t, userID, page[0].FolloweeID)
+
+	// --- Unfollowing twice removes once ---
+	removed, err := repo.RemoveFollow(ctx, followers[0], userID)
+	require.NoError(t, err)
+	require.True(t, removed)
+	removed, err = repo.RemoveFollow(ctx, followers[0], userID)
+	require.NoError(t, err)
+	require.False(t, removed)
+
+	// --- Purging a user removes its edges in both directions ---
+	gone, err := repo.RemoveFollowsOf(ctx, userID)
+	require.NoError(t, err)
+	require.Len(t, gone, 2)
+	page, err = repo.ListFollowing(ctx, followers[1], 10, nil)
+	require.NoError(t, err)
+	require.Empty(t, page)
+}
+
+func TestPostgresBlockRepository(t *testing.T) {
+	ctx := context.Background()
+	repo := repository.NewPostgresBlockRepository(startPostgres(t))
+
+	blockedID, blockerID := uuid.New(), uuid.New()
+	created, err := repo.AddBlock(ctx, &model.Block{BlockerID: blockerID, BlockedID: blockedID, CreatedAt: time.Now()})
+	require.NoError(t, err)
+	require.True(t, created)
+	created, err = repo.AddBlock(ctx, &model.Block{BlockerID: blockerID, BlockedID: blockedID, CreatedAt: time.Now()})
+	require.NoError(t, err)
+	require.False(t, created)
+
+	blockers, err := repo.ListBlockers(ctx, blockedID)
+	require.NoError(t, err)
+	require.Equal(t, []uuid.UUID{blockerID}, blockers)
+	blockers, err = repo.ListBlockers(ctx, blockerID)
+	require.NoError(t, err)
+	require.Empty(t, blockers)
+
+	blocked, err := repo.ListBlocked(ctx, blockerID)
+	require.NoError(t, err)
+	require.Equal(t, []uuid.UUID{blockedID}, blocked)
+
+	removed, err := repo.RemoveBlock(ctx, blockerID, blockedID)
+	require.NoError(t, err)
+	require.True(t, removed)
+	removed, err = repo.RemoveBlock(ctx, blockerID, blockedID)
+	require.NoError(t, err)
+	require.False(t, removed)
+
+	// --- Purging a user removes its blocks in both directions ---
+	_, err = repo.AddBlock(ctx, &model.Block{BlockerID: blockerID, BlockedID: blockedID, CreatedAt: time.Now()})
+	require.NoError(t, err)
+	_, err = repo.AddBlock(ctx, &model.Block{BlockerID: blockedID, BlockedID: blockerID, CreatedAt: time.Now()})
+	require.NoError(t, err)
+	gone, err := repo.RemoveBlocksOf(ctx, blockedID)
+	require.NoError(t, err)
+	require.Len(t, gone, 2)
+	blocked, err = repo.ListBlocked(ctx, blockerID)
+	require.NoError(t, err)
+	require.Empty(t, blocked)
+}
+
+func TestPostgresHandleRepository(t *testing.T) {
+	ctx := context.Background()
+	repo := repository.NewPostgresHandleRepository(startPostgres(t))
+
+	now := time.Now().UTC()
+	ada, bob := uuid.New(), uuid.New()
+	require.NoError(t, repo.ReserveHandle(ctx, "ada", ada, nil, now))
+	require.NoError(t, repo.ReserveHandle(ctx, "ada", ada, nil, now), "owners may reserve again")
+
+	// --- Held handles, current or redirecting, cannot be taken ---
+	require.ErrorIs(t, repo.ReserveHandle(ctx, "ada", bob, nil, now), service.ErrConflict)
+	until := now.Add(time.Hour)
+	require.NoError(t, repo.ReserveHandle(ctx, "ada", ada, &until, now))
+	require.ErrorIs(t, repo.ReserveHandle(ctx, "ada", bob, nil, now), service.ErrConflict)
+
+	// --- Expired reservations are taken over ---
+	require.NoError(t, repo.ReserveHandle(ctx, "ada", bob, nil, until))
+
+	// --- Purged users release theirs ---
+	require.NoError(t, repo.ReleaseHandles(ctx, bob))
+	require.NoError(t, repo.ReserveHandle(ctx, "ada", ada, nil, now))
+}
@@ -0,0 +1,37 @@
+package integration
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"userService/internal/migrations"
+	"userService/internal/repository"
+	"userService/internal/repository/repotest"
+	"userService/internal/service"
+)
+
+func TestMongoUserRepository_Conformance(t *testing.T) {
+	repotest.RunUserRepository(t, func(t *testing.T) service.UserRepository {
+		ctx := context.Background()
+
+		// A migrated scratch database per subtest, so unique indexes apply
+		db := container.DB.Client().Database("conformance_" + uuid.NewString()[:8])
+		t.Cleanup(func() { _ = db.Drop(context.Background()) })
+
+		_, err := migrations.NewMigrator(db, migrations.All, false).Up(ctx)
+		require.NoError(t, err)
+		return repository.NewUserRepository(db)
+	})
+}
+
+func TestPostgresUserRepository_Conformance(t *testing.T) {
+	pool := startPostgres(t)
+
+	repotest.RunUserRepository(t, func(t *testing.T) service.UserRepository {
+		_, err := pool.Exec(context.Background(), "TRUNCATE users")
+		require.NoError(t, err)
+		return repository.NewPostgresUserRepository(pool)
+	})
+}
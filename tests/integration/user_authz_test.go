@@ -0,0 +1,69 @@
+package integration
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"userService/internal/auth"
+	"userService/internal/events"
+	"userService/tests/testutil"
+)
+
+func TestRoutePolicies(t *testing.T) {
+	ownerID := createUser(t, events.UserCreatedPayload{
+		UserID: uuid.New(), Firstname: "Policy", Lastname: "Owner", Email: "policy-owner@example.com",
+	})
+	path := "/api/v1/users/" + ownerID.String()
+	bearer := func(token string) map[string]string {
+		return map[string]string{
+			"Authorization": "Bearer " + token,
+			"Content-Type":  "application/merge-patch+json",
+		}
+	}
+	stranger := bearer(testutil.GenerateMockToken(uuid.NewString()))
+	admin := bearer(testutil.GenerateMockTokenWithRoles(uuid.NewString(), auth.RoleAdmin))
+
+	// --- Writes need a valid token ---
+	w := doRequest(t, http.MethodPatch, path, strings.NewReader(`{"about":"anonymous"}`), bearer("not-a-token"))
+	require.Equal(t, http.StatusUnauthorized, w.Code)
+	require.Equal(t, "UNAUTHORIZED", decodeError(t, w.Body.Bytes()).Code)
+
+	// --- Tokens of another client or another issuer are not valid here ---
+	otherClient := testutil.GenerateMockTokenWithClaims(ownerID.String(), jwt.MapClaims{"aud": []string{"account"}})
+	w = doRequest(t, http.MethodPatch, path, strings.NewReader(`{"about":"other client"}`), bearer(otherClient))
+	require.Equal(t, http.StatusUnauthorized, w.Code)
+
+	otherIssuer := testutil.GenerateMockTokenWithClaims(ownerID.String(), jwt.MapClaims{"iss": "http://evil.example.com/realms/go-project"})
+	w = doRequest(t, http.MethodPatch, path, strings.NewReader(`{"about":"other issuer"}`), bearer(otherIssuer))
+	require.Equal(t, http.StatusUnauthorized, w.Code)
+
+	// --- Other users can neither edit nor delete the profile ---
+	w = doRequest(t, http.MethodPatch, path, strings.NewReader(`{"about":"hijacked"}`), stranger)
+	require.Equal(t, http.StatusForbidden, w.Code)
+	require.Equal(t, "FORBIDDEN", decodeError(t, w.Body.Bytes()).Code)
+
+	w = doRequest(t, http.MethodDelete, path, nil, stranger)
+	require.Equal(t, http.StatusForbidden, w.Code)
+
+	w = doRequest(t, http.MethodGet, path+"/history", nil, stranger)
+	require.Equal(t, http.StatusForbidden, w.Code)
+
+	// --- Admins can ---
+	w = doRequest(t, http.MethodPatch, path, strings.NewReader(`{"about":"moderated"}`), admin)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	require.Contains(t, w.Body.String(), `"about":"moderated"`)
+
+	// --- Privileged reads are admin only ---
+	w = doRequest(t, http.MethodGet, "/api/v1/users/by-email?email=policy-owner@example.com", nil, stranger)
+	require.Equal(t, http.StatusForbidden, w.Code)
+
+	w = doRequest(t, http.MethodGet, path+"?include_deleted=true", nil, nil)
+	require.Equal(t, http.StatusUnauthorized, w.Code)
+
+	w = doRequest(t, http.MethodGet, path+"?include_deleted=true", nil, admin)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+}
@@ -0,0 +1,43 @@
+package integration
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"userService/internal/events"
+	"userService/internal/transport/response"
+)
+
+func TestBatchGetUsers(t *testing.T) {
+	first := createUser(t, events.UserCreatedPayload{
+		UserID: uuid.New(), Firstname: "Batch", Lastname: "First", Email: "batch-first@example.com",
+	})
+	second := createUser(t, events.UserCreatedPayload{
+		UserID: uuid.New(), Firstname: "Batch", Lastname: "Second", Email: "batch-second@example.com",
+	})
+	unknown := uuid.New()
+
+	// Warm the cache for one of them so both paths are exercised
+	w := doRequest(t, http.MethodGet, "/api/v1/users/"+second.String(), nil, nil)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	w = doRequest(t, http.MethodGet, fmt.Sprintf("/api/v1/users?ids=%s,%s,%s", second, unknown, first), nil, nil)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	var batch response.UserBatchResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &batch))
+	require.Len(t, batch.Users, 2)
+	require.Equal(t, second, batch.Users[0].ID)
+	require.Equal(t, first, batch.Users[1].ID)
+	require.Equal(t, []uuid.UUID{unknown}, batch.Missing)
+
+	w = doRequest(t, http.MethodGet, "/api/v1/users?ids=not-a-uuid", nil, nil)
+	require.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = doRequest(t, http.MethodGet, fmt.Sprintf("/api/v1/users?ids=%s&limit=5", first), nil, nil)
+	require.Equal(t, http.StatusBadRequest, w.Code)
+}
@@ -0,0 +1,161 @@
+package integration
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+
+	"userService/internal/auth"
+	"userService/internal/cache"
+	"userService/internal/events"
+	"userService/internal/model"
+	"userService/internal/transport/response"
+	"userService/tests/testutil"
+)
+
+func TestUserBlocks(t *testing.T) {
+	blockerID := createUser(t, events.UserCreatedPayload{
+		UserID: uuid.New(), Firstname: "Blockington", Lastname: "Owner", Email: "block.owner@example.com",
+	})
+	blockedID := createUser(t, events.UserCreatedPayload{
+		UserID: uuid.New(), Firstname: "Block", Lastname: "Target", Email: "block.target@example.com",
+	})
+	bearer := func(id uuid.UUID) map[string]string {
+		return map[string]string{"Authorization": "Bearer " + testutil.GenerateMockToken(id.String())}
+	}
+	blocker, blocked := bearer(blockerID), bearer(blockedID)
+	admin := map[string]string{"Authorization": "Bearer " + testutil.GenerateMockTokenWithRoles(uuid.NewString(), auth.RoleAdmin)}
+	profile := "/api/v1/users/" + blockerID.String()
+
+	w := doRequest(t, http.MethodPut, profile+"/follow", nil, blocked)
+	require.Equal(t, http.StatusNoContent, w.Code, w.Body.String())
+
+	// --- Blocking is idempotent and cannot target oneself ---
+	w = doRequest(t, http.MethodPut, profile+"/block", nil, blocker)
+	require.Equal(t, http.StatusUnprocessableEntity, w.Code, w.Body.String())
+	require.Equal(t, "SELF_BLOCK", decodeError(t, w.Body.Bytes()).Code)
+	for i := 0; i < 2; i++ {
+		w = doRequest(t, http.MethodPut, "/api/v1/users/"+blockedID.String()+"/block", nil, blocker)
+		require.Equal(t, http.StatusNoContent, w.Code, w.Body.String())
+	}
+
+	// --- The blocked user no longer finds the blocker ---
+	w = doRequest(t, http.MethodGet, profile, nil, blocked)
+	require.Equal(t, http.StatusNotFound, w.Code, w.Body.String())
+	w = doRequest(t, http.MethodGet, profile+"/followers", nil, blocked)
+	require.Equal(t, http.StatusNotFound, w.Code, w.Body.String())
+	w = doRequest(t, http.MethodPut, profile+"/follow", nil, blocked)
+	require.Equal(t, http.StatusNotFound, w.Code, w.Body.String())
+
+	var page response.UserPageResponse
+	w = doRequest(t, http.MethodGet, "/api/v1/users/search?q=blockington", nil, blocked)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &page))
+	require.Empty(t, page.Items)
+
+	var batch response.UserBatchResponse
+	w = doRequest(t, http.MethodGet, "/api/v1/users?ids="+blockerID.String(), nil, blocked)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &batch))
+	require.Equal(t, []uuid.UUID{blockerID}, batch.Missing)
+
+	// --- Nor does the blocker find the blocked user ---
+	w = doRequest(t, http.MethodGet, "/api/v1/users/"+blockedID.String(), nil, blocker)
+	require.Equal(t, http.StatusNotFound, w.Code, w.Body.String())
+	w = doRequest(t, http.MethodGet, "/api/v1/users/search?q=target", nil, blocker)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &page))
+	for _, item := range page.Items {
+		require.NotEqual(t, blockedID, item.ID)
+	}
+
+	// --- Everyone else still does, and the follow was ended ---
+	var user response.UserResponse
+	w = doRequest(t, http.MethodGet, profile, nil, nil)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &user))
+	require.EqualValues(t, 0, user.FollowersCount)
+	w = doRequest(t, http.MethodGet, profile, nil, admin)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	// --- Checks backed by the Redis block set ---
+	ctx := context.Background()
+	isBlocked, err := container.UserService.IsBlocked(ctx, blockerID, blockedID)
+	require.NoError(t, err)
+	require.True(t, isBlocked)
+	isBlocked, err = container.UserService.IsBlocked(ctx, blockedID, blockerID)
+	require.NoError(t, err)
+	require.False(t, isBlocked)
+
+	blockers, err := container.UserService.BlockedBy(ctx, blockedID, []uuid.UUID{uuid.New(), blockerID})
+	require.NoError(t, err)
+	require.Equal(t, []uuid.UUID{blockerID}, blockers)
+
+	// --- Unblocking restores visibility at once ---
+	for i := 0; i < 2; i++ {
+		w = doRequest(t, http.MethodDelete, "/api/v1/users/"+blockedID.String()+"/block", nil, blocker)
+		require.Equal(t, http.StatusNoContent, w.Code, w.Body.String())
+	}
+	w = doRequest(t, http.MethodGet, profile, nil, blocked)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	require.Equal(t, 1, blockEvents(t, events.UserBlocked, blockerID, blockedID))
+	require.Equal(t, 1, blockEvents(t, events.UserUnblocked, blockerID, blockedID))
+	require.Equal(t, 1, followEvents(t, events.UserUnfollowed, blockedID, blockerID))
+}
+
+func TestRedisBlockSet_StaleLoad(t *testing.T) {
+	ctx := context.Background()
+	client := redis.NewClient(&redis.Options{Addr: redisAddr})
+	t.Cleanup(func() { _ = client.Close() })
+	set := cache.NewRedisBlockSet(client)
+	blockedID, blockerID := uuid.New(), uuid.New()
+
+	// A load reads the generation, then a block commits and invalidates the
+	// set before the load stores what it read without the new blocker
+	gen, err := set.Generation(ctx, blockedID)
+	require.NoError(t, err)
+	require.NoError(t, set.InvalidateBlockers(ctx, blockedID))
+	require.NoError(t, set.StoreBlockers(ctx, blockedID, []uuid.UUID{}, gen, time.Minute))
+
+	_, ok, err := set.Blockers(ctx, blockedID)
+	require.NoError(t, err)
+	require.False(t, ok, "a load older than the invalidation must not be cached")
+
+	// The next load sees the block and is cached
+	gen, err = set.Generation(ctx, blockedID)
+	require.NoError(t, err)
+	require.NoError(t, set.StoreBlockers(ctx, blockedID, []uuid.UUID{blockerID}, gen, time.Minute))
+
+	blocked, ok, err := set.IsBlockedBy(ctx, blockedID, blockerID)
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.True(t, blocked)
+}
+
+// blockEvents counts the stored events of eventType for the given block.
+func blockEvents(t *testing.T, eventType string, blockerID, blockedID uuid.UUID) int {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	cur, err := container.DB.Collection("outbox").Find(ctx, bson.M{"event_type": eventType})
+	require.NoError(t, err)
+	var messages []model.OutboxMessage
+	require.NoError(t, cur.All(ctx, &messages))
+
+	count := 0
+	for _, msg := range messages {
+		var payload events.BlockPayload
+		if json.Unmarshal(msg.Payload, &payload) == nil && payload.BlockerID == blockerID && payload.BlockedID == blockedID {
+			count++
+		}
+	}
+	return count
+}
@@ -0,0 +1,88 @@
+package integration
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"userService/internal/events"
+	"userService/internal/model"
+	"userService/tests/testutil"
+)
+
+func TestProfileCompletion(t *testing.T) {
+	userID := createUser(t, events.UserCreatedPayload{
+		UserID: uuid.New(), Firstname: "Completion", Lastname: "null", Email: "completion@example.com",
+	})
+	headers := map[string]string{
+		"Authorization": "Bearer " + testutil.GenerateMockToken(userID.String()),
+		"Content-Type":  "application/merge-patch+json",
+	}
+
+	// --- The missing last name keeps the profile incomplete ---
+	w := doRequest(t, http.MethodGet, "/api/v1/users/"+userID.String(), nil, headers)
+	require.Equal(t, http.StatusOK, w.Code)
+	var resp map[string]any
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	require.Equal(t, true, resp["needs_completion"])
+	require.Contains(t, resp["missing_fields"], "lastname")
+	require.NotContains(t, resp, "completed_at")
+
+	// --- Other viewers cannot tell what is missing ---
+	w = doRequest(t, http.MethodGet, "/api/v1/users/"+userID.String(), nil, nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	resp = nil
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	require.NotContains(t, resp, "missing_fields")
+	require.Equal(t, false, resp["needs_completion"])
+
+	// --- Setting it completes the profile ---
+	w = doRequest(t, http.MethodPatch, "/api/v1/users/"+userID.String(), strings.NewReader(`{"lastname":"Tester"}`), headers)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	resp = nil
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	require.Equal(t, false, resp["needs_completion"])
+	require.NotContains(t, resp["missing_fields"], "lastname")
+	require.Contains(t, resp, "completed_at")
+
+	// --- Later edits raise the score but do not complete it again ---
+	w = doRequest(t, http.MethodPatch, "/api/v1/users/"+userID.String(), strings.NewReader(`{"about":"done"}`), headers)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var updated map[string]any
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &updated))
+	require.Greater(t, updated["completeness"], resp["completeness"])
+
+	require.Equal(t, 1, countProfileCompleted(t, userID))
+
+	// --- Profiles complete at registration are announced too ---
+	completeID := createUser(t, events.UserCreatedPayload{
+		UserID: uuid.New(), Firstname: "Complete", Lastname: "Already", Email: "complete@example.com",
+	})
+	require.Equal(t, 1, countProfileCompleted(t, completeID))
+}
+
+// countProfileCompleted counts the ProfileCompleted events stored for userID.
+func countProfileCompleted(t *testing.T, userID uuid.UUID) int {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	cur, err := container.DB.Collection("outbox").Find(ctx, bson.M{"event_type": events.ProfileCompleted})
+	require.NoError(t, err)
+	var messages []model.OutboxMessage
+	require.NoError(t, cur.All(ctx, &messages))
+
+	count := 0
+	for _, msg := range messages {
+		var payload events.ProfileCompletedPayload
+		if json.Unmarshal(msg.Payload, &payload) == nil && payload.UserID == userID {
+			count++
+		}
+	}
+	return count
+}
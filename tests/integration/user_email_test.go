@@ -0,0 +1,59 @@
+package integration
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"userService/internal/auth"
+	"userService/internal/events"
+	"userService/internal/model"
+	"userService/internal/repository"
+	"userService/internal/service"
+	"userService/tests/testutil"
+)
+
+func TestGetUserByEmail(t *testing.T) {
+	userID := createUser(t, events.UserCreatedPayload{
+		UserID:    uuid.New(),
+		Firstname: "Email",
+		Lastname:  "Lookup",
+		Email:     "  Email.Lookup@Example.com",
+	})
+
+	admin := map[string]string{
+		"Authorization": "Bearer " + testutil.GenerateMockTokenWithRoles(uuid.NewString(), auth.RoleAdmin),
+	}
+	w := doRequest(t, http.MethodGet, "/api/v1/users/by-email?email=EMAIL.lookup@example.com", nil, admin)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	var resp map[string]any
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	require.Equal(t, userID.String(), resp["id"])
+	require.Equal(t, "email.lookup@example.com", resp["email"])
+
+	// --- Regular users may not look up others by email ---
+	user := map[string]string{
+		"Authorization": "Bearer " + testutil.GenerateMockToken(userID.String()),
+	}
+	w = doRequest(t, http.MethodGet, "/api/v1/users/by-email?email=email.lookup@example.com", nil, user)
+	require.Equal(t, http.StatusForbidden, w.Code)
+
+	w = doRequest(t, http.MethodGet, "/api/v1/users/by-email?email=nobody@example.com", nil, admin)
+	require.Equal(t, http.StatusNotFound, w.Code)
+
+	// --- The unique index rejects a second profile with the same email ---
+	repo := repository.NewUserRepository(container.DB)
+	err := repo.CreateUser(context.Background(), &model.User{
+		ID:        uuid.New(),
+		Firstname: "Email",
+		Lastname:  "Copy",
+		Email:     "email.lookup@example.com",
+	})
+	var conflict *service.ConflictError
+	require.ErrorAs(t, err, &conflict)
+	require.Equal(t, "email", conflict.Field)
+}
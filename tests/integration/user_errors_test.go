@@ -0,0 +1,71 @@
+package integration
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"userService/internal/events"
+	"userService/tests/testutil"
+)
+
+// errorBody is the JSON error envelope of every handler.
+type errorBody struct {
+	Status  string          `json:"status"`
+	Code    string          `json:"code"`
+	Message string          `json:"message"`
+	Details json.RawMessage `json:"details"`
+}
+
+func decodeError(t *testing.T, body []byte) errorBody {
+	t.Helper()
+	var e errorBody
+	require.NoError(t, json.Unmarshal(body, &e))
+	require.Equal(t, "error", e.Status)
+	return e
+}
+
+func TestDomainErrorMapping(t *testing.T) {
+	// --- Unknown users are 404 on reads and writes ---
+	w := doRequest(t, http.MethodGet, "/api/v1/users/"+uuid.NewString(), nil, nil)
+	require.Equal(t, http.StatusNotFound, w.Code)
+	e := decodeError(t, w.Body.Bytes())
+	require.Equal(t, "NOT_FOUND", e.Code)
+	require.Empty(t, e.Details)
+
+	strangerID := uuid.NewString()
+	stranger := map[string]string{"Authorization": "Bearer " + testutil.GenerateMockToken(strangerID)}
+	w = doRequest(t, http.MethodDelete, "/api/v1/users/"+strangerID, nil, stranger)
+	require.Equal(t, http.StatusNotFound, w.Code)
+	require.Equal(t, "NOT_FOUND", decodeError(t, w.Body.Bytes()).Code)
+
+	// --- Deleted users are 410 ---
+	userID := createUser(t, events.UserCreatedPayload{
+		UserID: uuid.New(), Firstname: "Errors", Lastname: "Tester", Email: "errors@example.com",
+	})
+	owner := map[string]string{
+		"Authorization": "Bearer " + testutil.GenerateMockToken(userID.String()),
+		"Content-Type":  "application/merge-patch+json",
+	}
+
+	// Bad input is 422 with field-level details
+	w = doRequest(t, http.MethodPatch, "/api/v1/users/"+userID.String(), strings.NewReader(`{"gender":"robot"}`), owner)
+	require.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	e = decodeError(t, w.Body.Bytes())
+	require.Equal(t, "VALIDATION_FAILED", e.Code)
+	require.Contains(t, string(e.Details), `"field":"gender"`)
+
+	w = doRequest(t, http.MethodDelete, "/api/v1/users/"+userID.String(), nil, owner)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	w = doRequest(t, http.MethodDelete, "/api/v1/users/"+userID.String(), nil, owner)
+	require.Equal(t, http.StatusGone, w.Code)
+	require.Equal(t, "USER_DELETED", decodeError(t, w.Body.Bytes()).Code)
+
+	w = doRequest(t, http.MethodPatch, "/api/v1/users/"+userID.String(), strings.NewReader(`{"about":"late"}`), owner)
+	require.Equal(t, http.StatusGone, w.Code)
+	require.Equal(t, "USER_DELETED", decodeError(t, w.Body.Bytes()).Code)
+}
@@ -0,0 +1,165 @@
+package integration
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+
+	"userService/internal/events"
+	"userService/internal/model"
+	"userService/internal/transport/response"
+	"userService/tests/testutil"
+)
+
+func TestUserFollows(t *testing.T) {
+	aliceID := createUser(t, events.UserCreatedPayload{
+		UserID: uuid.New(), Firstname: "Follow", Lastname: "Alice", Email: "follow.alice@example.com",
+	})
+	bobID := createUser(t, events.UserCreatedPayload{
+		UserID: uuid.New(), Firstname: "Follow", Lastname: "Bob", Email: "follow.bob@example.com",
+	})
+	carolID := createUser(t, events.UserCreatedPayload{
+		UserID: uuid.New(), Firstname: "Follow", Lastname: "Carol", Email: "follow.carol@example.com",
+	})
+	bearer := func(id uuid.UUID) map[string]string {
+		return map[string]string{"Authorization": "Bearer " + testutil.GenerateMockToken(id.String())}
+	}
+	alice, bob, carol := bearer(aliceID), bearer(bobID), bearer(carolID)
+
+	// --- Following requires a token and another user ---
+	w := doRequest(t, http.MethodPut, "/api/v1/users/"+aliceID.String()+"/follow", nil, nil)
+	require.Equal(t, http.StatusUnauthorized, w.Code, w.Body.String())
+	w = doRequest(t, http.MethodPut, "/api/v1/users/"+aliceID.String()+"/follow", nil, alice)
+	require.Equal(t, http.StatusUnprocessableEntity, w.Code, w.Body.String())
+	require.Equal(t, "SELF_FOLLOW", decodeError(t, w.Body.Bytes()).Code)
+	w = doRequest(t, http.MethodPut, "/api/v1/users/"+uuid.NewString()+"/follow", nil, alice)
+	require.Equal(t, http.StatusNotFound, w.Code, w.Body.String())
+
+	// --- Following is idempotent ---
+	for i := 0; i < 2; i++ {
+		w = doRequest(t, http.MethodPut, "/api/v1/users/"+aliceID.String()+"/follow", nil, bob)
+		require.Equal(t, http.StatusNoContent, w.Code, w.Body.String())
+	}
+	// Keep the follows apart in time so the list order is predictable
+	time.Sleep(10 * time.Millisecond)
+	w = doRequest(t, http.MethodPut, "/api/v1/users/"+aliceID.String()+"/follow", nil, carol)
+	require.Equal(t, http.StatusNoContent, w.Code, w.Body.String())
+
+	var user response.UserResponse
+	w = doRequest(t, http.MethodGet, "/api/v1/users/"+aliceID.String(), nil, nil)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &user))
+	require.EqualValues(t, 2, user.FollowersCount)
+	require.EqualValues(t, 0, user.FollowingCount)
+
+	// --- Lists are newest first and paged ---
+	var page response.UserPageResponse
+	w = doRequest(t, http.MethodGet, "/api/v1/users/"+aliceID.String()+"/followers?limit=1", nil, nil)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &page))
+	require.Len(t, page.Items, 1)
+	require.Equal(t, carolID, page.Items[0].ID)
+	require.NotEmpty(t, page.NextCursor)
+
+	w = doRequest(t, http.MethodGet, "/api/v1/users/"+aliceID.String()+"/followers?limit=1&cursor="+page.NextCursor, nil, nil)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	page = response.UserPageResponse{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &page))
+	require.Len(t, page.Items, 1)
+	require.Equal(t, bobID, page.Items[0].ID)
+	require.Empty(t, page.NextCursor)
+
+	w = doRequest(t, http.MethodGet, "/api/v1/users/"+bobID.String()+"/following", nil, nil)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	page = response.UserPageResponse{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &page))
+	require.Len(t, page.Items, 1)
+	require.Equal(t, aliceID, page.Items[0].ID)
+
+	// --- Unfollowing is idempotent ---
+	for i := 0; i < 2; i++ {
+		w = doRequest(t, http.MethodDelete, "/api/v1/users/"+aliceID.String()+"/follow", nil, bob)
+		require.Equal(t, http.StatusNoContent, w.Code, w.Body.String())
+	}
+	w = doRequest(t, http.MethodGet, "/api/v1/users/"+bobID.String(), nil, nil)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	user = response.UserResponse{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &user))
+	require.EqualValues(t, 0, user.FollowingCount)
+
+	// One event per change, none for the repeats
+	require.Equal(t, 2, followEvents(t, events.UserFollowed, bobID, aliceID)+followEvents(t, events.UserFollowed, carolID, aliceID))
+	require.Equal(t, 1, followEvents(t, events.UserUnfollowed, bobID, aliceID))
+}
+
+func TestUserFollows_DeletedUsers(t *testing.T) {
+	aliceID := createUser(t, events.UserCreatedPayload{
+		UserID: uuid.New(), Firstname: "Gone", Lastname: "Alice", Email: "gone.alice@example.com",
+	})
+	daveID := createUser(t, events.UserCreatedPayload{
+		UserID: uuid.New(), Firstname: "Gone", Lastname: "Dave", Email: "gone.dave@example.com",
+	})
+	dave := map[string]string{"Authorization": "Bearer " + testutil.GenerateMockToken(daveID.String())}
+	profile := "/api/v1/users/" + aliceID.String()
+
+	w := doRequest(t, http.MethodPut, profile+"/follow", nil, dave)
+	require.Equal(t, http.StatusNoContent, w.Code, w.Body.String())
+	w = doRequest(t, http.MethodDelete, "/api/v1/users/"+daveID.String(), nil, dave)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	// --- Soft-deleted followers are left out of lists but still counted ---
+	var page response.UserPageResponse
+	w = doRequest(t, http.MethodGet, profile+"/followers", nil, nil)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &page))
+	require.Empty(t, page.Items)
+	require.EqualValues(t, 1, followersCount(t, aliceID))
+
+	// --- Purging removes the edge and its count ---
+	ctx := context.Background()
+	_, err := container.DB.Collection("users").UpdateByID(ctx, daveID,
+		bson.M{"$set": bson.M{"deleted_at": time.Now().AddDate(-1, 0, 0)}})
+	require.NoError(t, err)
+	_, err = container.Lifecycle.PurgeExpired(ctx)
+	require.NoError(t, err)
+
+	require.EqualValues(t, 0, followersCount(t, aliceID))
+	n, err := container.DB.Collection("follows").CountDocuments(ctx, bson.M{"follower_id": daveID})
+	require.NoError(t, err)
+	require.Zero(t, n)
+}
+
+// followersCount reads the followers count of userID as anyone sees it.
+func followersCount(t *testing.T, userID uuid.UUID) int64 {
+	var user response.UserResponse
+	w := doRequest(t, http.MethodGet, "/api/v1/users/"+userID.String(), nil, nil)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &user))
+	return user.FollowersCount
+}
+
+// followEvents counts the stored events of eventType for the given edge.
+func followEvents(t *testing.T, eventType string, followerID, followeeID uuid.UUID) int {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	cur, err := container.DB.Collection("outbox").Find(ctx, bson.M{"event_type": eventType})
+	require.NoError(t, err)
+	var messages []model.OutboxMessage
+	require.NoError(t, cur.All(ctx, &messages))
+
+	count := 0
+	for _, msg := range messages {
+		var payload events.FollowPayload
+		if json.Unmarshal(msg.Payload, &payload) == nil && payload.FollowerID == followerID && payload.FolloweeID == followeeID {
+			count++
+		}
+	}
+	return count
+}
@@ -2,6 +2,7 @@ package integration
 
 import (
 	"context"
+	"net/http"
 	"testing"
 	"time"
 
@@ -9,10 +10,14 @@ import (
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/require"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
 
 	userpb "github.com/Sayan80bayev/go-project/pkg/proto/user"
 	"userService/internal/events"
+	"userService/tests/testutil"
 )
 
 func newGRPCClient(t *testing.T) userpb.UserServiceClient {
@@ -37,7 +42,7 @@ func TestGetUser_GRPC(t *testing.T) {
 		UserID:    userID,
 		Firstname: "Sayan",
 		Lastname:  "Seksenbayev",
-		Email:     "sayan123serv@gmail.com",
+		Email:     "grpc@example.com",
 	}
 
 	err := container.Producer.Produce(ctx, events.UserCreated, payload)
@@ -53,5 +58,33 @@ func TestGetUser_GRPC(t *testing.T) {
 	require.NoError(t, err, "GetUser should return user without error")
 	require.Equal(t, "Sayan", res.Firstname)
 	require.Equal(t, "Seksenbayev", res.Lastname)
-	require.Equal(t, "sayan123serv@gmail.com", res.Email)
+	require.Empty(t, res.Email, "email is private by default")
+
+	// --- Step 4: The owner sees their email ---
+	ownerCtx := metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer "+testutil.GenerateMockToken(userID.String()))
+	res, err = client.GetUser(ownerCtx, &userpb.GetUserRequest{UserId: userID.String()})
+	require.NoError(t, err)
+	require.Equal(t, "grpc@example.com", res.Email)
+}
+
+func TestGetUser_GRPC_Deleted(t *testing.T) {
+	ctx := context.Background()
+	userID := createUser(t, events.UserCreatedPayload{
+		UserID:    uuid.New(),
+		Firstname: "Grpc",
+		Lastname:  "Deleted",
+		Email:     "grpc-deleted@example.com",
+	})
+
+	w := doRequest(t, http.MethodDelete, "/api/v1/users/"+userID.String(), nil, map[string]string{
+		"Authorization": "Bearer " + testutil.GenerateMockToken(userID.String()),
+	})
+	require.Equal(t, http.StatusOK, w.Code)
+
+	client := newGRPCClient(t)
+
+	_, err := client.GetUser(ctx, &userpb.GetUserRequest{UserId: userID.String()})
+	st := status.Convert(err)
+	require.Equal(t, codes.NotFound, st.Code())
+	require.NotEmpty(t, st.Details(), "expected USER_DELETED detail")
 }
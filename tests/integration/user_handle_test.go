@@ -0,0 +1,168 @@
+package integration
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"userService/internal/auth"
+	"userService/internal/events"
+	"userService/internal/model"
+	"userService/internal/service"
+	"userService/tests/testutil"
+)
+
+func TestUserHandles(t *testing.T) {
+	userID := createUser(t, events.UserCreatedPayload{
+		UserID: uuid.New(), Firstname: "Handle", Lastname: "Owner", Email: "handle.owner@example.com",
+	})
+	otherID := createUser(t, events.UserCreatedPayload{
+		UserID: uuid.New(), Firstname: "Handle", Lastname: "Other", Email: "handle.other@example.com",
+	})
+	bearer := func(token string) map[string]string {
+		return map[string]string{
+			"Authorization": "Bearer " + token,
+			"Content-Type":  "application/json",
+		}
+	}
+	owner := bearer(testutil.GenerateMockToken(userID.String()))
+	other := bearer(testutil.GenerateMockToken(otherID.String()))
+	admin := bearer(testutil.GenerateMockTokenWithRoles(uuid.NewString(), auth.RoleAdmin))
+
+	// --- Format rules and reserved words ---
+	w := doRequest(t, http.MethodPut, "/api/v1/users/me/handle", strings.NewReader(`{"handle":"no"}`), owner)
+	require.Equal(t, http.StatusUnprocessableEntity, w.Code, w.Body.String())
+	w = doRequest(t, http.MethodPut, "/api/v1/users/me/handle", strings.NewReader(`{"handle":"support"}`), owner)
+	require.Equal(t, http.StatusUnprocessableEntity, w.Code, w.Body.String())
+
+	// --- Handles are unique regardless of case ---
+	w = doRequest(t, http.MethodPut, "/api/v1/users/me/handle", strings.NewReader(`{"handle":"@Grace_Hopper"}`), owner)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	require.Contains(t, w.Body.String(), `"handle":"grace_hopper"`)
+
+	w = doRequest(t, http.MethodPut, "/api/v1/users/me/handle", strings.NewReader(`{"handle":"GRACE_HOPPER"}`), other)
+	require.Equal(t, http.StatusConflict, w.Code, w.Body.String())
+	require.Equal(t, "ALREADY_TAKEN", decodeError(t, w.Body.Bytes()).Code)
+
+	w = doRequest(t, http.MethodGet, "/api/v1/users/@Grace_Hopper", nil, nil)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var resp map[string]any
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	require.Equal(t, userID.String(), resp["id"])
+
+	// --- Owners cannot rename again within the interval ---
+	w = doRequest(t, http.MethodPut, "/api/v1/users/me/handle", strings.NewReader(`{"handle":"amazing_grace"}`), owner)
+	require.Equal(t, http.StatusTooManyRequests, w.Code, w.Body.String())
+	require.Equal(t, "HANDLE_RENAME_TOO_SOON", decodeError(t, w.Body.Bytes()).Code)
+
+	// --- Admins can, and the old handle redirects to the new one ---
+	w = doRequest(t, http.MethodPut, "/api/v1/users/"+userID.String()+"/handle", strings.NewReader(`{"handle":"amazing_grace"}`), admin)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	w = doRequest(t, http.MethodGet, "/api/v1/users/@grace_hopper", nil, nil)
+	require.Equal(t, http.StatusTemporaryRedirect, w.Code)
+	require.Equal(t, "/api/v1/users/@amazing_grace", w.Header().Get("Location"))
+
+	// --- Nobody else can claim the old handle while it redirects ---
+	w = doRequest(t, http.MethodPut, "/api/v1/users/me/handle", strings.NewReader(`{"handle":"grace_hopper"}`), other)
+	require.Equal(t, http.StatusConflict, w.Code, w.Body.String())
+
+	w = doRequest(t, http.MethodGet, "/api/v1/users/@nobody_here", nil, nil)
+	require.Equal(t, http.StatusNotFound, w.Code)
+
+	require.Equal(t, []string{"grace_hopper", "amazing_grace"}, handleChanges(t, userID))
+}
+
+func TestUserHandles_ConcurrentClaims(t *testing.T) {
+	ctx := context.Background()
+	claimants := make([]uuid.UUID, 8)
+	for i := range claimants {
+		claimants[i] = createUser(t, events.UserCreatedPayload{
+			UserID: uuid.New(), Firstname: "Race", Lastname: "Claimant", Email: "race.claimant" + strconv.Itoa(i) + "@example.com",
+		})
+	}
+	changeHandle := func(userID uuid.UUID, handle string) error {
+		ctx := service.WithOrigin(ctx, service.Origin{Source: service.SourceHTTP, Actor: &userID})
+		_, err := container.UserService.ChangeHandle(ctx, userID, handle)
+		return err
+	}
+
+	// --- Of concurrent claims of a free handle exactly one wins ---
+	var wg sync.WaitGroup
+	var won atomic.Int32
+	for _, id := range claimants {
+		wg.Add(1)
+		go func(id uuid.UUID) {
+			defer wg.Done()
+			if changeHandle(id, "race_free") == nil {
+				won.Add(1)
+			}
+		}(id)
+	}
+	wg.Wait()
+	require.EqualValues(t, 1, won.Load())
+
+	// --- A handle given up in a rename cannot be taken while it redirects,
+	// even by claims racing the rename ---
+	holder := claimants[0]
+	require.NoError(t, changeHandle(holder, "race_old"))
+	won.Store(0)
+	for _, id := range claimants[1:] {
+		wg.Add(1)
+		go func(id uuid.UUID) {
+			defer wg.Done()
+			if changeHandle(id, "race_old") == nil {
+				won.Add(1)
+			}
+		}(id)
+	}
+	// Admins are not held to the rename interval
+	var renameErr error
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		ctx := service.WithOrigin(ctx, service.Origin{Source: service.SourceHTTP, Actor: &holder, Admin: true})
+		_, renameErr = container.UserService.ChangeHandle(ctx, holder, "race_new")
+	}()
+	wg.Wait()
+	require.NoError(t, renameErr)
+	require.Zero(t, won.Load())
+
+	var reservation struct {
+		UserID uuid.UUID  `bson:"user_id"`
+		Until  *time.Time `bson:"until"`
+	}
+	require.NoError(t, container.DB.Collection("handle_reservations").FindOne(ctx, bson.M{"_id": "race_old"}).Decode(&reservation))
+	require.Equal(t, holder, reservation.UserID)
+	require.NotNil(t, reservation.Until)
+}
+
+// handleChanges lists the new handles of the UserHandleChanged events stored
+// for userID, oldest first.
+func handleChanges(t *testing.T, userID uuid.UUID) []string {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	cur, err := container.DB.Collection("outbox").Find(ctx, bson.M{"event_type": events.UserHandleChanged})
+	require.NoError(t, err)
+	var messages []model.OutboxMessage
+	require.NoError(t, cur.All(ctx, &messages))
+
+	var handles []string
+	for _, msg := range messages {
+		var payload events.UserHandleChangedPayload
+		if json.Unmarshal(msg.Payload, &payload) == nil && payload.UserID == userID {
+			handles = append(handles, payload.Handle)
+		}
+	}
+	return handles
+}
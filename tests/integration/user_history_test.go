@@ -0,0 +1,79 @@
+package integration
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"userService/internal/events"
+	"userService/internal/service"
+	"userService/internal/transport/response"
+	"userService/tests/testutil"
+)
+
+func TestUserHistory(t *testing.T) {
+	userID := createUser(t, events.UserCreatedPayload{
+		UserID: uuid.New(), Firstname: "History", Lastname: "Tester", Email: "history@example.com",
+	})
+	headers := map[string]string{
+		"Authorization": "Bearer " + testutil.GenerateMockToken(userID.String()),
+		"Content-Type":  "application/merge-patch+json",
+	}
+
+	// --- Two edits over HTTP ---
+	for _, body := range []string{`{"location":"Almaty"}`, `{"location":"Astana","about":"moved"}`} {
+		w := doRequest(t, http.MethodPatch, "/api/v1/users/"+userID.String(), strings.NewReader(body), headers)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	}
+
+	// --- History is newest first and paginated ---
+	w := doRequest(t, http.MethodGet, "/api/v1/users/"+userID.String()+"/history?limit=2", nil, headers)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	var page response.UserRevisionPageResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &page))
+	require.Len(t, page.Items, 2)
+	require.NotEmpty(t, page.NextCursor)
+
+	latest := page.Items[0]
+	require.Equal(t, service.ActionUpdate, latest.Action)
+	require.Equal(t, service.SourceHTTP, latest.Source)
+	require.NotNil(t, latest.Actor)
+	require.Equal(t, userID, *latest.Actor)
+	require.Contains(t, latest.Changes, response.FieldChangeResponse{Field: "location", Old: "Almaty", New: "Astana"})
+
+	w = doRequest(t, http.MethodGet, "/api/v1/users/"+userID.String()+"/history?limit=2&cursor="+page.NextCursor, nil, headers)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var rest response.UserRevisionPageResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &rest))
+	require.Len(t, rest.Items, 1)
+	created := rest.Items[0]
+	require.Equal(t, service.ActionCreate, created.Action)
+	require.Equal(t, service.SourceEvent, created.Source)
+	require.Empty(t, rest.NextCursor)
+
+	// --- The profile as it was at a revision ---
+	w = doRequest(t, http.MethodGet, "/api/v1/users/"+userID.String()+"/history/"+page.Items[1].ID.String(), nil, headers)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var then response.UserResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &then))
+	require.Equal(t, "Almaty", then.Location)
+	require.Empty(t, then.About)
+
+	w = doRequest(t, http.MethodGet, "/api/v1/users/"+userID.String()+"/history/"+created.ID.String(), nil, headers)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &then))
+	require.Empty(t, then.Location)
+	require.Equal(t, "History", then.Firstname)
+
+	w = doRequest(t, http.MethodGet, "/api/v1/users/"+userID.String()+"/history/"+uuid.NewString(), nil, headers)
+	require.Equal(t, http.StatusNotFound, w.Code)
+
+	// --- Only the owner and admins may read it ---
+	stranger := map[string]string{"Authorization": "Bearer " + testutil.GenerateMockToken(uuid.NewString())}
+	w = doRequest(t, http.MethodGet, "/api/v1/users/"+userID.String()+"/history", nil, stranger)
+	require.Equal(t, http.StatusForbidden, w.Code)
+}
@@ -14,6 +14,7 @@ import (
 	"net/http/httptest"
 	"testing"
 	"time"
+	"userService/internal/auth"
 	"userService/internal/events"
 	"userService/tests/testutil"
 )
@@ -55,6 +56,8 @@ func TestUserLifecycle(t *testing.T) {
 	// --- Step 3: Fetch created user ---
 	w := doRequest(t, http.MethodGet, fmt.Sprintf("/api/v1/users/%s", userID.String()), nil, nil)
 	require.Equal(t, http.StatusOK, w.Code)
+	etag := w.Header().Get("ETag")
+	require.NotEmpty(t, etag, "expected ETag on fetch")
 
 	// --- Step 4: Update user (with JWT token) ---
 	token := testutil.GenerateMockToken(userID.String())
@@ -89,9 +92,17 @@ func TestUserLifecycle(t *testing.T) {
 		"Content-Type":  writer.FormDataContentType(),
 	}
 
-	w = doRequest(t, http.MethodPut, "/api/v1/users/"+userID.String(), body, headers)
+	w = doRequest(t, http.MethodPut, "/api/v1/users/"+userID.String(), bytes.NewReader(body.Bytes()), headers)
+	require.Equal(t, http.StatusPreconditionRequired, w.Code, "expected 428 without If-Match")
+
+	headers["If-Match"] = etag
+	w = doRequest(t, http.MethodPut, "/api/v1/users/"+userID.String(), bytes.NewReader(body.Bytes()), headers)
 	require.Equal(t, http.StatusOK, w.Code, "expected 200 on update")
 
+	// The same ETag is stale now that the profile has a new version
+	w = doRequest(t, http.MethodPut, "/api/v1/users/"+userID.String(), bytes.NewReader(body.Bytes()), headers)
+	require.Equal(t, http.StatusPreconditionFailed, w.Code, "expected 412 for stale If-Match")
+
 	// --- Step 5: Delete user (with JWT token) ---
 	headers = map[string]string{
 		"Authorization": "Bearer " + token,
@@ -99,14 +110,25 @@ func TestUserLifecycle(t *testing.T) {
 	w = doRequest(t, http.MethodDelete, "/api/v1/users/"+userID.String(), nil, headers)
 	require.Equal(t, http.StatusOK, w.Code, "expected 200 on delete")
 
-	// --- Step 6: Confirm fetch still works (or check a status) ---
+	// --- Step 6: Deleted users are gone for regular readers ---
 	w = doRequest(t, http.MethodGet, fmt.Sprintf("/api/v1/users/%s", userID.String()), nil, nil)
-	require.Equal(t, http.StatusOK, w.Code, "expected 200 after fetch")
+	require.Equal(t, http.StatusGone, w.Code, "expected 410 after delete")
+
+	w = doRequest(t, http.MethodGet, fmt.Sprintf("/api/v1/users/%s?include_deleted=true", userID.String()), nil, headers)
+	require.Equal(t, http.StatusForbidden, w.Code, "include_deleted is for admins only")
+
+	// --- Step 7: Admins can still see the deleted profile ---
+	admin := map[string]string{
+		"Authorization": "Bearer " + testutil.GenerateMockTokenWithRoles(uuid.NewString(), auth.RoleAdmin),
+	}
+	w = doRequest(t, http.MethodGet, fmt.Sprintf("/api/v1/users/%s?include_deleted=true", userID.String()), nil, admin)
+	require.Equal(t, http.StatusOK, w.Code, "expected 200 for admin with include_deleted")
 
 	// Parse response JSON into a map
 	var resp map[string]any
 	err = json.Unmarshal(w.Body.Bytes(), &resp)
 	require.NoError(t, err, "failed to unmarshal response")
+	require.Contains(t, resp, "deleted_at")
 }
 
 func TestUserLifecycle_NeedsCompletion(t *testing.T) {
@@ -116,7 +138,7 @@ func TestUserLifecycle_NeedsCompletion(t *testing.T) {
 	userID := uuid.New()
 	payload := events.UserCreatedPayload{
 		UserID: userID,
-		Email:  "sayan123serv@gmail.com",
+		Email:  "needs-completion@example.com",
 	}
 
 	// Produce event
@@ -131,7 +153,10 @@ func TestUserLifecycle_NeedsCompletion(t *testing.T) {
 func waitForUserNeedsCompletion(t *testing.T, userID uuid.UUID, timeout time.Duration) {
 	deadline := time.Now().Add(timeout)
 	for time.Now().Before(deadline) {
-		w := doRequest(t, http.MethodGet, "/api/v1/users/"+userID.String(), nil, nil)
+		// Completion state is shown to the owner only
+		w := doRequest(t, http.MethodGet, "/api/v1/users/"+userID.String(), nil, map[string]string{
+			"Authorization": "Bearer " + testutil.GenerateMockToken(userID.String()),
+		})
 		if w.Code == http.StatusOK {
 			var resp map[string]any
 			if err := json.Unmarshal(w.Body.Bytes(), &resp); err == nil {
@@ -144,3 +169,92 @@ func waitForUserNeedsCompletion(t *testing.T, userID uuid.UUID, timeout time.Dur
 	}
 	t.Fatalf("user %s did not reach needs_completion=true within %s", userID, timeout)
 }
+
+func TestUserPatch(t *testing.T) {
+	userID := createUser(t, events.UserCreatedPayload{
+		UserID:    uuid.New(),
+		Firstname: "Patch",
+		Lastname:  "Tester",
+		Email:     "patch@example.com",
+	})
+	token := testutil.GenerateMockToken(userID.String())
+
+	// --- Set two fields ---
+	headers := map[string]string{
+		"Authorization": "Bearer " + token,
+		"Content-Type":  "application/merge-patch+json",
+	}
+	w := doRequest(t, http.MethodPatch, "/api/v1/users/"+userID.String(),
+		bytes.NewBufferString(`{"about":"keep me","location":"Almaty"}`), headers)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	// --- Change only location; about must survive ---
+	headers["If-Match"] = w.Header().Get("ETag")
+	w = doRequest(t, http.MethodPatch, "/api/v1/users/"+userID.String(),
+		bytes.NewBufferString(`{"location":null}`), headers)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	var resp map[string]any
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	require.Equal(t, "keep me", resp["about"])
+	require.NotContains(t, resp, "location")
+
+	// --- Invalid values are rejected field by field ---
+	delete(headers, "If-Match")
+	w = doRequest(t, http.MethodPatch, "/api/v1/users/"+userID.String(),
+		bytes.NewBufferString(`{"gender":"unknown","email":"x@y.z"}`), headers)
+	require.Equal(t, http.StatusUnprocessableEntity, w.Code)
+}
+
+// createUser produces a UserCreated event and waits until the profile can be fetched.
+func createUser(t *testing.T, payload events.UserCreatedPayload) uuid.UUID {
+	err := container.Producer.Produce(context.Background(), events.UserCreated, payload)
+	require.NoError(t, err)
+
+	deadline := time.Now().Add(10 * time.Second)
+	for time.Now().Before(deadline) {
+		w := doRequest(t, http.MethodGet, "/api/v1/users/"+payload.UserID.String(), nil, nil)
+		if w.Code == http.StatusOK {
+			return payload.UserID
+		}
+		time.Sleep(200 * time.Millisecond)
+	}
+	t.Fatalf("user %s was not created within timeout", payload.UserID)
+	return uuid.Nil
+}
+
+func TestUserRestore(t *testing.T) {
+	userID := createUser(t, events.UserCreatedPayload{
+		UserID:    uuid.New(),
+		Firstname: "Restore",
+		Lastname:  "Tester",
+		Email:     "restore@example.com",
+	})
+	headers := map[string]string{
+		"Authorization": "Bearer " + testutil.GenerateMockToken(userID.String()),
+	}
+
+	// --- Restoring a live profile is a conflict ---
+	w := doRequest(t, http.MethodPost, "/api/v1/users/"+userID.String()+"/restore", nil, headers)
+	require.Equal(t, http.StatusConflict, w.Code, w.Body.String())
+
+	w = doRequest(t, http.MethodDelete, "/api/v1/users/"+userID.String(), nil, headers)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	// --- Someone else cannot restore it ---
+	other := map[string]string{
+		"Authorization": "Bearer " + testutil.GenerateMockToken(uuid.NewString()),
+	}
+	w = doRequest(t, http.MethodPost, "/api/v1/users/"+userID.String()+"/restore", nil, other)
+	require.Equal(t, http.StatusForbidden, w.Code)
+
+	// --- The owner can, within the grace period ---
+	w = doRequest(t, http.MethodPost, "/api/v1/users/"+userID.String()+"/restore", nil, headers)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	w = doRequest(t, http.MethodGet, "/api/v1/users/"+userID.String(), nil, nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	var resp map[string]any
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	require.NotContains(t, resp, "deleted_at")
+}
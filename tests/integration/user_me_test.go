@@ -0,0 +1,51 @@
+package integration
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"userService/internal/events"
+	"userService/tests/testutil"
+)
+
+func TestMeEndpoints(t *testing.T) {
+	// --- Anonymous callers have no "me" ---
+	w := doRequest(t, http.MethodGet, "/api/v1/users/me", nil, nil)
+	require.Equal(t, http.StatusUnauthorized, w.Code)
+
+	userID := createUser(t, events.UserCreatedPayload{
+		UserID: uuid.New(), Firstname: "Me", Lastname: "Tester", Email: "me@example.com",
+	})
+	headers := map[string]string{"Authorization": "Bearer " + testutil.GenerateMockToken(userID.String())}
+
+	// --- GET returns the private view of the token subject ---
+	w = doRequest(t, http.MethodGet, "/api/v1/users/me", nil, headers)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	require.NotEmpty(t, w.Header().Get("ETag"))
+	var resp map[string]any
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	require.Equal(t, userID.String(), resp["id"])
+	require.Equal(t, "me@example.com", resp["email"])
+	require.Contains(t, resp, "completeness")
+
+	// --- PATCH edits the caller's profile ---
+	headers["Content-Type"] = "application/merge-patch+json"
+	w = doRequest(t, http.MethodPatch, "/api/v1/users/me", strings.NewReader(`{"location":"Almaty"}`), headers)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	resp = nil
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	require.Equal(t, userID.String(), resp["id"])
+	require.Equal(t, "Almaty", resp["location"])
+
+	// --- DELETE removes it ---
+	delete(headers, "Content-Type")
+	w = doRequest(t, http.MethodDelete, "/api/v1/users/me", nil, headers)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	w = doRequest(t, http.MethodGet, "/api/v1/users/me", nil, headers)
+	require.Equal(t, http.StatusGone, w.Code)
+}
@@ -0,0 +1,108 @@
+package integration
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"userService/internal/auth"
+	"userService/internal/events"
+	"userService/internal/model"
+	"userService/internal/service"
+	"userService/tests/testutil"
+)
+
+func TestUserModeration(t *testing.T) {
+	userID := createUser(t, events.UserCreatedPayload{
+		UserID: uuid.New(), Firstname: "Moderated", Lastname: "User", Email: "moderated@example.com",
+	})
+	path := "/api/v1/users/" + userID.String()
+	bearer := func(token string) map[string]string {
+		return map[string]string{
+			"Authorization": "Bearer " + token,
+			"Content-Type":  "application/json",
+		}
+	}
+	owner := bearer(testutil.GenerateMockToken(userID.String()))
+	admin := bearer(testutil.GenerateMockTokenWithRoles(uuid.NewString(), auth.RoleAdmin))
+
+	// --- Only admins moderate ---
+	w := doRequest(t, http.MethodPost, path+"/ban", strings.NewReader(`{"reason":"spam"}`), owner)
+	require.Equal(t, http.StatusForbidden, w.Code)
+
+	// --- Suspensions need an expiry ---
+	w = doRequest(t, http.MethodPost, path+"/suspend", strings.NewReader(`{"reason":"spam"}`), admin)
+	require.Equal(t, http.StatusUnprocessableEntity, w.Code, w.Body.String())
+
+	w = doRequest(t, http.MethodPost, path+"/ban", strings.NewReader(`{"reason":"spam"}`), admin)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	// --- Banned profiles are hidden from the public but not from admins and the owner ---
+	w = doRequest(t, http.MethodGet, path, nil, nil)
+	require.Equal(t, http.StatusNotFound, w.Code)
+
+	w = doRequest(t, http.MethodGet, "/api/v1/users?limit=100", nil, nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	require.NotContains(t, w.Body.String(), userID.String())
+
+	w = doRequest(t, http.MethodGet, "/api/v1/users?moderated=true", nil, nil)
+	require.Equal(t, http.StatusUnauthorized, w.Code)
+
+	w = doRequest(t, http.MethodGet, path, nil, admin)
+	require.Equal(t, http.StatusOK, w.Code)
+	var resp map[string]any
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	require.Equal(t, model.StatusBanned, resp["account_status"])
+
+	w = doRequest(t, http.MethodGet, "/api/v1/users/me", nil, owner)
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Contains(t, w.Body.String(), `"account_status":"banned"`)
+
+	// --- The owner cannot edit a banned profile ---
+	w = doRequest(t, http.MethodPatch, "/api/v1/users/me", strings.NewReader(`{"about":"let me in"}`), map[string]string{
+		"Authorization": owner["Authorization"],
+		"Content-Type":  "application/merge-patch+json",
+	})
+	require.Equal(t, http.StatusForbidden, w.Code)
+	require.Equal(t, "ACCOUNT_RESTRICTED", decodeError(t, w.Body.Bytes()).Code)
+
+	// --- Unbanning makes the profile public again ---
+	w = doRequest(t, http.MethodPost, path+"/unban", strings.NewReader(`{"reason":"appeal"}`), admin)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	w = doRequest(t, http.MethodPost, path+"/unban", nil, admin)
+	require.Equal(t, http.StatusConflict, w.Code)
+
+	w = doRequest(t, http.MethodGet, path, nil, nil)
+	require.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestUserModeration_SuspensionExpires(t *testing.T) {
+	userID := createUser(t, events.UserCreatedPayload{
+		UserID: uuid.New(), Firstname: "Suspended", Lastname: "User", Email: "suspended@example.com",
+	})
+	moderatorID := uuid.New()
+	ctx := service.WithOrigin(context.Background(), service.Origin{Source: service.SourceHTTP, Actor: &moderatorID, Admin: true})
+
+	expiresAt := time.Now().Add(time.Second)
+	require.NoError(t, container.Moderation.SuspendUser(ctx, userID, service.ModerationAction{
+		Reason: "cool down", ExpiresAt: &expiresAt,
+	}))
+
+	_, err := container.UserService.GetUserById(context.Background(), userID)
+	require.ErrorIs(t, err, service.ErrUserNotFound)
+
+	time.Sleep(time.Until(expiresAt) + 10*time.Millisecond)
+	lifted, err := container.Moderation.LiftExpired(context.Background())
+	require.NoError(t, err)
+	require.GreaterOrEqual(t, lifted, 1)
+
+	user, err := container.UserService.GetUserById(context.Background(), userID)
+	require.NoError(t, err)
+	require.Equal(t, model.StatusActive, user.AccountStatus)
+}
@@ -0,0 +1,62 @@
+package integration
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"userService/internal/auth"
+	"userService/internal/events"
+	"userService/tests/testutil"
+)
+
+func TestUserPrivacy(t *testing.T) {
+	userID := createUser(t, events.UserCreatedPayload{
+		UserID: uuid.New(), Firstname: "Private", Lastname: "Person", Email: "private@example.com",
+	})
+	path := "/api/v1/users/" + userID.String()
+	bearer := func(token string) map[string]string {
+		return map[string]string{
+			"Authorization": "Bearer " + token,
+			"Content-Type":  "application/json",
+		}
+	}
+	owner := bearer(testutil.GenerateMockToken(userID.String()))
+	other := bearer(testutil.GenerateMockToken(uuid.NewString()))
+	admin := bearer(testutil.GenerateMockTokenWithRoles(uuid.NewString(), auth.RoleAdmin))
+	get := func(headers map[string]string) map[string]any {
+		w := doRequest(t, http.MethodGet, path, nil, headers)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		var resp map[string]any
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		return resp
+	}
+
+	// --- Emails are private by default ---
+	require.NotContains(t, get(nil), "email")
+	require.NotContains(t, get(other), "email")
+	require.NotContains(t, get(other), "privacy")
+	require.Equal(t, "private@example.com", get(admin)["email"])
+
+	// --- Only the owner and admins change the settings ---
+	w := doRequest(t, http.MethodPatch, path+"/privacy", strings.NewReader(`{"email":"public"}`), other)
+	require.Equal(t, http.StatusForbidden, w.Code)
+
+	w = doRequest(t, http.MethodPatch, "/api/v1/users/me/privacy", strings.NewReader(`{"email":"friends"}`), owner)
+	require.Equal(t, http.StatusUnprocessableEntity, w.Code, w.Body.String())
+
+	w = doRequest(t, http.MethodPatch, "/api/v1/users/me/privacy", strings.NewReader(`{"email":"authenticated"}`), owner)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	require.Contains(t, w.Body.String(), `"email":"authenticated"`)
+
+	// --- The cached view is narrowed per caller ---
+	require.NotContains(t, get(nil), "email")
+	require.Equal(t, "private@example.com", get(other)["email"])
+
+	w = doRequest(t, http.MethodPatch, path+"/privacy", strings.NewReader(`{"email":null}`), admin)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	require.NotContains(t, get(other), "email")
+}
@@ -0,0 +1,76 @@
+package integration
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"userService/internal/auth"
+	"userService/internal/events"
+	"userService/tests/testutil"
+)
+
+func TestUserRoles(t *testing.T) {
+	userID := createUser(t, events.UserCreatedPayload{
+		UserID: uuid.New(), Firstname: "Roles", Lastname: "User", Email: "roles@example.com",
+	})
+	path := "/api/v1/users/" + userID.String() + "/roles"
+	bearer := func(token string) map[string]string {
+		return map[string]string{
+			"Authorization": "Bearer " + token,
+			"Content-Type":  "application/json",
+		}
+	}
+	owner := bearer(testutil.GenerateMockToken(userID.String()))
+	admin := bearer(testutil.GenerateMockTokenWithRoles(uuid.NewString(), auth.RoleAdmin))
+	rolesOf := func(body []byte) []string {
+		var resp struct {
+			Roles []string `json:"roles"`
+		}
+		require.NoError(t, json.Unmarshal(body, &resp))
+		return resp.Roles
+	}
+
+	// --- Users cannot grant themselves roles ---
+	w := doRequest(t, http.MethodPost, path, strings.NewReader(`{"roles":["moderator"]}`), owner)
+	require.Equal(t, http.StatusForbidden, w.Code)
+
+	w = doRequest(t, http.MethodPost, path, strings.NewReader(`{"roles":["superuser"]}`), admin)
+	require.Equal(t, http.StatusUnprocessableEntity, w.Code)
+
+	// --- Granted roles are stored and mirrored to Keycloak ---
+	w = doRequest(t, http.MethodPost, path, strings.NewReader(`{"roles":["moderator","user-admin"]}`), admin)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	require.Equal(t, []string{"moderator", "user-admin"}, rolesOf(w.Body.Bytes()))
+	require.Equal(t, []string{"moderator", "user-admin"}, keycloakAdmin.Roles(userID.String()))
+
+	// Only the owner and admins learn who holds which role
+	w = doRequest(t, http.MethodGet, "/api/v1/users/"+userID.String(), nil, nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Empty(t, rolesOf(w.Body.Bytes()))
+	w = doRequest(t, http.MethodGet, "/api/v1/users/"+userID.String(), nil, owner)
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Equal(t, []string{"moderator", "user-admin"}, rolesOf(w.Body.Bytes()))
+
+	// --- Revoking is idempotent ---
+	w = doRequest(t, http.MethodDelete, path+"/moderator", nil, admin)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	require.Equal(t, []string{"user-admin"}, rolesOf(w.Body.Bytes()))
+
+	w = doRequest(t, http.MethodDelete, path+"/moderator", nil, admin)
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Equal(t, []string{"user-admin"}, keycloakAdmin.Roles(userID.String()))
+
+	// --- Nothing is stored when Keycloak is down ---
+	keycloakAdmin.SetFail(true)
+	defer keycloakAdmin.SetFail(false)
+	w = doRequest(t, http.MethodDelete, path+"/user-admin", nil, admin)
+	require.Equal(t, http.StatusBadGateway, w.Code)
+	require.Equal(t, "ROLE_SYNC_FAILED", decodeError(t, w.Body.Bytes()).Code)
+
+	w = doRequest(t, http.MethodGet, "/api/v1/users/"+userID.String(), nil, admin)
+	require.Equal(t, []string{"user-admin"}, rolesOf(w.Body.Bytes()))
+}
@@ -0,0 +1,51 @@
+package integration
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"userService/internal/events"
+	"userService/internal/transport/response"
+)
+
+func TestSearchUsers(t *testing.T) {
+	ctx := context.Background()
+
+	userID := uuid.New()
+	payload := events.UserCreatedPayload{
+		UserID:    userID,
+		Firstname: "Zhanibek",
+		Lastname:  "Searchable",
+		Email:     "search@example.com",
+	}
+
+	err := container.Producer.Produce(ctx, events.UserCreated, payload)
+	require.NoError(t, err)
+
+	// Poll until the consumer has stored the user and the text index picks it up
+	deadline := time.Now().Add(10 * time.Second)
+	for time.Now().Before(deadline) {
+		w := doRequest(t, http.MethodGet, "/api/v1/users/search?q=zhanibek", nil, nil)
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var page response.UserPageResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &page))
+		for _, u := range page.Items {
+			if u.ID == userID {
+				return // ✅ found
+			}
+		}
+		time.Sleep(200 * time.Millisecond)
+	}
+	t.Fatalf("user %s was not found by search within timeout", userID)
+}
+
+func TestSearchUsers_EmptyQuery(t *testing.T) {
+	w := doRequest(t, http.MethodGet, "/api/v1/users/search?q=", nil, nil)
+	require.Equal(t, http.StatusBadRequest, w.Code)
+}
@@ -69,15 +69,31 @@ func base64Url(b []byte) string {
 	return base64.RawURLEncoding.EncodeToString(b)
 }
 
+// Issuer and audience of mock tokens; the test container expects both
+const (
+	MockIssuer   = "http://go-project-keycloak:8080/realms/go-project"
+	MockAudience = "user-service"
+)
+
 // GenerateMockToken creates a JWT signed with the mock private key
 func GenerateMockToken(userId string) string {
+	return GenerateMockTokenWithRoles(userId)
+}
+
+// GenerateMockTokenWithRoles creates a JWT whose realm_access also grants roles
+func GenerateMockTokenWithRoles(userId string, roles ...string) string {
+	return GenerateMockTokenWithClaims(userId, nil, roles...)
+}
+
+// GenerateMockTokenWithClaims creates a JWT with overrides replacing the default claims
+func GenerateMockTokenWithClaims(userId string, overrides jwt.MapClaims, roles ...string) string {
 	now := time.Now()
 	claims := jwt.MapClaims{
 		"exp": now.Add(time.Hour).Unix(),
 		"iat": now.Unix(),
 		"jti": "onrtro:cba1c0d6-c951-a2c4-53be-2c964da5125b",
-		"iss": "http://go-project-keycloak:8080/realms/go-project",
-		"aud": []string{"realm-management", "account"},
+		"iss": MockIssuer,
+		"aud": []string{"realm-management", "account", MockAudience},
 		"sub": userId,
 		"typ": "Bearer",
 		"azp": "auth_service",
@@ -88,11 +104,11 @@ func GenerateMockToken(userId string) string {
 			"/*",
 		},
 		"realm_access": map[string]interface{}{
-			"roles": []string{
+			"roles": append([]string{
 				"default-roles-go-project",
 				"offline_access",
 				"uma_authorization",
-			},
+			}, roles...),
 		},
 		"resource_access": map[string]interface{}{
 			"realm-management": map[string]interface{}{
@@ -111,6 +127,10 @@ func GenerateMockToken(userId string) string {
 		"email":              "sayan123serv@gmail.com",
 	}
 
+	for k, v := range overrides {
+		claims[k] = v
+	}
+
 	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
 	token.Header["kid"] = "test-key-id"
 	ss, err := token.SignedString(privateKey)
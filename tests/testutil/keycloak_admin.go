@@ -0,0 +1,102 @@
+package testutil
+
+import (
+	"encoding/json"
+	"net/http"
+	"slices"
+	"sync"
+)
+
+// KeycloakAdmin stands in for the Keycloak admin REST API: it issues service
+// account tokens and records realm role mappings per user.
+type KeycloakAdmin struct {
+	mu       sync.Mutex
+	mappings map[string][]string
+	// Fail makes role mapping requests answer 503 while set
+	Fail bool
+}
+
+type roleRepresentation struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+const mockAdminToken = "mock-admin-token"
+
+// NewKeycloakAdmin registers the stand-in on mux for realm. Any role name
+// exists in the realm.
+func NewKeycloakAdmin(mux *http.ServeMux, realm string) *KeycloakAdmin {
+	k := &KeycloakAdmin{mappings: map[string][]string{}}
+
+	mux.HandleFunc("POST /realms/"+realm+"/protocol/openid-connect/token", func(w http.ResponseWriter, r *http.Request) {
+		if r.FormValue("grant_type") != "client_credentials" {
+			http.Error(w, "unsupported grant", http.StatusBadRequest)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{"access_token": mockAdminToken, "expires_in": 300})
+	})
+	mux.HandleFunc("GET /admin/realms/"+realm+"/roles/{name}", func(w http.ResponseWriter, r *http.Request) {
+		if !authorized(r) {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		name := r.PathValue("name")
+		_ = json.NewEncoder(w).Encode(roleRepresentation{ID: "role-" + name, Name: name})
+	})
+	mux.HandleFunc("/admin/realms/"+realm+"/users/{id}/role-mappings/realm", k.serveMappings)
+	return k
+}
+
+// Roles returns the realm roles mapped to userID, sorted.
+func (k *KeycloakAdmin) Roles(userID string) []string {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	roles := slices.Clone(k.mappings[userID])
+	slices.Sort(roles)
+	return roles
+}
+
+// SetFail toggles failures of role mapping requests.
+func (k *KeycloakAdmin) SetFail(fail bool) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	k.Fail = fail
+}
+
+func (k *KeycloakAdmin) serveMappings(w http.ResponseWriter, r *http.Request) {
+	if !authorized(r) {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	var roles []roleRepresentation
+	if err := json.NewDecoder(r.Body).Decode(&roles); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	if k.Fail {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		return
+	}
+	userID := r.PathValue("id")
+	for _, role := range roles {
+		switch r.Method {
+		case http.MethodPost:
+			if !slices.Contains(k.mappings[userID], role.Name) {
+				k.mappings[userID] = append(k.mappings[userID], role.Name)
+			}
+		case http.MethodDelete:
+			k.mappings[userID] = slices.DeleteFunc(k.mappings[userID], func(name string) bool { return name == role.Name })
+		default:
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func authorized(r *http.Request) bool {
+	return r.Header.Get("Authorization") == "Bearer "+mockAdminToken
+}